@@ -21,6 +21,20 @@ type PluginConfig struct {
 	InterfaceBlock int      `json:"interfaceBlock"`
 	ExcludeCIDRs   []string `json:"excludeCIDRs,omitempty"`
 	VlanMode       string   `json:"vlanMode,omitempty"`
+	RailOptimized  bool     `json:"railOptimized,omitempty"`
+	// +optional
+	Rule *PolicyRule `json:"rule,omitempty"`
+}
+
+// PolicyRule defines priority and selectors of the policy routing rules to the L3 route table
+// in addition to the source subnet
+// Priority is the rule priority, assigned by the kernel if not set
+// FwMark selects packets with the firewall mark in the form of mark or mark/mask (e.g., 0x10/0xff)
+// Iif selects packets from the input interface (e.g., lo for packets from the host)
+type PolicyRule struct {
+	Priority int    `json:"priority,omitempty"`
+	FwMark   string `json:"fwmark,omitempty"`
+	Iif      string `json:"iif,omitempty"`
 }
 
 type HostInterfaceInfo struct {
@@ -47,10 +61,47 @@ type CIDRSpec struct {
 	CIDRs  []CIDREntry  `json:"cidr"`
 }
 
+// HostUtilization shows how many addresses of the host pod CIDR are allocated
+// Capacity is the number of assignable addresses in pod CIDR
+// Allocated is the number of allocations in the corresponding IPPool
+type HostUtilization struct {
+	HostName  string `json:"hostName"`
+	PodCIDR   string `json:"podCIDR"`
+	IPPool    string `json:"ippool,omitempty"`
+	Capacity  int    `json:"capacity"`
+	Allocated int    `json:"allocated"`
+}
+
+// CIDREntryStatus shows host index utilization of the VLAN CIDR
+// HostCapacity is the number of host indexes (host blocks) in VLAN CIDR
+// AssignedHosts is the number of host blocks assigned to hosts
+// AvailableHostIndexes is the number of host indexes neither assigned nor excluded
+// Rail is the rail index of the master network in rail-optimized network
+type CIDREntryStatus struct {
+	NetAddress string `json:"netAddress"`
+	// +optional
+	Rail                 *int              `json:"rail,omitempty"`
+	VlanCIDR             string            `json:"vlanCIDR"`
+	HostCapacity         int               `json:"hostCapacity"`
+	AssignedHosts        int               `json:"assignedHosts"`
+	AvailableHostIndexes int               `json:"availableHostIndexes"`
+	Hosts                []HostUtilization `json:"hosts,omitempty"`
+}
+
+// UnassignedHost is a host interface that cannot get pod CIDR
+type UnassignedHost struct {
+	HostName      string `json:"hostName"`
+	InterfaceName string `json:"interfaceName"`
+	NetAddress    string `json:"netAddress"`
+	Reason        string `json:"reason"`
+}
+
 // CIDRStatus defines the observed state of CIDR
 type CIDRStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+	Entries         []CIDREntryStatus `json:"entries,omitempty"`
+	UnassignedHosts []UnassignedHost  `json:"unassignedHosts,omitempty"`
 }
 
 //+kubebuilder:object:root=true
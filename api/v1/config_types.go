@@ -49,6 +49,9 @@ type ConfigSpec struct {
 	LongReconcileMinutes   int        `json:"longReconcileMinutes,omitempty"`
 	ContextTimeoutMinutes  int        `json:"contextTimeoutMinutes,omitempty"`
 	LogLevel               int        `json:"logLevel,omitempty"`
+	// IPPoolNearlyExhaustedPercent is the allocated percentage of usable addresses
+	// from which IPPool is marked NearlyExhausted, default: 90
+	IPPoolNearlyExhaustedPercent int `json:"ippoolNearlyExhaustedPercent,omitempty"`
 }
 
 // ConfigStatus defines the observed state of Config
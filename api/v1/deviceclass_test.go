@@ -0,0 +1,60 @@
+package v1_test
+
+import (
+	. "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeviceClass", func() {
+	numaNode := 1
+	sriov := true
+	info := InterfaceInfoType{
+		InterfaceName: "ens1f0np0",
+		Vendor:        "15b3",
+		Product:       "101d",
+		PciAddress:    "0000:1a:00.0",
+		Driver:        "mlx5_core",
+		Speed:         200000,
+		NumaNode:      &numaNode,
+		SRIOV:         true,
+	}
+
+	DescribeTable("match interface", func(spec DeviceClassSpec, expected bool) {
+		matched, err := spec.Match(info)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matched).To(Equal(expected))
+	},
+		Entry("empty spec", DeviceClassSpec{}, true),
+		Entry("device ID", DeviceClassSpec{DeviceIDs: []DeviceID{{Vendor: "15b3", Products: []string{"1017", "101d"}}}}, true),
+		Entry("other product", DeviceClassSpec{DeviceIDs: []DeviceID{{Vendor: "15b3", Products: []string{"1017"}}}}, false),
+		Entry("driver", DeviceClassSpec{Drivers: []string{"mlx5_core"}}, true),
+		Entry("other driver", DeviceClassSpec{Drivers: []string{"ice"}}, false),
+		Entry("min speed", DeviceClassSpec{MinSpeed: 100000}, true),
+		Entry("slower than min speed", DeviceClassSpec{MinSpeed: 400000}, false),
+		Entry("NUMA node", DeviceClassSpec{NumaNodes: []int{0, 1}}, true),
+		Entry("other NUMA node", DeviceClassSpec{NumaNodes: []int{0}}, false),
+		Entry("PCI address glob", DeviceClassSpec{PciAddress: "0000:1a:*"}, true),
+		Entry("other PCI address", DeviceClassSpec{PciAddress: "0000:3b:*"}, false),
+		Entry("interface name regex", DeviceClassSpec{InterfaceName: "^ens[0-9]+f0"}, true),
+		Entry("other interface name", DeviceClassSpec{InterfaceName: "^eth"}, false),
+		Entry("SR-IOV", DeviceClassSpec{SRIOV: &sriov}, true),
+		Entry("all fields", DeviceClassSpec{Drivers: []string{"mlx5_core"}, MinSpeed: 100000, PciAddress: "0000:1a:00.?", SRIOV: &sriov}, true),
+		Entry("one field not match", DeviceClassSpec{Drivers: []string{"mlx5_core"}, MinSpeed: 400000}, false),
+	)
+
+	It("does not match unknown NUMA node", func() {
+		unknownNuma := *info.DeepCopy()
+		unknownNuma.NumaNode = nil
+		matched, err := DeviceClassSpec{NumaNodes: []int{0}}.Match(unknownNuma)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(matched).To(BeFalse())
+	})
+
+	It("reports invalid pattern", func() {
+		Expect(DeviceClassSpec{PciAddress: "0000:[1a"}.Validate()).NotTo(Succeed())
+		Expect(DeviceClassSpec{InterfaceName: "ens(1"}.Validate()).NotTo(Succeed())
+		_, err := DeviceClassSpec{InterfaceName: "ens(1"}.Match(info)
+		Expect(err).To(HaveOccurred())
+	})
+})
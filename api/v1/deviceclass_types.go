@@ -6,6 +6,11 @@
 package v1
 
 import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -18,17 +23,102 @@ type DeviceID struct {
 }
 
 // DeviceClassSpec defines the desired state of DeviceClass
+// an interface is in the class if it matches all specified fields
+// DeviceIDs is list of vendor and product IDs, match any
+// Drivers is list of kernel driver names, match any
+// MinSpeed is minimum link speed in Mbps
+// NumaNodes is list of NUMA nodes of the device, match any
+// PciAddress is glob pattern of PCI address such as 0000:1a:*
+// InterfaceName is regular expression of interface name
+// SRIOV is expected SR-IOV capability of the device
 type DeviceClassSpec struct {
-	DeviceIDs []DeviceID `json:"ids"`
+	DeviceIDs []DeviceID `json:"ids,omitempty"`
+	Drivers   []string   `json:"drivers,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	MinSpeed      int    `json:"minSpeed,omitempty"`
+	NumaNodes     []int  `json:"numaNodes,omitempty"`
+	PciAddress    string `json:"pciAddress,omitempty"`
+	InterfaceName string `json:"interfaceName,omitempty"`
+	SRIOV         *bool  `json:"sriov,omitempty"`
 }
 
 // DeviceClassStatus defines the observed state of DeviceClass
+// Nodes lists interfaces on each node which currently match the class
+// Message reports invalid spec
 type DeviceClassStatus struct {
+	NumOfNodes      int                     `json:"numOfNodes"`
+	NumOfInterfaces int                     `json:"numOfInterfaces"`
+	Nodes           []DeviceClassNodeStatus `json:"nodes,omitempty"`
+	Message         string                  `json:"message,omitempty"`
+}
+
+type DeviceClassNodeStatus struct {
+	HostName   string   `json:"hostName"`
+	Interfaces []string `json:"interfaces"`
+}
+
+// Validate checks PCI address glob pattern and interface name regular expression
+func (spec DeviceClassSpec) Validate() error {
+	if spec.PciAddress != "" {
+		if _, err := path.Match(spec.PciAddress, ""); err != nil {
+			return fmt.Errorf("invalid pciAddress pattern %s: %v", spec.PciAddress, err)
+		}
+	}
+	if spec.InterfaceName != "" {
+		if _, err := regexp.Compile(spec.InterfaceName); err != nil {
+			return fmt.Errorf("invalid interfaceName pattern %s: %v", spec.InterfaceName, err)
+		}
+	}
+	return nil
+}
+
+// Match returns true if the interface satisfies all specified fields of the class
+func (spec DeviceClassSpec) Match(info InterfaceInfoType) (bool, error) {
+	if err := spec.Validate(); err != nil {
+		return false, err
+	}
+	if len(spec.DeviceIDs) > 0 {
+		found := false
+		for _, deviceID := range spec.DeviceIDs {
+			if deviceID.Vendor == info.Vendor && slices.Contains(deviceID.Products, info.Product) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	if len(spec.Drivers) > 0 && !slices.Contains(spec.Drivers, info.Driver) {
+		return false, nil
+	}
+	if spec.MinSpeed > 0 && info.Speed < spec.MinSpeed {
+		return false, nil
+	}
+	if len(spec.NumaNodes) > 0 && (info.NumaNode == nil || !slices.Contains(spec.NumaNodes, *info.NumaNode)) {
+		return false, nil
+	}
+	if spec.PciAddress != "" {
+		if matched, _ := path.Match(spec.PciAddress, info.PciAddress); !matched {
+			return false, nil
+		}
+	}
+	if spec.InterfaceName != "" {
+		if matched, _ := regexp.MatchString(spec.InterfaceName, info.InterfaceName); !matched {
+			return false, nil
+		}
+	}
+	if spec.SRIOV != nil && *spec.SRIOV != info.SRIOV {
+		return false, nil
+	}
+	return true, nil
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster
+//+kubebuilder:printcolumn:name="Nodes",type=integer,JSONPath=`.status.numOfNodes`
+//+kubebuilder:printcolumn:name="Interfaces",type=integer,JSONPath=`.status.numOfInterfaces`
 
 // DeviceClass is the Schema for the deviceclasses API
 type DeviceClass struct {
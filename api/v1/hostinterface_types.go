@@ -6,6 +6,8 @@
 package v1
 
 import (
+	"slices"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -13,13 +15,30 @@ type InterfaceInfoType struct {
 	InterfaceName string `json:"interfaceName"`
 	NetAddress    string `json:"netAddress,omitempty"`
 	HostIP        string `json:"hostIP,omitempty"`
-	Vendor        string `json:"vendor,omitempty"`
-	Product       string `json:"product,omitempty"`
-	PciAddress    string `json:"pciAddress,omitempty"`
+	// HostIPs lists host addresses of all address families (e.g., IPv4 and IPv6 in dual-stack host)
+	HostIPs    []string `json:"hostIPs,omitempty"`
+	Vendor     string   `json:"vendor,omitempty"`
+	Product    string   `json:"product,omitempty"`
+	PciAddress string   `json:"pciAddress,omitempty"`
+	Driver     string   `json:"driver,omitempty"`
+	// Speed is link speed in Mbps, 0 if unknown
+	Speed int `json:"speed,omitempty"`
+	// NumaNode is NUMA node of the device, nil if unknown
+	NumaNode *int `json:"numaNode,omitempty"`
+	// SRIOV is true if the device supports SR-IOV virtual functions
+	SRIOV bool `json:"sriov,omitempty"`
 }
 
 func (i InterfaceInfoType) Equal(cmp InterfaceInfoType) bool {
-	return i.InterfaceName == cmp.InterfaceName && i.NetAddress == cmp.NetAddress && i.HostIP == cmp.HostIP
+	return i.InterfaceName == cmp.InterfaceName && i.NetAddress == cmp.NetAddress && i.HostIP == cmp.HostIP && slices.Equal(i.HostIPs, cmp.HostIPs) &&
+		i.deviceEqual(cmp)
+}
+
+// deviceEqual compares device properties used by DeviceClass
+func (i InterfaceInfoType) deviceEqual(cmp InterfaceInfoType) bool {
+	numaEqual := (i.NumaNode == nil && cmp.NumaNode == nil) || (i.NumaNode != nil && cmp.NumaNode != nil && *i.NumaNode == *cmp.NumaNode)
+	return i.Vendor == cmp.Vendor && i.Product == cmp.Product && i.PciAddress == cmp.PciAddress && i.Driver == cmp.Driver &&
+		i.Speed == cmp.Speed && numaEqual && i.SRIOV == cmp.SRIOV
 }
 
 // HostInterfaceSpec defines the desired state of HostInterface
@@ -29,10 +48,17 @@ type HostInterfaceSpec struct {
 }
 
 // HostInterfaceStatus defines the observed state of HostInterface
+// Interfaces lists link state and statistics of each discovered interface reported by the daemon
+// Stat is deprecated and no longer reported, use Interfaces
 type HostInterfaceStatus struct {
-	Stat LinkStat `json:"stat"`
+	Interfaces     []InterfaceLinkStatus `json:"interfaces,omitempty"`
+	LastUpdateTime metav1.Time           `json:"lastUpdateTime,omitempty"`
+	// Deprecated: use Interfaces
+	// +optional
+	Stat LinkStat `json:"stat,omitempty"`
 }
 
+// LinkStat is deprecated statistics of a single interface, replaced by InterfaceLinkStatus
 type LinkStat struct {
 	InterfaceName string `json:"interfaceName"`
 	TxRate        int    `json:"txRate"`
@@ -47,6 +73,36 @@ type LinkStat struct {
 	UsedCount     int    `json:"count"`
 }
 
+// InterfaceLinkStatus defines link state and statistics of an interface
+// OperState is operational state such as up, down, and dormant
+// Speed is link speed in Mbps, 0 if unknown
+// Duplex is full, half, or unknown
+// Rates is the change of counters per second since the previous report
+type InterfaceLinkStatus struct {
+	InterfaceName string       `json:"interfaceName"`
+	OperState     string       `json:"operState,omitempty"`
+	Carrier       bool         `json:"carrier"`
+	MTU           int          `json:"mtu,omitempty"`
+	Speed         int          `json:"speed,omitempty"`
+	Duplex        string       `json:"duplex,omitempty"`
+	Driver        string       `json:"driver,omitempty"`
+	NumaNode      *int         `json:"numaNode,omitempty"`
+	Counters      LinkCounters `json:"counters"`
+	Rates         LinkCounters `json:"rates"`
+}
+
+// LinkCounters holds transmit and receive statistics of an interface
+type LinkCounters struct {
+	TxBytes   int64 `json:"txBytes"`
+	RxBytes   int64 `json:"rxBytes"`
+	TxPackets int64 `json:"txPackets"`
+	RxPackets int64 `json:"rxPackets"`
+	TxErrors  int64 `json:"txErrors"`
+	RxErrors  int64 `json:"rxErrors"`
+	TxDropped int64 `json:"txDropped"`
+	RxDropped int64 `json:"rxDropped"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:resource:scope=Cluster
@@ -12,8 +12,14 @@ import (
 type Allocation struct {
 	Pod       string `json:"pod"`
 	Namespace string `json:"namespace"`
-	Index     int    `json:"index"`
-	Address   string `json:"address"`
+	// PodUID is UID of the pod instance holding the address
+	// +optional
+	PodUID string `json:"podUID,omitempty"`
+	// ContainerID is ID of the pod sandbox passed by CNI
+	// +optional
+	ContainerID string `json:"containerID,omitempty"`
+	Index       int    `json:"index"`
+	Address     string `json:"address"`
 }
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -34,10 +40,29 @@ type IPPoolSpec struct {
 	Allocations      []Allocation `json:"allocations"`
 }
 
+const (
+	// IPPoolExhausted indicates that no usable address is left in the pool
+	IPPoolExhausted = "Exhausted"
+	// IPPoolNearlyExhausted indicates that allocated addresses reach the configured percentage of usable addresses
+	IPPoolNearlyExhausted = "NearlyExhausted"
+)
+
 // IPPoolStatus defines the observed state of IPPool
+// Total is the number of addresses in pod CIDR
+// Usable is the number of assignable addresses which are not excluded
+// Excluded is the number of assignable addresses covered by excludes
+// LargestFreeRange is the largest number of consecutive free addresses
 type IPPoolStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+	Total              int                `json:"total"`
+	Usable             int                `json:"usable"`
+	Allocated          int                `json:"allocated"`
+	Free               int                `json:"free"`
+	Excluded           int                `json:"excluded"`
+	LargestFreeRange   int                `json:"largestFreeRange"`
+	LastAllocationTime *metav1.Time       `json:"lastAllocationTime,omitempty"`
+	Conditions         []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
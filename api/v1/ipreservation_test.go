@@ -0,0 +1,87 @@
+package v1_test
+
+import (
+	. "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("IPReservation", func() {
+	addresses := []ReservedAddress{
+		{InterfaceName: "eth1", Address: "192.168.0.10"},
+		{InterfaceName: "eth2", Address: "fd00::10"},
+	}
+	podSpec := IPReservationSpec{Network: "multinic-sample", PodName: "pod", Addresses: addresses}
+	stsSpec := IPReservationSpec{
+		Network:     "multinic-sample",
+		StatefulSet: &StatefulSetReservation{Name: "worker", Replicas: 2, StartOrdinal: 1},
+		Addresses:   addresses,
+	}
+
+	DescribeTable("validate", func(spec IPReservationSpec, valid bool) {
+		err := spec.Validate()
+		if valid {
+			Expect(err).NotTo(HaveOccurred())
+		} else {
+			Expect(err).To(HaveOccurred())
+		}
+	},
+		Entry("pod name", podSpec, true),
+		Entry("statefulset", stsSpec, true),
+		Entry("no network", IPReservationSpec{PodName: "pod", Addresses: addresses}, false),
+		Entry("no owner", IPReservationSpec{Network: "multinic-sample", Addresses: addresses}, false),
+		Entry("both owners", IPReservationSpec{Network: "multinic-sample", PodName: "pod",
+			StatefulSet: &StatefulSetReservation{Name: "worker", Replicas: 1}, Addresses: addresses}, false),
+		Entry("no replicas", IPReservationSpec{Network: "multinic-sample",
+			StatefulSet: &StatefulSetReservation{Name: "worker"}, Addresses: addresses}, false),
+		Entry("no address", IPReservationSpec{Network: "multinic-sample", PodName: "pod"}, false),
+		Entry("invalid address", IPReservationSpec{Network: "multinic-sample", PodName: "pod",
+			Addresses: []ReservedAddress{{Address: "192.168.0.0/24"}}}, false),
+	)
+
+	DescribeTable("reserved addresses for pod", func(spec IPReservationSpec, podName string, expected []string) {
+		reservedAddresses := spec.ReservedAddressesForPod(podName)
+		if expected == nil {
+			Expect(reservedAddresses).To(BeNil())
+			return
+		}
+		Expect(reservedAddresses).To(HaveLen(len(expected)))
+		for i, reserved := range reservedAddresses {
+			Expect(reserved.InterfaceName).To(Equal(addresses[i].InterfaceName))
+			Expect(reserved.Address).To(Equal(expected[i]))
+		}
+	},
+		Entry("pod name", podSpec, "pod", []string{"192.168.0.10", "fd00::10"}),
+		Entry("other pod", podSpec, "pod-1", nil),
+		Entry("first ordinal", stsSpec, "worker-1", []string{"192.168.0.10", "fd00::10"}),
+		Entry("next ordinal", stsSpec, "worker-2", []string{"192.168.0.11", "fd00::11"}),
+		Entry("ordinal before start", stsSpec, "worker-0", nil),
+		Entry("ordinal out of replicas", stsSpec, "worker-3", nil),
+		Entry("non-numeric ordinal", stsSpec, "worker-a", nil),
+		Entry("other statefulset", stsSpec, "worker-job-1", nil),
+	)
+
+	It("lists all reserved addresses", func() {
+		Expect(podSpec.AllReservedAddresses()).To(Equal(addresses))
+		Expect(stsSpec.AllReservedAddresses()).To(Equal([]ReservedAddress{
+			{InterfaceName: "eth1", Address: "192.168.0.10"},
+			{InterfaceName: "eth2", Address: "fd00::10"},
+			{InterfaceName: "eth1", Address: "192.168.0.11"},
+			{InterfaceName: "eth2", Address: "fd00::11"},
+		}))
+	})
+
+	It("checks reserved allocation", func() {
+		reservations := []IPReservation{
+			{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}, Spec: podSpec},
+			{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"}, Spec: stsSpec},
+		}
+		Expect(IsReservedAllocation(reservations, "eth1", Allocation{Pod: "pod", Namespace: "default", Address: "192.168.0.10"})).To(BeTrue())
+		Expect(IsReservedAllocation(reservations, "eth1", Allocation{Pod: "worker-2", Namespace: "default", Address: "192.168.0.11"})).To(BeTrue())
+		// reserved to other pod, on other interface, or in other namespace
+		Expect(IsReservedAllocation(reservations, "eth1", Allocation{Pod: "other", Namespace: "default", Address: "192.168.0.10"})).To(BeFalse())
+		Expect(IsReservedAllocation(reservations, "eth2", Allocation{Pod: "pod", Namespace: "default", Address: "192.168.0.10"})).To(BeFalse())
+		Expect(IsReservedAllocation(reservations, "eth1", Allocation{Pod: "pod", Namespace: "other", Address: "192.168.0.10"})).To(BeFalse())
+	})
+})
@@ -0,0 +1,199 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package v1
+
+import (
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPReservationSpec defines the desired state of IPReservation
+// Network is name of MultiNicNetwork (and NetworkAttachmentDefinition) using multi-nic-ipam
+// PodName is name of the pod in the same namespace which owns the addresses
+// StatefulSet reserves the addresses to pods of the StatefulSet in the same namespace by ordinal
+// Addresses is list of reserved address on each interface
+// either PodName or StatefulSet must be set
+type IPReservationSpec struct {
+	Network     string                  `json:"network"`
+	PodName     string                  `json:"podName,omitempty"`
+	StatefulSet *StatefulSetReservation `json:"statefulSet,omitempty"`
+	// +kubebuilder:validation:MinItems=1
+	Addresses []ReservedAddress `json:"addresses"`
+}
+
+// StatefulSetReservation reserves consecutive addresses to pods <Name>-<ordinal>
+// where ordinal is in [StartOrdinal, StartOrdinal+Replicas)
+// pod with ordinal StartOrdinal+n owns the reserved address + n
+type StatefulSetReservation struct {
+	Name string `json:"name"`
+	// +kubebuilder:validation:Minimum=1
+	Replicas int `json:"replicas"`
+	// +kubebuilder:validation:Minimum=0
+	StartOrdinal int `json:"startOrdinal,omitempty"`
+}
+
+// ReservedAddress is an address reserved on the host interface (master)
+// empty InterfaceName applies to any interface whose pod CIDR contains the address
+type ReservedAddress struct {
+	InterfaceName string `json:"interfaceName,omitempty"`
+	Address       string `json:"address"`
+}
+
+// IsOnInterface returns true if the address is reserved on the interface (any interface if not specified)
+func (reserved ReservedAddress) IsOnInterface(interfaceName string) bool {
+	return reserved.InterfaceName == "" || reserved.InterfaceName == interfaceName
+}
+
+// Validate checks owner and addresses of the reservation
+func (spec IPReservationSpec) Validate() error {
+	if spec.Network == "" {
+		return fmt.Errorf("network must be set")
+	}
+	if (spec.PodName == "") == (spec.StatefulSet == nil) {
+		return fmt.Errorf("either podName or statefulSet must be set")
+	}
+	if spec.StatefulSet != nil {
+		if spec.StatefulSet.Name == "" || spec.StatefulSet.Replicas < 1 || spec.StatefulSet.StartOrdinal < 0 {
+			return fmt.Errorf("invalid statefulSet %v", *spec.StatefulSet)
+		}
+	}
+	if len(spec.Addresses) == 0 {
+		return fmt.Errorf("no address to reserve")
+	}
+	for _, reserved := range spec.Addresses {
+		if _, err := netip.ParseAddr(reserved.Address); err != nil {
+			return fmt.Errorf("invalid address %s: %v", reserved.Address, err)
+		}
+	}
+	return nil
+}
+
+// GetOrdinal returns ordinal of the pod if the pod belongs to the reserved StatefulSet
+func (s StatefulSetReservation) GetOrdinal(podName string) (int, bool) {
+	prefix := s.Name + "-"
+	if !strings.HasPrefix(podName, prefix) {
+		return -1, false
+	}
+	ordinalStr := strings.TrimPrefix(podName, prefix)
+	ordinal, err := strconv.Atoi(ordinalStr)
+	if err != nil || strconv.Itoa(ordinal) != ordinalStr {
+		return -1, false
+	}
+	if ordinal < s.StartOrdinal || ordinal >= s.StartOrdinal+s.Replicas {
+		return -1, false
+	}
+	return ordinal, true
+}
+
+// ReservedAddressesForPod returns addresses reserved to the pod name in the reservation namespace
+// returns nil if the pod does not own this reservation
+func (spec IPReservationSpec) ReservedAddressesForPod(podName string) []ReservedAddress {
+	if spec.Validate() != nil {
+		return nil
+	}
+	if spec.PodName != "" {
+		if spec.PodName != podName {
+			return nil
+		}
+		return spec.Addresses
+	}
+	ordinal, ok := spec.StatefulSet.GetOrdinal(podName)
+	if !ok {
+		return nil
+	}
+	return offsetAddresses(spec.Addresses, ordinal-spec.StatefulSet.StartOrdinal)
+}
+
+// AllReservedAddresses returns all addresses held by the reservation
+func (spec IPReservationSpec) AllReservedAddresses() []ReservedAddress {
+	if spec.Validate() != nil {
+		return nil
+	}
+	if spec.PodName != "" {
+		return spec.Addresses
+	}
+	reservedAddresses := []ReservedAddress{}
+	for offset := 0; offset < spec.StatefulSet.Replicas; offset++ {
+		reservedAddresses = append(reservedAddresses, offsetAddresses(spec.Addresses, offset)...)
+	}
+	return reservedAddresses
+}
+
+// IsReservedTo returns true if the address on the interface is reserved to the pod
+func (r IPReservation) IsReservedTo(podName, podNamespace, interfaceName, address string) bool {
+	if r.Namespace != podNamespace {
+		return false
+	}
+	for _, reserved := range r.Spec.ReservedAddressesForPod(podName) {
+		if reserved.Address == address && reserved.IsOnInterface(interfaceName) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReservedAllocation returns true if the allocation holds an address reserved to its pod.
+// Such allocation is kept even if the pod is gone so that the address is given back to the pod instance.
+func IsReservedAllocation(reservations []IPReservation, interfaceName string, allocation Allocation) bool {
+	for _, reservation := range reservations {
+		if reservation.IsReservedTo(allocation.Pod, allocation.Namespace, interfaceName, allocation.Address) {
+			return true
+		}
+	}
+	return false
+}
+
+// offsetAddresses shifts each reserved address by offset
+func offsetAddresses(addresses []ReservedAddress, offset int) []ReservedAddress {
+	shifted := []ReservedAddress{}
+	for _, reserved := range addresses {
+		addr, err := netip.ParseAddr(reserved.Address)
+		if err != nil {
+			continue
+		}
+		for i := 0; i < offset && addr.IsValid(); i++ {
+			addr = addr.Next()
+		}
+		if !addr.IsValid() {
+			continue
+		}
+		shifted = append(shifted, ReservedAddress{
+			InterfaceName: reserved.InterfaceName,
+			Address:       addr.String(),
+		})
+	}
+	return shifted
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:printcolumn:name="Network",type=string,JSONPath=`.spec.network`
+//+kubebuilder:printcolumn:name="Pod",type=string,JSONPath=`.spec.podName`
+//+kubebuilder:printcolumn:name="StatefulSet",type=string,JSONPath=`.spec.statefulSet.name`
+
+// IPReservation is the Schema for the ipreservations API
+type IPReservation struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec IPReservationSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// IPReservationList contains a list of IPReservation
+type IPReservationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []IPReservation `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&IPReservation{}, &IPReservationList{})
+}
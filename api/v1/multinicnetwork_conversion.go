@@ -0,0 +1,288 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package v1
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	v2 "github.com/foundation-model-stack/multi-nic-cni/api/v2"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+)
+
+// OriginalIPAMAnnotation keeps spec.ipam string of v1 in v2 object
+// to restore the string as it was written (key order and zero values) when converting back to v1
+const OriginalIPAMAnnotation = "multinic.fms.io/v1-ipam"
+
+// typedIPAMKeys lists ipam keys which are converted to the typed fields of v2 IPAMSpec
+var typedIPAMKeys = []string{"type", "hostBlock", "interfaceBlock", "excludeCIDRs", "vlanMode", "routes", "allocationStrategy", "quarantineSeconds", "rule"}
+
+// typedIPAM holds typed fields of ipam JSON string
+type typedIPAM struct {
+	Type           string     `json:"type"`
+	HostBlock      int        `json:"hostBlock,omitempty"`
+	InterfaceBlock int        `json:"interfaceBlock,omitempty"`
+	ExcludeCIDRs   []string   `json:"excludeCIDRs,omitempty"`
+	VlanMode       string     `json:"vlanMode,omitempty"`
+	Routes         []v2.Route `json:"routes,omitempty"`
+	// AllocationStrategy and QuarantineSeconds are read by the daemon allocator
+	AllocationStrategy string `json:"allocationStrategy,omitempty"`
+	QuarantineSeconds  int    `json:"quarantineSeconds,omitempty"`
+	// Rule is applied by the route handler
+	Rule *v2.PolicyRule `json:"rule,omitempty"`
+}
+
+var _ conversion.Convertible = &MultiNicNetwork{}
+
+// ConvertTo converts this MultiNicNetwork to the hub version (v2)
+func (src *MultiNicNetwork) ConvertTo(dstRaw conversion.Hub) error {
+	dst, ok := dstRaw.(*v2.MultiNicNetwork)
+	if !ok {
+		return fmt.Errorf("expected a v2 MultiNicNetwork object but got %T", dstRaw)
+	}
+	ipam, err := ConvertIPAMToV2(src.Spec.IPAM)
+	if err != nil {
+		return fmt.Errorf("failed to convert ipam of %s: %v", src.GetName(), err)
+	}
+	ipam.Subnet = src.Spec.Subnet
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Annotations = copyAnnotations(src.Annotations)
+	if src.Spec.IPAM != "" {
+		if dst.Annotations == nil {
+			dst.Annotations = make(map[string]string)
+		}
+		dst.Annotations[OriginalIPAMAnnotation] = src.Spec.IPAM
+	} else {
+		delete(dst.Annotations, OriginalIPAMAnnotation)
+	}
+	dst.Spec = v2.MultiNicNetworkSpec{
+		MasterNetAddrs: src.Spec.MasterNetAddrs,
+		IPAM:           ipam,
+		MainPlugin: v2.PluginSpec{
+			CNIVersion:   src.Spec.MainPlugin.CNIVersion,
+			Type:         src.Spec.MainPlugin.Type,
+			Capabilities: src.Spec.MainPlugin.Capabilities,
+			DNS:          v2.DNS(src.Spec.MainPlugin.DNS),
+			CNIArgs:      src.Spec.MainPlugin.CNIArgs,
+		},
+		Policy: v2.AttachmentPolicy{
+			Strategy:    src.Spec.Policy.Strategy,
+			Target:      src.Spec.Policy.Target,
+			Placement:   src.Spec.Policy.Placement,
+			OnUnhealthy: src.Spec.Policy.OnUnhealthy,
+		},
+		Namespaces:    src.Spec.Namespaces,
+		RailOptimized: src.Spec.RailOptimized,
+	}
+	if src.Spec.Policy.Pipeline != nil {
+		dst.Spec.Policy.Pipeline = make([]v2.SelectionStage, len(src.Spec.Policy.Pipeline))
+		for i, stage := range src.Spec.Policy.Pipeline {
+			dst.Spec.Policy.Pipeline[i] = v2.SelectionStage(stage)
+		}
+	}
+	computeResults := make([]v2.NicNetworkResult, len(src.Status.ComputeResults))
+	for i, result := range src.Status.ComputeResults {
+		computeResults[i] = v2.NicNetworkResult(result)
+	}
+	if src.Status.ComputeResults == nil {
+		computeResults = nil
+	}
+	dst.Status = v2.MultiNicNetworkStatus{
+		ComputeResults:  computeResults,
+		DiscoverStatus:  v2.DiscoverStatus(src.Status.DiscoverStatus),
+		NetConfigStatus: v2.NetConfigStatus(src.Status.NetConfigStatus),
+		RouteStatus:     v2.RouteStatus(src.Status.RouteStatus),
+		Message:         src.Status.Message,
+		LastSyncTime:    src.Status.LastSyncTime,
+		Conditions:      src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertFrom converts from the hub version (v2) to this version.
+// multiNICIPAM is derived from the ipam type.
+func (dst *MultiNicNetwork) ConvertFrom(srcRaw conversion.Hub) error {
+	src, ok := srcRaw.(*v2.MultiNicNetwork)
+	if !ok {
+		return fmt.Errorf("expected a v2 MultiNicNetwork object but got %T", srcRaw)
+	}
+	ipam, err := ConvertIPAMFromV2(src.Spec.IPAM)
+	if err != nil {
+		return fmt.Errorf("failed to convert ipam of %s: %v", src.GetName(), err)
+	}
+	if original, found := src.Annotations[OriginalIPAMAnnotation]; found {
+		ipam = restoreIPAM(original, ipam)
+	}
+
+	dst.ObjectMeta = src.ObjectMeta
+	dst.Annotations = copyAnnotations(src.Annotations)
+	delete(dst.Annotations, OriginalIPAMAnnotation)
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	dst.Spec = MultiNicNetworkSpec{
+		MasterNetAddrs: src.Spec.MasterNetAddrs,
+		Subnet:         src.Spec.IPAM.Subnet,
+		IPAM:           ipam,
+		IsMultiNICIPAM: src.Spec.IPAM.Type == MultiNICIPAMType,
+		MainPlugin: PluginSpec{
+			CNIVersion:   src.Spec.MainPlugin.CNIVersion,
+			Type:         src.Spec.MainPlugin.Type,
+			Capabilities: src.Spec.MainPlugin.Capabilities,
+			DNS:          DNS(src.Spec.MainPlugin.DNS),
+			CNIArgs:      src.Spec.MainPlugin.CNIArgs,
+		},
+		Policy: AttachmentPolicy{
+			Strategy:    src.Spec.Policy.Strategy,
+			Target:      src.Spec.Policy.Target,
+			Placement:   src.Spec.Policy.Placement,
+			OnUnhealthy: src.Spec.Policy.OnUnhealthy,
+		},
+		Namespaces:    src.Spec.Namespaces,
+		RailOptimized: src.Spec.RailOptimized,
+	}
+	if src.Spec.Policy.Pipeline != nil {
+		dst.Spec.Policy.Pipeline = make([]SelectionStage, len(src.Spec.Policy.Pipeline))
+		for i, stage := range src.Spec.Policy.Pipeline {
+			dst.Spec.Policy.Pipeline[i] = SelectionStage(stage)
+		}
+	}
+	computeResults := make([]NicNetworkResult, len(src.Status.ComputeResults))
+	for i, result := range src.Status.ComputeResults {
+		computeResults[i] = NicNetworkResult(result)
+	}
+	if src.Status.ComputeResults == nil {
+		computeResults = nil
+	}
+	dst.Status = MultiNicNetworkStatus{
+		ComputeResults:  computeResults,
+		DiscoverStatus:  DiscoverStatus(src.Status.DiscoverStatus),
+		NetConfigStatus: NetConfigStatus(src.Status.NetConfigStatus),
+		RouteStatus:     RouteStatus(src.Status.RouteStatus),
+		Message:         src.Status.Message,
+		LastSyncTime:    src.Status.LastSyncTime,
+		Conditions:      src.Status.Conditions,
+	}
+	return nil
+}
+
+// ConvertIPAMToV2 parses ipam JSON string into typed v2 IPAMSpec,
+// keys other than typedIPAMKeys are kept in Args
+func ConvertIPAMToV2(ipam string) (v2.IPAMSpec, error) {
+	ipamSpec := v2.IPAMSpec{}
+	if ipam == "" {
+		return ipamSpec, nil
+	}
+	typed := typedIPAM{}
+	if err := json.Unmarshal([]byte(ipam), &typed); err != nil {
+		return ipamSpec, err
+	}
+	args := make(map[string]json.RawMessage)
+	if err := json.Unmarshal([]byte(ipam), &args); err != nil {
+		return ipamSpec, err
+	}
+	for _, key := range typedIPAMKeys {
+		delete(args, key)
+	}
+	ipamSpec.Type = typed.Type
+	ipamSpec.HostBlock = typed.HostBlock
+	ipamSpec.InterfaceBlock = typed.InterfaceBlock
+	ipamSpec.ExcludeCIDRs = typed.ExcludeCIDRs
+	ipamSpec.VlanMode = typed.VlanMode
+	ipamSpec.Routes = typed.Routes
+	ipamSpec.AllocationStrategy = typed.AllocationStrategy
+	ipamSpec.QuarantineSeconds = typed.QuarantineSeconds
+	ipamSpec.Rule = typed.Rule
+	if len(args) > 0 {
+		raw, err := json.Marshal(args)
+		if err != nil {
+			return ipamSpec, err
+		}
+		ipamSpec.Args = &runtime.RawExtension{Raw: raw}
+	}
+	return ipamSpec, nil
+}
+
+// ConvertIPAMFromV2 composes ipam JSON string from typed v2 IPAMSpec and its Args
+// Subnet is not included as it is set to spec.subnet of v1
+func ConvertIPAMFromV2(ipamSpec v2.IPAMSpec) (string, error) {
+	if ipamSpec.Type == "" && ipamSpec.Args == nil {
+		return "", nil
+	}
+	ipam := make(map[string]interface{})
+	if ipamSpec.Args != nil && len(ipamSpec.Args.Raw) > 0 {
+		if err := json.Unmarshal(ipamSpec.Args.Raw, &ipam); err != nil {
+			return "", err
+		}
+	}
+	typed, err := json.Marshal(typedIPAM{
+		Type:           ipamSpec.Type,
+		HostBlock:      ipamSpec.HostBlock,
+		InterfaceBlock: ipamSpec.InterfaceBlock,
+		ExcludeCIDRs:   ipamSpec.ExcludeCIDRs,
+		VlanMode:       ipamSpec.VlanMode,
+		Routes:         ipamSpec.Routes,
+
+		AllocationStrategy: ipamSpec.AllocationStrategy,
+		QuarantineSeconds:  ipamSpec.QuarantineSeconds,
+		Rule:               ipamSpec.Rule,
+	})
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(typed, &ipam); err != nil {
+		return "", err
+	}
+	ipamBytes, err := json.Marshal(ipam)
+	if err != nil {
+		return "", err
+	}
+	return string(ipamBytes), nil
+}
+
+// restoreIPAM returns the original ipam string if it is still semantically equal to the converted one,
+// i.e., ipam of v2 has not been changed since converted from the original
+func restoreIPAM(original string, converted string) string {
+	ipamSpec, err := ConvertIPAMToV2(original)
+	if err != nil {
+		return converted
+	}
+	normalized, err := ConvertIPAMFromV2(ipamSpec)
+	if err != nil || !isJSONEqual(normalized, converted) {
+		return converted
+	}
+	return original
+}
+
+// isJSONEqual checks if two JSON strings represent the same value
+func isJSONEqual(a string, b string) bool {
+	if a == "" || b == "" {
+		return a == b
+	}
+	var aValue, bValue interface{}
+	if err := json.Unmarshal([]byte(a), &aValue); err != nil {
+		return false
+	}
+	if err := json.Unmarshal([]byte(b), &bValue); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+// copyAnnotations returns a copy of annotations not to modify the source object
+func copyAnnotations(annotations map[string]string) map[string]string {
+	if annotations == nil {
+		return nil
+	}
+	copied := make(map[string]string, len(annotations))
+	for key, value := range annotations {
+		copied[key] = value
+	}
+	return copied
+}
@@ -0,0 +1,111 @@
+package v1_test
+
+import (
+	. "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	v2 "github.com/foundation-model-stack/multi-nic-cni/api/v2"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("MultiNicNetwork Conversion", func() {
+	DescribeTable("convert ipam to v2 and back", func(ipam string, expectedType string, expectArgs bool) {
+		ipamSpec, err := ConvertIPAMToV2(ipam)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ipamSpec.Type).To(Equal(expectedType))
+		Expect(ipamSpec.Args != nil).To(Equal(expectArgs))
+		converted, err := ConvertIPAMFromV2(ipamSpec)
+		Expect(err).NotTo(HaveOccurred())
+		if ipam == "" {
+			Expect(converted).To(BeEmpty())
+		} else {
+			Expect(converted).To(MatchJSON(ipam))
+		}
+	},
+		Entry("multi-nic-ipam", validIPAM, MultiNICIPAMType, false),
+		Entry("multi-nic-ipam with excludes and routes",
+			`{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "excludeCIDRs": ["192.168.0.0/32"], "vlanMode": "l2", "routes": [{"dst": "10.0.0.0/8", "gw": "192.168.0.1"}]}`,
+			MultiNICIPAMType, false),
+		Entry("multi-nic-ipam with allocation strategy",
+			`{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "allocationStrategy": "lru", "quarantineSeconds": 300}`,
+			MultiNICIPAMType, false),
+		Entry("multi-nic-ipam with policy rule",
+			`{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "vlanMode": "l3", "rule": {"priority": 1000, "fwmark": "0x10/0xff", "iif": "lo"}}`,
+			MultiNICIPAMType, false),
+		Entry("whereabouts", `{"type": "whereabouts", "range": "10.0.0.0/24", "exclude": ["10.0.0.1/32"]}`, "whereabouts", true),
+		Entry("empty", "", "", false),
+	)
+
+	DescribeTable("restores the exact ipam string through v2", func(ipam string, modify func(*v2.MultiNicNetwork), expectOriginal bool) {
+		src := newMultiNicNetwork("192.168.0.0/16", ipam, "ipvlan", "none")
+		src.Annotations = map[string]string{"owner": "gitops"}
+		hub := &v2.MultiNicNetwork{}
+		Expect(src.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Annotations).To(HaveKeyWithValue(OriginalIPAMAnnotation, ipam))
+		Expect(src.Annotations).NotTo(HaveKey(OriginalIPAMAnnotation))
+		if modify != nil {
+			modify(hub)
+		}
+		dst := &MultiNicNetwork{}
+		Expect(dst.ConvertFrom(hub)).To(Succeed())
+		Expect(dst.Annotations).To(Equal(map[string]string{"owner": "gitops"}))
+		if expectOriginal {
+			Expect(dst.Spec.IPAM).To(Equal(ipam))
+		} else {
+			Expect(dst.Spec.IPAM).NotTo(Equal(ipam))
+		}
+	},
+		Entry("key order and spaces", `{"vlanMode": "l3", "type": "multi-nic-ipam",  "interfaceBlock": 2, "hostBlock": 8}`, nil, true),
+		Entry("zero values", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "quarantineSeconds": 0, "excludeCIDRs": []}`, nil, true),
+		Entry("args", `{"type": "whereabouts", "range": "10.0.0.0/24", "exclude": []}`, nil, true),
+		Entry("changed in v2", `{"vlanMode": "l3", "type": "multi-nic-ipam", "interfaceBlock": 2, "hostBlock": 8}`, func(hub *v2.MultiNicNetwork) {
+			hub.Spec.IPAM.HostBlock = 6
+		}, false),
+	)
+
+	It("fails on unparsable ipam", func() {
+		_, err := ConvertIPAMToV2(`{"type": `)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("converts MultiNicNetwork to v2 and back", func() {
+		src := newMultiNicNetwork("192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "vlanMode": "l3", "excludeCIDRs": ["192.168.0.1/32"], "rule": {"priority": 1000, "iif": "lo"}}`, "ipvlan", "costOpt")
+		src.Spec.IsMultiNICIPAM = true
+		src.Spec.MasterNetAddrs = []string{"10.0.0.0/24"}
+		src.Spec.MainPlugin.CNIArgs = map[string]string{"mode": "l3"}
+		src.Spec.Policy.Pipeline = []SelectionStage{{Type: "devClass", Class: "highspeed"}, {Type: "names", Names: []string{"eth1"}}, {Type: "costOpt", Placement: "pack"}}
+		src.Spec.Policy.OnUnhealthy = "fail"
+		src.Spec.RailOptimized = true
+		src.Status = MultiNicNetworkStatus{
+			ComputeResults:  []NicNetworkResult{{NetAddress: "10.0.0.0/24", NumOfHost: 2}},
+			DiscoverStatus:  DiscoverStatus{ExistDaemon: 2, InterfaceInfoAvailable: 2, CIDRProcessedHost: 2},
+			NetConfigStatus: ConfigComplete,
+			RouteStatus:     AllRouteApplied,
+			Conditions:      []metav1.Condition{{Type: Degraded, Status: metav1.ConditionFalse, Reason: "AsExpected"}},
+		}
+
+		hub := &v2.MultiNicNetwork{}
+		Expect(src.ConvertTo(hub)).To(Succeed())
+		Expect(hub.Spec.IPAM.Type).To(Equal(MultiNICIPAMType))
+		Expect(hub.Spec.IPAM.Subnet).To(Equal("192.168.0.0/16"))
+		Expect(hub.Spec.IPAM.HostBlock).To(Equal(8))
+		Expect(hub.Spec.IPAM.InterfaceBlock).To(Equal(2))
+		Expect(hub.Spec.IPAM.VlanMode).To(Equal("l3"))
+		Expect(hub.Spec.IPAM.ExcludeCIDRs).To(Equal([]string{"192.168.0.1/32"}))
+		Expect(hub.Spec.IPAM.Rule).To(Equal(&v2.PolicyRule{Priority: 1000, Iif: "lo"}))
+		Expect(hub.Spec.IPAM.Args).To(BeNil())
+		Expect(hub.Spec.MainPlugin.CNIArgs).To(HaveKeyWithValue("mode", "l3"))
+		Expect(string(hub.Status.RouteStatus)).To(Equal(string(AllRouteApplied)))
+		Expect(hub.Spec.Policy.Pipeline).To(HaveLen(3))
+		Expect(hub.Spec.Policy.Pipeline[2]).To(Equal(v2.SelectionStage{Type: "costOpt", Placement: "pack"}))
+		Expect(hub.Spec.Policy.OnUnhealthy).To(Equal("fail"))
+		Expect(hub.Spec.RailOptimized).To(BeTrue())
+
+		dst := &MultiNicNetwork{}
+		Expect(dst.ConvertFrom(hub)).To(Succeed())
+		Expect(dst.Spec.IPAM).To(MatchJSON(src.Spec.IPAM))
+		dst.Spec.IPAM = src.Spec.IPAM
+		Expect(dst.Spec).To(Equal(src.Spec))
+		Expect(dst.Status).To(Equal(src.Status))
+	})
+})
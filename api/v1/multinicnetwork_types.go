@@ -18,6 +18,7 @@ import (
 // IPAM is ipam specification
 // MainPlugin is plugin specification
 // Policy is general policy of the pool
+// RailOptimized is true if each master network is a rail cabled to the same switch rail on every node (rail i is the i-th of MasterNetAddrs)
 type MultiNicNetworkSpec struct {
 	MasterNetAddrs []string         `json:"masterNets,omitempty"`
 	Subnet         string           `json:"subnet,omitempty"`
@@ -26,6 +27,8 @@ type MultiNicNetworkSpec struct {
 	MainPlugin     PluginSpec       `json:"plugin"`
 	Policy         AttachmentPolicy `json:"attachPolicy,omitempty"`
 	Namespaces     []string         `json:"namespaces,omitempty"`
+	// +optional
+	RailOptimized bool `json:"railOptimized,omitempty"`
 }
 
 // reference: github.com/containernetworking/cni/pkg/types
@@ -49,9 +52,33 @@ type DNS struct {
 // Strategy is one of None, CostOpt, PerfOpt, QoSClass
 // Target is target bandwidth in a format (d+)Gbps, (d+)Mbps, (d+)Kbps
 // required for CostOpt and PerfOpt
+// Placement is balance (default) to spread pods over NICs or pack to fill as few NICs as possible (CostOpt)
+// Pipeline is ordered filter and score stages evaluated instead of a single Strategy
+// OnUnhealthy is degrade (default) to attach fewer NICs or fail to fail the pod when NICs with link down or flapping are excluded
 type AttachmentPolicy struct {
 	Strategy string `json:"strategy"`
 	Target   string `json:"target,omitempty"`
+	// +kubebuilder:validation:Enum=balance;pack
+	Placement string `json:"placement,omitempty"`
+	// +optional
+	Pipeline []SelectionStage `json:"pipeline,omitempty"`
+	// +kubebuilder:validation:Enum=degrade;fail
+	OnUnhealthy string `json:"onUnhealthy,omitempty"`
+}
+
+// SelectionStage is a stage of NIC selection pipeline
+// filter stages (devClass, linkUp, names) remove NICs from the candidates
+// score stages (topology, perfOpt, costOpt) rank the candidates, a later score stage breaks ties of the earlier ones
+// Class is DeviceClass name of devClass stage (default: class in pod annotation)
+// Names is interface names kept by names stage
+// Placement is balance or pack of costOpt stage (default: Placement of the policy)
+type SelectionStage struct {
+	// +kubebuilder:validation:Enum=devClass;linkUp;names;topology;perfOpt;costOpt
+	Type  string   `json:"type"`
+	Class string   `json:"class,omitempty"`
+	Names []string `json:"names,omitempty"`
+	// +kubebuilder:validation:Enum=balance;pack
+	Placement string `json:"placement,omitempty"`
 }
 
 // +enum
@@ -88,6 +115,24 @@ const (
 	ConfigFailed NetConfigStatus = "Failed"
 )
 
+// condition types of MultiNicNetwork
+const (
+	// NetAttachDefReady indicates that NetworkAttachmentDefinitions are generated
+	NetAttachDefReady = "NetAttachDefReady"
+
+	// CIDRComputed indicates that pod CIDRs are computed for all hosts with interface information
+	CIDRComputed = "CIDRComputed"
+
+	// RoutesApplied indicates that L3 routes are applied to all hosts or not required
+	RoutesApplied = "RoutesApplied"
+
+	// DaemonsDiscovered indicates that all daemons report their interface information
+	DaemonsDiscovered = "DaemonsDiscovered"
+
+	// Degraded indicates that the network configuration or some route has failed
+	Degraded = "Degraded"
+)
+
 type NicNetworkResult struct {
 	NetAddress string `json:"netAddress"`
 	NumOfHost  int    `json:"numOfHosts"`
@@ -107,6 +152,10 @@ type MultiNicNetworkStatus struct {
 	RouteStatus     `json:"routeStatus"`
 	Message         string      `json:"message"`
 	LastSyncTime    metav1.Time `json:"lastSyncTime"`
+	// Conditions represent the latest observations of the network state
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,355 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/foundation-model-stack/multi-nic-cni/internal/compute"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	MultiNICIPAMType  = "multi-nic-ipam"
+	DefaultCNIVersion = "0.3.0"
+	DefaultVlanMode   = "l2"
+	DefaultStrategy   = "none"
+	// MainRulePriority is priority of the default rule to the main table, rules of multi-nic-ipam must precede it
+	MainRulePriority = 32766
+)
+
+var (
+	// SupportedPluginTypes lists main plugin types handled by the operator
+	SupportedPluginTypes = []string{"ipvlan", "macvlan", "sriov", "aws-ipvlan", "mellanox"}
+	// SupportedStrategies lists attachment policy strategies handled by the daemon selector
+	SupportedStrategies = []string{"none", "costOpt", "perfOpt", "devClass", "topology"}
+	// SupportedPlacements lists attachment policy placements of costOpt strategy
+	SupportedPlacements = []string{"balance", "pack"}
+	// SupportedUnhealthyActions lists actions of attachment policy when NICs are excluded for unhealthy links
+	SupportedUnhealthyActions = []string{"degrade", "fail"}
+	// SupportedSelectionStages lists stage types of attachment policy pipeline
+	SupportedSelectionStages = []string{"devClass", "linkUp", "names", "topology", "perfOpt", "costOpt"}
+	// SupportedVlanModes lists vlanMode values of multi-nic-ipam
+	SupportedVlanModes = []string{"l2", "l3", "l3s"}
+	// SupportedAllocationStrategies lists allocationStrategy values of multi-nic-ipam handled by the daemon allocator
+	SupportedAllocationStrategies = []string{"sequential", "lowestFree", "random", "lru"}
+)
+
+// log is for logging in this package.
+var multinicnetworklog = logf.Log.WithName("multinicnetwork-resource")
+
+// SetupMultiNicNetworkConversionWebhookWithManager registers the conversion webhook between v1 and v2 (hub) MultiNicNetwork,
+// which must be served whenever the CRD uses Webhook conversion
+func SetupMultiNicNetworkConversionWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&MultiNicNetwork{}).Complete()
+}
+
+// SetupMultiNicNetworkWebhookWithManager registers the defaulting and validating webhook for MultiNicNetwork
+func SetupMultiNicNetworkWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&MultiNicNetwork{}).
+		WithDefaulter(&MultiNicNetworkCustomDefaulter{}).
+		WithValidator(&MultiNicNetworkCustomValidator{}).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/mutate-multinic-fms-io-v1-multinicnetwork,mutating=true,failurePolicy=fail,sideEffects=None,groups=multinic.fms.io,resources=multinicnetworks,verbs=create;update,versions=v1,name=mmultinicnetwork.kb.io,admissionReviewVersions=v1
+
+// MultiNicNetworkCustomDefaulter fills default values of MultiNicNetwork
+type MultiNicNetworkCustomDefaulter struct{}
+
+var _ webhook.CustomDefaulter = &MultiNicNetworkCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter
+func (d *MultiNicNetworkCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	multinicnetwork, ok := obj.(*MultiNicNetwork)
+	if !ok {
+		return fmt.Errorf("expected a MultiNicNetwork object but got %T", obj)
+	}
+	multinicnetworklog.V(1).Info("default", "name", multinicnetwork.GetName())
+	multinicnetwork.Spec.Default()
+	return nil
+}
+
+// Default sets cniVersion, attachment strategy, and vlanMode of multi-nic-ipam if not specified
+func (spec *MultiNicNetworkSpec) Default() {
+	if spec.MainPlugin.CNIVersion == "" {
+		spec.MainPlugin.CNIVersion = DefaultCNIVersion
+	}
+	if spec.Policy.Strategy == "" {
+		spec.Policy.Strategy = DefaultStrategy
+	}
+	// keep unparsable ipam as it is and let the validator report it
+	ipam := make(map[string]interface{})
+	if err := json.Unmarshal([]byte(spec.IPAM), &ipam); err != nil {
+		return
+	}
+	if ipam["type"] != MultiNICIPAMType {
+		return
+	}
+	if mode, ok := ipam["vlanMode"].(string); ok && mode != "" {
+		return
+	}
+	ipam["vlanMode"] = DefaultVlanMode
+	if ipamBytes, err := json.Marshal(ipam); err == nil {
+		spec.IPAM = string(ipamBytes)
+	}
+}
+
+//+kubebuilder:webhook:path=/validate-multinic-fms-io-v1-multinicnetwork,mutating=false,failurePolicy=fail,sideEffects=None,groups=multinic.fms.io,resources=multinicnetworks,verbs=create;update,versions=v1,name=vmultinicnetwork.kb.io,admissionReviewVersions=v1
+
+// MultiNicNetworkCustomValidator validates MultiNicNetwork on create and update
+type MultiNicNetworkCustomValidator struct{}
+
+var _ webhook.CustomValidator = &MultiNicNetworkCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator
+func (v *MultiNicNetworkCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	multinicnetwork, ok := obj.(*MultiNicNetwork)
+	if !ok {
+		return nil, fmt.Errorf("expected a MultiNicNetwork object but got %T", obj)
+	}
+	multinicnetworklog.V(1).Info("validate create", "name", multinicnetwork.GetName())
+	return nil, toInvalidError(multinicnetwork, validateSpec(&multinicnetwork.Spec))
+}
+
+// ValidateUpdate implements webhook.CustomValidator
+func (v *MultiNicNetworkCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldNetwork, ok := oldObj.(*MultiNicNetwork)
+	if !ok {
+		return nil, fmt.Errorf("expected a MultiNicNetwork object for the old object but got %T", oldObj)
+	}
+	multinicnetwork, ok := newObj.(*MultiNicNetwork)
+	if !ok {
+		return nil, fmt.Errorf("expected a MultiNicNetwork object for the new object but got %T", newObj)
+	}
+	multinicnetworklog.V(1).Info("validate update", "name", multinicnetwork.GetName())
+	if multinicnetwork.GetDeletionTimestamp() != nil || equality.Semantic.DeepEqual(oldNetwork.Spec, multinicnetwork.Spec) {
+		// allow metadata-only changes such as finalizer removal
+		return nil, nil
+	}
+	errs := validateSpec(&multinicnetwork.Spec)
+	errs = append(errs, validateImmutableSpec(&oldNetwork.Spec, &multinicnetwork.Spec)...)
+	return nil, toInvalidError(multinicnetwork, errs)
+}
+
+// ValidateDelete implements webhook.CustomValidator
+func (v *MultiNicNetworkCustomValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+func toInvalidError(multinicnetwork *MultiNicNetwork, errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("MultiNicNetwork").GroupKind(), multinicnetwork.GetName(), errs)
+}
+
+// parseIPAM returns multi-nic-ipam config or nil if ipam is of another type
+func parseIPAM(ipam string) (*PluginConfig, error) {
+	simpleIPAM := struct {
+		Type string `json:"type"`
+	}{}
+	if err := json.Unmarshal([]byte(ipam), &simpleIPAM); err != nil {
+		return nil, err
+	}
+	if simpleIPAM.Type != MultiNICIPAMType {
+		return nil, nil
+	}
+	ipamConfig := &PluginConfig{}
+	if err := json.Unmarshal([]byte(ipam), ipamConfig); err != nil {
+		return nil, err
+	}
+	return ipamConfig, nil
+}
+
+func validateSpec(spec *MultiNicNetworkSpec) field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	pluginTypePath := specPath.Child("plugin", "type")
+	if spec.MainPlugin.Type == "" {
+		errs = append(errs, field.Required(pluginTypePath, "plugin type must be specified"))
+	} else if !slices.Contains(SupportedPluginTypes, spec.MainPlugin.Type) {
+		errs = append(errs, field.NotSupported(pluginTypePath, spec.MainPlugin.Type, SupportedPluginTypes))
+	}
+
+	if spec.Policy.Strategy != "" && !slices.Contains(SupportedStrategies, spec.Policy.Strategy) {
+		errs = append(errs, field.NotSupported(specPath.Child("attachPolicy", "strategy"), spec.Policy.Strategy, SupportedStrategies))
+	}
+	if spec.Policy.Placement != "" && !slices.Contains(SupportedPlacements, spec.Policy.Placement) {
+		errs = append(errs, field.NotSupported(specPath.Child("attachPolicy", "placement"), spec.Policy.Placement, SupportedPlacements))
+	}
+	if spec.Policy.OnUnhealthy != "" && !slices.Contains(SupportedUnhealthyActions, spec.Policy.OnUnhealthy) {
+		errs = append(errs, field.NotSupported(specPath.Child("attachPolicy", "onUnhealthy"), spec.Policy.OnUnhealthy, SupportedUnhealthyActions))
+	}
+	errs = append(errs, validatePipeline(spec.Policy, specPath.Child("attachPolicy"))...)
+	if spec.RailOptimized && len(spec.MasterNetAddrs) == 0 {
+		errs = append(errs, field.Required(specPath.Child("masterNets"), "rail-optimized network must list master networks in rail order"))
+	}
+
+	ipamPath := specPath.Child("ipam")
+	ipamConfig, err := parseIPAM(spec.IPAM)
+	if err != nil {
+		return append(errs, field.Invalid(ipamPath, spec.IPAM, fmt.Sprintf("cannot parse ipam: %v", err)))
+	}
+	if ipamConfig != nil {
+		errs = append(errs, validateMultiNICIPAM(spec.Subnet, ipamConfig, specPath)...)
+		errs = append(errs, validateAllocationStrategy(spec.IPAM, specPath)...)
+	}
+	return errs
+}
+
+// validatePipeline checks selection stages of attachment policy, strategy is a shorthand of single-stage pipeline
+func validatePipeline(policy AttachmentPolicy, policyPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(policy.Pipeline) == 0 {
+		return errs
+	}
+	if policy.Strategy != "" && policy.Strategy != DefaultStrategy {
+		errs = append(errs, field.Invalid(policyPath.Child("strategy"), policy.Strategy, "must be none when pipeline is set"))
+	}
+	for i, stage := range policy.Pipeline {
+		stagePath := policyPath.Child("pipeline").Index(i)
+		if !slices.Contains(SupportedSelectionStages, stage.Type) {
+			errs = append(errs, field.NotSupported(stagePath.Child("type"), stage.Type, SupportedSelectionStages))
+		}
+		if stage.Type == "names" && len(stage.Names) == 0 {
+			errs = append(errs, field.Required(stagePath.Child("names"), "names stage must list interface names"))
+		}
+		if stage.Placement != "" && !slices.Contains(SupportedPlacements, stage.Placement) {
+			errs = append(errs, field.NotSupported(stagePath.Child("placement"), stage.Placement, SupportedPlacements))
+		}
+	}
+	return errs
+}
+
+// validateAllocationStrategy checks allocationStrategy and quarantineSeconds of multi-nic-ipam
+func validateAllocationStrategy(ipam string, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	ipamPath := specPath.Child("ipam")
+	allocationConfig := struct {
+		AllocationStrategy string `json:"allocationStrategy,omitempty"`
+		QuarantineSeconds  int    `json:"quarantineSeconds,omitempty"`
+	}{}
+	if err := json.Unmarshal([]byte(ipam), &allocationConfig); err != nil {
+		return append(errs, field.Invalid(ipamPath, ipam, fmt.Sprintf("cannot parse ipam: %v", err)))
+	}
+	if allocationConfig.AllocationStrategy != "" && !slices.Contains(SupportedAllocationStrategies, allocationConfig.AllocationStrategy) {
+		errs = append(errs, field.NotSupported(ipamPath.Key("allocationStrategy"), allocationConfig.AllocationStrategy, SupportedAllocationStrategies))
+	}
+	if allocationConfig.QuarantineSeconds < 0 {
+		errs = append(errs, field.Invalid(ipamPath.Key("quarantineSeconds"), allocationConfig.QuarantineSeconds, "must be non-negative"))
+	}
+	return errs
+}
+
+// validatePolicyRule checks that the rule is looked up before the main table and fwmark is in the form of mark or mark/mask
+func validatePolicyRule(rule PolicyRule, rulePath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if rule.Priority < 0 || rule.Priority >= MainRulePriority {
+		errs = append(errs, field.Invalid(rulePath.Key("priority"), rule.Priority, fmt.Sprintf("must be between 1 and %d", MainRulePriority-1)))
+	}
+	if rule.FwMark != "" {
+		for _, value := range strings.SplitN(rule.FwMark, "/", 2) {
+			if _, err := strconv.ParseUint(strings.TrimSpace(value), 0, 32); err != nil {
+				errs = append(errs, field.Invalid(rulePath.Key("fwmark"), rule.FwMark, "must be mark or mark/mask of 32-bit numbers"))
+				break
+			}
+		}
+	}
+	return errs
+}
+
+// validateMultiNICIPAM checks that the subnet can be divided by hostBlock and interfaceBlock
+func validateMultiNICIPAM(subnet string, ipamConfig *PluginConfig, specPath *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	subnetPath := specPath.Child("subnet")
+	ipamPath := specPath.Child("ipam")
+
+	if ipamConfig.VlanMode != "" && !slices.Contains(SupportedVlanModes, ipamConfig.VlanMode) {
+		errs = append(errs, field.NotSupported(ipamPath.Key("vlanMode"), ipamConfig.VlanMode, SupportedVlanModes))
+	}
+	if ipamConfig.HostBlock < 0 {
+		errs = append(errs, field.Invalid(ipamPath.Key("hostBlock"), ipamConfig.HostBlock, "must be non-negative"))
+	}
+	if ipamConfig.InterfaceBlock < 0 {
+		errs = append(errs, field.Invalid(ipamPath.Key("interfaceBlock"), ipamConfig.InterfaceBlock, "must be non-negative"))
+	}
+	for index, excludeCIDR := range ipamConfig.ExcludeCIDRs {
+		if _, _, err := net.ParseCIDR(excludeCIDR); err != nil {
+			errs = append(errs, field.Invalid(ipamPath.Key("excludeCIDRs").Index(index), excludeCIDR, err.Error()))
+		}
+	}
+	if ipamConfig.Rule != nil {
+		errs = append(errs, validatePolicyRule(*ipamConfig.Rule, ipamPath.Key("rule"))...)
+	}
+
+	if subnet == "" {
+		return append(errs, field.Required(subnetPath, "subnet is required by "+MultiNICIPAMType))
+	}
+	subnets := compute.SplitSubnets(subnet)
+	families := make(map[bool]bool)
+	for _, singleSubnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(singleSubnet)
+		if err != nil {
+			errs = append(errs, field.Invalid(subnetPath, subnet, err.Error()))
+			continue
+		}
+		isIPv6 := ipNet.IP.To4() == nil
+		if families[isIPv6] {
+			errs = append(errs, field.Invalid(subnetPath, subnet, "only one subnet per IP family is allowed"))
+			continue
+		}
+		families[isIPv6] = true
+		ones, bits := ipNet.Mask.Size()
+		if ones+ipamConfig.HostBlock+ipamConfig.InterfaceBlock >= bits {
+			msg := fmt.Sprintf("hostBlock (%d) + interfaceBlock (%d) must be less than %d to fit in %s",
+				ipamConfig.HostBlock, ipamConfig.InterfaceBlock, bits-ones, singleSubnet)
+			errs = append(errs, field.Invalid(ipamPath, ipamConfig.HostBlock+ipamConfig.InterfaceBlock, msg))
+		}
+	}
+	return errs
+}
+
+// validateImmutableSpec forbids changes that would orphan the existing IPPools
+func validateImmutableSpec(oldSpec, newSpec *MultiNicNetworkSpec) field.ErrorList {
+	oldIPAMConfig, err := parseIPAM(oldSpec.IPAM)
+	if err != nil || oldIPAMConfig == nil {
+		// no ippool is managed for the old spec
+		return nil
+	}
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	errs = append(errs, apivalidation.ValidateImmutableField(newSpec.Subnet, oldSpec.Subnet, specPath.Child("subnet"))...)
+	// routes of L3 mode are applied to the tables of rails
+	errs = append(errs, apivalidation.ValidateImmutableField(newSpec.RailOptimized, oldSpec.RailOptimized, specPath.Child("railOptimized"))...)
+	newIPAMConfig, err := parseIPAM(newSpec.IPAM)
+	if err != nil {
+		// reported by validateSpec
+		return errs
+	}
+	ipamPath := specPath.Child("ipam")
+	if newIPAMConfig == nil {
+		return append(errs, field.Forbidden(ipamPath.Key("type"), "cannot change type from "+MultiNICIPAMType))
+	}
+	errs = append(errs, apivalidation.ValidateImmutableField(newIPAMConfig.HostBlock, oldIPAMConfig.HostBlock, ipamPath.Key("hostBlock"))...)
+	errs = append(errs, apivalidation.ValidateImmutableField(newIPAMConfig.InterfaceBlock, oldIPAMConfig.InterfaceBlock, ipamPath.Key("interfaceBlock"))...)
+	return errs
+}
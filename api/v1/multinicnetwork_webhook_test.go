@@ -0,0 +1,196 @@
+package v1_test
+
+import (
+	"context"
+
+	. "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const validIPAM = `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "vlanMode": "l3"}`
+
+func newMultiNicNetwork(subnet, ipam, pluginType, strategy string) *MultiNicNetwork {
+	return &MultiNicNetwork{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-network"},
+		Spec: MultiNicNetworkSpec{
+			Subnet: subnet,
+			IPAM:   ipam,
+			MainPlugin: PluginSpec{
+				CNIVersion: "0.3.0",
+				Type:       pluginType,
+			},
+			Policy: AttachmentPolicy{Strategy: strategy},
+		},
+	}
+}
+
+var _ = Describe("MultiNicNetwork Webhook", func() {
+	ctx := context.Background()
+	defaulter := &MultiNicNetworkCustomDefaulter{}
+	validator := &MultiNicNetworkCustomValidator{}
+
+	Context("Defaulting", func() {
+		It("fills cniVersion, strategy and vlanMode", func() {
+			multinicnetwork := newMultiNicNetwork("192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2}`, "ipvlan", "")
+			multinicnetwork.Spec.MainPlugin.CNIVersion = ""
+			Expect(defaulter.Default(ctx, multinicnetwork)).To(Succeed())
+			Expect(multinicnetwork.Spec.MainPlugin.CNIVersion).To(Equal(DefaultCNIVersion))
+			Expect(multinicnetwork.Spec.Policy.Strategy).To(Equal(DefaultStrategy))
+			Expect(multinicnetwork.Spec.IPAM).To(MatchJSON(`{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "vlanMode": "l2"}`))
+		})
+
+		It("keeps specified values", func() {
+			multinicnetwork := newMultiNicNetwork("192.168.0.0/16", validIPAM, "ipvlan", "costOpt")
+			multinicnetwork.Spec.MainPlugin.CNIVersion = "1.0.0"
+			Expect(defaulter.Default(ctx, multinicnetwork)).To(Succeed())
+			Expect(multinicnetwork.Spec.MainPlugin.CNIVersion).To(Equal("1.0.0"))
+			Expect(multinicnetwork.Spec.Policy.Strategy).To(Equal("costOpt"))
+			Expect(multinicnetwork.Spec.IPAM).To(Equal(validIPAM))
+		})
+
+		It("keeps non multi-nic-ipam and unparsable ipam", func() {
+			for _, ipam := range []string{`{"type": "whereabouts", "range": "10.0.0.0/24"}`, `{"type": `} {
+				multinicnetwork := newMultiNicNetwork("", ipam, "ipvlan", "")
+				Expect(defaulter.Default(ctx, multinicnetwork)).To(Succeed())
+				Expect(multinicnetwork.Spec.IPAM).To(Equal(ipam))
+			}
+		})
+	})
+
+	DescribeTable("Validating create", func(subnet, ipam, pluginType, strategy string, expectedField string) {
+		multinicnetwork := newMultiNicNetwork(subnet, ipam, pluginType, strategy)
+		_, err := validator.ValidateCreate(ctx, multinicnetwork)
+		if expectedField == "" {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(expectedField))
+	},
+		Entry("valid ipv4", "192.168.0.0/16", validIPAM, "ipvlan", "none", ""),
+		Entry("valid dual-stack", "192.168.0.0/16,fd00::/48", validIPAM, "macvlan", "topology", ""),
+		Entry("valid non multi-nic-ipam without subnet", "", `{"type": "whereabouts"}`, "sriov", "", ""),
+		Entry("invalid subnet", "192.168.0.0/33", validIPAM, "ipvlan", "none", "spec.subnet"),
+		Entry("missing subnet", "", validIPAM, "ipvlan", "none", "spec.subnet"),
+		Entry("duplicate family", "192.168.0.0/16,10.0.0.0/16", validIPAM, "ipvlan", "none", "spec.subnet"),
+		Entry("blocks not fit", "192.168.0.0/24", validIPAM, "ipvlan", "none", "spec.ipam"),
+		Entry("negative block", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": -1, "interfaceBlock": 2}`, "ipvlan", "none", "spec.ipam[hostBlock]"),
+		Entry("unparsable ipam", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": "8"}`, "ipvlan", "none", "spec.ipam"),
+		Entry("invalid exclude", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "excludeCIDRs": ["192.168.0.1"]}`, "ipvlan", "none", "spec.ipam[excludeCIDRs][0]"),
+		Entry("unknown vlanMode", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "vlanMode": "l4"}`, "ipvlan", "none", "spec.ipam[vlanMode]"),
+		Entry("valid allocationStrategy", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "allocationStrategy": "lru", "quarantineSeconds": 60}`, "ipvlan", "none", ""),
+		Entry("unknown allocationStrategy", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "allocationStrategy": "first"}`, "ipvlan", "none", "spec.ipam[allocationStrategy]"),
+		Entry("negative quarantineSeconds", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "quarantineSeconds": -1}`, "ipvlan", "none", "spec.ipam[quarantineSeconds]"),
+		Entry("valid rule", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "rule": {"priority": 1000, "fwmark": "0x10/0xff", "iif": "lo"}}`, "ipvlan", "none", ""),
+		Entry("rule after main table", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "rule": {"priority": 32766}}`, "ipvlan", "none", "spec.ipam[rule][priority]"),
+		Entry("invalid rule fwmark", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "rule": {"fwmark": "0x10/mask"}}`, "ipvlan", "none", "spec.ipam[rule][fwmark]"),
+		Entry("unknown plugin", "192.168.0.0/16", validIPAM, "bridge", "none", "spec.plugin.type"),
+		Entry("missing plugin", "192.168.0.0/16", validIPAM, "", "none", "spec.plugin.type"),
+		Entry("unknown strategy", "192.168.0.0/16", validIPAM, "ipvlan", "fastest", "spec.attachPolicy.strategy"),
+	)
+
+	DescribeTable("Validating placement", func(placement string, expectedField string) {
+		multinicnetwork := newMultiNicNetwork("192.168.0.0/16", validIPAM, "ipvlan", "costOpt")
+		multinicnetwork.Spec.Policy.Placement = placement
+		_, err := validator.ValidateCreate(ctx, multinicnetwork)
+		if expectedField == "" {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(expectedField))
+	},
+		Entry("default", "", ""),
+		Entry("balance", "balance", ""),
+		Entry("pack", "pack", ""),
+		Entry("unknown", "spread", "spec.attachPolicy.placement"),
+	)
+
+	DescribeTable("Validating onUnhealthy", func(onUnhealthy string, expectedField string) {
+		multinicnetwork := newMultiNicNetwork("192.168.0.0/16", validIPAM, "ipvlan", "none")
+		multinicnetwork.Spec.Policy.OnUnhealthy = onUnhealthy
+		_, err := validator.ValidateCreate(ctx, multinicnetwork)
+		if expectedField == "" {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(expectedField))
+	},
+		Entry("default", "", ""),
+		Entry("degrade", "degrade", ""),
+		Entry("fail", "fail", ""),
+		Entry("unknown", "ignore", "spec.attachPolicy.onUnhealthy"),
+	)
+
+	DescribeTable("Validating pipeline", func(strategy string, pipeline []SelectionStage, expectedField string) {
+		multinicnetwork := newMultiNicNetwork("192.168.0.0/16", validIPAM, "ipvlan", strategy)
+		multinicnetwork.Spec.Policy.Pipeline = pipeline
+		_, err := validator.ValidateCreate(ctx, multinicnetwork)
+		if expectedField == "" {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(expectedField))
+	},
+		Entry("valid", "none", []SelectionStage{{Type: "devClass", Class: "cx7"}, {Type: "topology"}, {Type: "perfOpt"}}, ""),
+		Entry("names", "", []SelectionStage{{Type: "names", Names: []string{"eth1"}}, {Type: "costOpt", Placement: "pack"}}, ""),
+		Entry("with strategy", "topology", []SelectionStage{{Type: "linkUp"}}, "spec.attachPolicy.strategy"),
+		Entry("unknown stage", "none", []SelectionStage{{Type: "fastest"}}, "spec.attachPolicy.pipeline[0].type"),
+		Entry("names without names", "none", []SelectionStage{{Type: "linkUp"}, {Type: "names"}}, "spec.attachPolicy.pipeline[1].names"),
+		Entry("unknown placement", "none", []SelectionStage{{Type: "costOpt", Placement: "spread"}}, "spec.attachPolicy.pipeline[0].placement"),
+	)
+
+	DescribeTable("Validating railOptimized", func(masterNets []string, expectedField string) {
+		multinicnetwork := newMultiNicNetwork("192.168.0.0/16", validIPAM, "ipvlan", "none")
+		multinicnetwork.Spec.MasterNetAddrs = masterNets
+		multinicnetwork.Spec.RailOptimized = true
+		_, err := validator.ValidateCreate(ctx, multinicnetwork)
+		if expectedField == "" {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(expectedField))
+	},
+		Entry("rails", []string{"10.0.0.0/24", "10.0.1.0/24"}, ""),
+		Entry("no masterNets", nil, "spec.masterNets"),
+	)
+
+	It("rejects changing railOptimized", func() {
+		oldNetwork := newMultiNicNetwork("192.168.0.0/16", validIPAM, "ipvlan", "none")
+		oldNetwork.Spec.MasterNetAddrs = []string{"10.0.0.0/24"}
+		multinicnetwork := oldNetwork.DeepCopy()
+		multinicnetwork.Spec.RailOptimized = true
+		_, err := validator.ValidateUpdate(ctx, oldNetwork, multinicnetwork)
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring("spec.railOptimized"))
+	})
+
+	DescribeTable("Validating update", func(newSubnet, newIPAM string, deleting bool, expectedField string) {
+		oldNetwork := newMultiNicNetwork("192.168.0.0/16", validIPAM, "ipvlan", "none")
+		multinicnetwork := newMultiNicNetwork(newSubnet, newIPAM, "ipvlan", "none")
+		if deleting {
+			now := metav1.Now()
+			multinicnetwork.SetDeletionTimestamp(&now)
+		}
+		_, err := validator.ValidateUpdate(ctx, oldNetwork, multinicnetwork)
+		if expectedField == "" {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		Expect(err.Error()).To(ContainSubstring(expectedField))
+	},
+		Entry("unchanged", "192.168.0.0/16", validIPAM, false, ""),
+		Entry("change vlanMode", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 8, "interfaceBlock": 2, "vlanMode": "l2"}`, false, ""),
+		Entry("change subnet", "10.0.0.0/16", validIPAM, false, "spec.subnet"),
+		Entry("change hostBlock", "192.168.0.0/16", `{"type": "multi-nic-ipam", "hostBlock": 6, "interfaceBlock": 2, "vlanMode": "l3"}`, false, "spec.ipam[hostBlock]"),
+		Entry("change ipam type", "192.168.0.0/16", `{"type": "whereabouts"}`, false, "spec.ipam[type]"),
+		Entry("change subnet while deleting", "10.0.0.0/16", validIPAM, true, ""),
+	)
+})
@@ -11,6 +11,7 @@ package v1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -32,6 +33,13 @@ func (in *Allocation) DeepCopy() *Allocation {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AttachmentPolicy) DeepCopyInto(out *AttachmentPolicy) {
 	*out = *in
+	if in.Pipeline != nil {
+		in, out := &in.Pipeline, &out.Pipeline
+		*out = make([]SelectionStage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttachmentPolicy.
@@ -50,7 +58,7 @@ func (in *CIDR) DeepCopyInto(out *CIDR) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CIDR.
@@ -91,6 +99,31 @@ func (in *CIDREntry) DeepCopy() *CIDREntry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CIDREntryStatus) DeepCopyInto(out *CIDREntryStatus) {
+	*out = *in
+	if in.Rail != nil {
+		in, out := &in.Rail, &out.Rail
+		*out = new(int)
+		**out = **in
+	}
+	if in.Hosts != nil {
+		in, out := &in.Hosts, &out.Hosts
+		*out = make([]HostUtilization, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CIDREntryStatus.
+func (in *CIDREntryStatus) DeepCopy() *CIDREntryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CIDREntryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CIDRList) DeepCopyInto(out *CIDRList) {
 	*out = *in
@@ -149,6 +182,18 @@ func (in *CIDRSpec) DeepCopy() *CIDRSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CIDRStatus) DeepCopyInto(out *CIDRStatus) {
 	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]CIDREntryStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnassignedHosts != nil {
+		in, out := &in.UnassignedHosts, &out.UnassignedHosts
+		*out = make([]UnassignedHost, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CIDRStatus.
@@ -341,7 +386,7 @@ func (in *DeviceClass) DeepCopyInto(out *DeviceClass) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClass.
@@ -394,6 +439,26 @@ func (in *DeviceClassList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeviceClassNodeStatus) DeepCopyInto(out *DeviceClassNodeStatus) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClassNodeStatus.
+func (in *DeviceClassNodeStatus) DeepCopy() *DeviceClassNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DeviceClassNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceClassSpec) DeepCopyInto(out *DeviceClassSpec) {
 	*out = *in
@@ -404,6 +469,21 @@ func (in *DeviceClassSpec) DeepCopyInto(out *DeviceClassSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Drivers != nil {
+		in, out := &in.Drivers, &out.Drivers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NumaNodes != nil {
+		in, out := &in.NumaNodes, &out.NumaNodes
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.SRIOV != nil {
+		in, out := &in.SRIOV, &out.SRIOV
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClassSpec.
@@ -419,6 +499,13 @@ func (in *DeviceClassSpec) DeepCopy() *DeviceClassSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeviceClassStatus) DeepCopyInto(out *DeviceClassStatus) {
 	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]DeviceClassNodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeviceClassStatus.
@@ -472,7 +559,7 @@ func (in *HostInterface) DeepCopyInto(out *HostInterface) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostInterface.
@@ -546,7 +633,9 @@ func (in *HostInterfaceSpec) DeepCopyInto(out *HostInterfaceSpec) {
 	if in.Interfaces != nil {
 		in, out := &in.Interfaces, &out.Interfaces
 		*out = make([]InterfaceInfoType, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -563,6 +652,14 @@ func (in *HostInterfaceSpec) DeepCopy() *HostInterfaceSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HostInterfaceStatus) DeepCopyInto(out *HostInterfaceStatus) {
 	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]InterfaceLinkStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
 	out.Stat = in.Stat
 }
 
@@ -591,13 +688,28 @@ func (in *HostPathMount) DeepCopy() *HostPathMount {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HostUtilization) DeepCopyInto(out *HostUtilization) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HostUtilization.
+func (in *HostUtilization) DeepCopy() *HostUtilization {
+	if in == nil {
+		return nil
+	}
+	out := new(HostUtilization)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPPool) DeepCopyInto(out *IPPool) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPool.
@@ -678,6 +790,17 @@ func (in *IPPoolSpec) DeepCopy() *IPPoolSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IPPoolStatus) DeepCopyInto(out *IPPoolStatus) {
 	*out = *in
+	if in.LastAllocationTime != nil {
+		in, out := &in.LastAllocationTime, &out.LastAllocationTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPPoolStatus.
@@ -690,9 +813,102 @@ func (in *IPPoolStatus) DeepCopy() *IPPoolStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPReservation) DeepCopyInto(out *IPReservation) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPReservation.
+func (in *IPReservation) DeepCopy() *IPReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(IPReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPReservation) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPReservationList) DeepCopyInto(out *IPReservationList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]IPReservation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPReservationList.
+func (in *IPReservationList) DeepCopy() *IPReservationList {
+	if in == nil {
+		return nil
+	}
+	out := new(IPReservationList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *IPReservationList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IPReservationSpec) DeepCopyInto(out *IPReservationSpec) {
+	*out = *in
+	if in.StatefulSet != nil {
+		in, out := &in.StatefulSet, &out.StatefulSet
+		*out = new(StatefulSetReservation)
+		**out = **in
+	}
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]ReservedAddress, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IPReservationSpec.
+func (in *IPReservationSpec) DeepCopy() *IPReservationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(IPReservationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InterfaceInfoType) DeepCopyInto(out *InterfaceInfoType) {
 	*out = *in
+	if in.HostIPs != nil {
+		in, out := &in.HostIPs, &out.HostIPs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NumaNode != nil {
+		in, out := &in.NumaNode, &out.NumaNode
+		*out = new(int)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceInfoType.
@@ -705,6 +921,43 @@ func (in *InterfaceInfoType) DeepCopy() *InterfaceInfoType {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InterfaceLinkStatus) DeepCopyInto(out *InterfaceLinkStatus) {
+	*out = *in
+	if in.NumaNode != nil {
+		in, out := &in.NumaNode, &out.NumaNode
+		*out = new(int)
+		**out = **in
+	}
+	out.Counters = in.Counters
+	out.Rates = in.Rates
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InterfaceLinkStatus.
+func (in *InterfaceLinkStatus) DeepCopy() *InterfaceLinkStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InterfaceLinkStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkCounters) DeepCopyInto(out *LinkCounters) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkCounters.
+func (in *LinkCounters) DeepCopy() *LinkCounters {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkCounters)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *LinkStat) DeepCopyInto(out *LinkStat) {
 	*out = *in
@@ -788,7 +1041,7 @@ func (in *MultiNicNetworkSpec) DeepCopyInto(out *MultiNicNetworkSpec) {
 		copy(*out, *in)
 	}
 	in.MainPlugin.DeepCopyInto(&out.MainPlugin)
-	out.Policy = in.Policy
+	in.Policy.DeepCopyInto(&out.Policy)
 	if in.Namespaces != nil {
 		in, out := &in.Namespaces, &out.Namespaces
 		*out = make([]string, len(*in))
@@ -816,6 +1069,13 @@ func (in *MultiNicNetworkStatus) DeepCopyInto(out *MultiNicNetworkStatus) {
 	}
 	out.DiscoverStatus = in.DiscoverStatus
 	in.LastSyncTime.DeepCopyInto(&out.LastSyncTime)
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MultiNicNetworkStatus.
@@ -856,6 +1116,11 @@ func (in *PluginConfig) DeepCopyInto(out *PluginConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Rule != nil {
+		in, out := &in.Rule, &out.Rule
+		*out = new(PolicyRule)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PluginConfig.
@@ -897,3 +1162,83 @@ func (in *PluginSpec) DeepCopy() *PluginSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PolicyRule) DeepCopyInto(out *PolicyRule) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PolicyRule.
+func (in *PolicyRule) DeepCopy() *PolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReservedAddress) DeepCopyInto(out *ReservedAddress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReservedAddress.
+func (in *ReservedAddress) DeepCopy() *ReservedAddress {
+	if in == nil {
+		return nil
+	}
+	out := new(ReservedAddress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SelectionStage) DeepCopyInto(out *SelectionStage) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SelectionStage.
+func (in *SelectionStage) DeepCopy() *SelectionStage {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectionStage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StatefulSetReservation) DeepCopyInto(out *StatefulSetReservation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StatefulSetReservation.
+func (in *StatefulSetReservation) DeepCopy() *StatefulSetReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(StatefulSetReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnassignedHost) DeepCopyInto(out *UnassignedHost) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnassignedHost.
+func (in *UnassignedHost) DeepCopy() *UnassignedHost {
+	if in == nil {
+		return nil
+	}
+	out := new(UnassignedHost)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,9 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package v2
+
+// Hub marks v2 as the conversion hub of MultiNicNetwork
+func (*MultiNicNetwork) Hub() {}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// MultiNICIPAMType is the IPAM type managed by the operator
+const MultiNICIPAMType = "multi-nic-ipam"
+
+// MultiNicNetworkSpec defines the desired state of MultiNicNetwork
+// MasterNetAddrs is network addresses of NIC members in the pool
+// IPAM is typed ipam specification
+// MainPlugin is plugin specification
+// Policy is general policy of the pool
+// RailOptimized is true if each master network is a rail cabled to the same switch rail on every node (rail i is the i-th of MasterNetAddrs)
+type MultiNicNetworkSpec struct {
+	MasterNetAddrs []string         `json:"masterNets,omitempty"`
+	IPAM           IPAMSpec         `json:"ipam"`
+	MainPlugin     PluginSpec       `json:"plugin"`
+	Policy         AttachmentPolicy `json:"attachPolicy,omitempty"`
+	Namespaces     []string         `json:"namespaces,omitempty"`
+	// +optional
+	RailOptimized bool `json:"railOptimized,omitempty"`
+}
+
+// IPAMSpec defines the IPAM plugin configuration
+// Type is IPAM plugin type, multi-nic-ipam if the pod CIDRs are managed by the operator
+// Subnet is global subnet, default: 172.30.0.0/16
+// HostBlock and InterfaceBlock are the number of bits for host and interface index (multi-nic-ipam)
+// ExcludeCIDRs is list of CIDRs excluded from the allocation (multi-nic-ipam)
+// VlanMode is one of l2, l3, l3s (multi-nic-ipam)
+// Routes is list of routes added to the pod
+// AllocationStrategy is one of sequential, lowestFree, random, lru to select the next address (multi-nic-ipam)
+// QuarantineSeconds is time a released address is not reused by lru strategy (multi-nic-ipam)
+// Rule is priority and selectors of the policy routing rules to the L3 route table (multi-nic-ipam)
+// Args is additional configuration passed as-is to the IPAM plugin of other types
+type IPAMSpec struct {
+	// +kubebuilder:validation:MinLength=1
+	Type   string `json:"type"`
+	Subnet string `json:"subnet,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	HostBlock int `json:"hostBlock,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=128
+	InterfaceBlock int      `json:"interfaceBlock,omitempty"`
+	ExcludeCIDRs   []string `json:"excludeCIDRs,omitempty"`
+	// +kubebuilder:validation:Enum=l2;l3;l3s
+	VlanMode string  `json:"vlanMode,omitempty"`
+	Routes   []Route `json:"routes,omitempty"`
+	// +kubebuilder:validation:Enum=sequential;lowestFree;random;lru
+	AllocationStrategy string `json:"allocationStrategy,omitempty"`
+	// +kubebuilder:validation:Minimum=0
+	QuarantineSeconds int `json:"quarantineSeconds,omitempty"`
+	// +optional
+	Rule *PolicyRule `json:"rule,omitempty"`
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +kubebuilder:validation:Schemaless
+	// +kubebuilder:validation:Type=object
+	Args *runtime.RawExtension `json:"args,omitempty"`
+}
+
+// PolicyRule defines priority and selectors of the policy routing rules to the L3 route table
+// in addition to the source subnet
+// Priority is the rule priority before the main table (32766), assigned by the kernel if not set
+// FwMark selects packets with the firewall mark in the form of mark or mark/mask (e.g., 0x10/0xff)
+// Iif selects packets from the input interface (e.g., lo for packets from the host)
+type PolicyRule struct {
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=32765
+	Priority int `json:"priority,omitempty"`
+	// +kubebuilder:validation:Pattern=`^(0[xX][0-9a-fA-F]+|[0-9]+)(/(0[xX][0-9a-fA-F]+|[0-9]+))?$`
+	FwMark string `json:"fwmark,omitempty"`
+	// +kubebuilder:validation:MaxLength=15
+	Iif string `json:"iif,omitempty"`
+}
+
+// reference: github.com/containernetworking/cni/pkg/types
+type Route struct {
+	// +kubebuilder:validation:MinLength=1
+	Dst string `json:"dst"`
+	GW  string `json:"gw,omitempty"`
+}
+
+// reference: github.com/containernetworking/cni/pkg/types
+type PluginSpec struct {
+	CNIVersion   string            `json:"cniVersion"`
+	Type         string            `json:"type"`
+	Capabilities map[string]bool   `json:"capabilities,omitempty"`
+	DNS          DNS               `json:"dns,omitempty"`
+	CNIArgs      map[string]string `json:"args,omitempty"`
+}
+
+// reference: github.com/containernetworking/cni/pkg/types
+type DNS struct {
+	Nameservers []string `json:"nameservers,omitempty"`
+	Domain      string   `json:"domain,omitempty"`
+	Search      []string `json:"search,omitempty"`
+	Options     []string `json:"options,omitempty"`
+}
+
+// AssignmentPolicy defines the policy to select the NICs from the pool
+// Strategy is one of None, CostOpt, PerfOpt, QoSClass
+// Target is target bandwidth in a format (d+)Gbps, (d+)Mbps, (d+)Kbps
+// required for CostOpt and PerfOpt
+// Placement is balance (default) to spread pods over NICs or pack to fill as few NICs as possible (CostOpt)
+// Pipeline is ordered filter and score stages evaluated instead of a single Strategy
+// OnUnhealthy is degrade (default) to attach fewer NICs or fail to fail the pod when NICs with link down or flapping are excluded
+type AttachmentPolicy struct {
+	Strategy string `json:"strategy"`
+	Target   string `json:"target,omitempty"`
+	// +kubebuilder:validation:Enum=balance;pack
+	Placement string `json:"placement,omitempty"`
+	// +optional
+	Pipeline []SelectionStage `json:"pipeline,omitempty"`
+	// +kubebuilder:validation:Enum=degrade;fail
+	OnUnhealthy string `json:"onUnhealthy,omitempty"`
+}
+
+// SelectionStage is a stage of NIC selection pipeline
+// filter stages (devClass, linkUp, names) remove NICs from the candidates
+// score stages (topology, perfOpt, costOpt) rank the candidates, a later score stage breaks ties of the earlier ones
+// Class is DeviceClass name of devClass stage (default: class in pod annotation)
+// Names is interface names kept by names stage
+// Placement is balance or pack of costOpt stage (default: Placement of the policy)
+type SelectionStage struct {
+	// +kubebuilder:validation:Enum=devClass;linkUp;names;topology;perfOpt;costOpt
+	Type  string   `json:"type"`
+	Class string   `json:"class,omitempty"`
+	Names []string `json:"names,omitempty"`
+	// +kubebuilder:validation:Enum=balance;pack
+	Placement string `json:"placement,omitempty"`
+}
+
+// +enum
+type RouteStatus string
+
+// +enum
+type NetConfigStatus string
+
+type NicNetworkResult struct {
+	NetAddress string `json:"netAddress"`
+	NumOfHost  int    `json:"numOfHosts"`
+}
+
+type DiscoverStatus struct {
+	ExistDaemon            int `json:"existDaemon"`
+	InterfaceInfoAvailable int `json:"infoAvailable"`
+	CIDRProcessedHost      int `json:"cidrProcessed"`
+}
+
+// MultiNicNetworkStatus defines the observed state of MultiNicNetwork
+type MultiNicNetworkStatus struct {
+	ComputeResults  []NicNetworkResult `json:"computeResults"`
+	DiscoverStatus  `json:"discovery"`
+	NetConfigStatus `json:"configStatus"`
+	RouteStatus     `json:"routeStatus"`
+	Message         string      `json:"message"`
+	LastSyncTime    metav1.Time `json:"lastSyncTime"`
+	// Conditions represent the latest observations of the network state
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+//+kubebuilder:resource:scope=Cluster
+//+kubebuilder:storageversion
+
+// MultiNicNetwork is the Schema for the multinicnetworks API
+type MultiNicNetwork struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MultiNicNetworkSpec   `json:"spec,omitempty"`
+	Status MultiNicNetworkStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// MultiNicNetworkList contains a list of MultiNicNetwork
+type MultiNicNetworkList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MultiNicNetwork `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&MultiNicNetwork{}, &MultiNicNetworkList{})
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	"github.com/foundation-model-stack/multi-nic-cni/internal/multinicctl"
+)
+
+var (
+	scheme = runtime.NewScheme()
+
+	kubeconfig  string
+	kubecontext string
+	output      string
+)
+
+func init() {
+	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
+	utilruntime.Must(multinicv1.AddToScheme(scheme))
+}
+
+// loadInventory connects to the cluster and lists multi-nic resources
+func loadInventory(ctx context.Context, withPods bool) (*multinicctl.Inventory, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: kubecontext}
+	config, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("cannot load kubeconfig: %v", err)
+	}
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, fmt.Errorf("cannot create client: %v", err)
+	}
+	return multinicctl.LoadInventory(ctx, c, withPods)
+}
+
+// newViewCommand returns a command which loads inventory and prints the view built from args
+func newViewCommand(use, short string, args cobra.PositionalArgs, withPods bool,
+	view func(inv *multinicctl.Inventory, args []string) (multinicctl.TablePrinter, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   use,
+		Short: short,
+		Args:  args,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inv, err := loadInventory(cmd.Context(), withPods)
+			if err != nil {
+				return err
+			}
+			result, err := view(inv, args)
+			if err != nil {
+				return err
+			}
+			return multinicctl.Print(cmd.OutOrStdout(), output, result)
+		},
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:           "kubectl-multinic",
+		Short:         "Inspect multi-nic networks, IP pools and allocations",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+	}
+	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "", "path to the kubeconfig file")
+	rootCmd.PersistentFlags().StringVar(&kubecontext, "context", "", "name of the kubeconfig context to use")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", multinicctl.OutputTable, "output format: table, json or yaml")
+
+	rootCmd.AddCommand(
+		newViewCommand("networks", "List MultiNicNetworks with host and address usage", cobra.NoArgs, false,
+			func(inv *multinicctl.Inventory, args []string) (multinicctl.TablePrinter, error) {
+				return inv.NetworkView(), nil
+			}),
+		newViewCommand("pools <network>", "Show host index map, IPPools and allocations of the network", cobra.ExactArgs(1), false,
+			func(inv *multinicctl.Inventory, args []string) (multinicctl.TablePrinter, error) {
+				return inv.PoolView(args[0])
+			}),
+		newViewCommand("lookup <ip>", "Find the IPPool and pod which own the address", cobra.ExactArgs(1), true,
+			func(inv *multinicctl.Inventory, args []string) (multinicctl.TablePrinter, error) {
+				return inv.LookupView(args[0])
+			}),
+		newViewCommand("pod <namespace>/<name>", "Show multi-nic addresses of the pod", cobra.ExactArgs(1), true,
+			func(inv *multinicctl.Inventory, args []string) (multinicctl.TablePrinter, error) {
+				namespace, name := multinicctl.ParsePodName(args[0])
+				return inv.PodView(namespace, name)
+			}),
+		newViewCommand("node <name>", "Show host interfaces and IPPools of the node", cobra.ExactArgs(1), false,
+			func(inv *multinicctl.Inventory, args []string) (multinicctl.TablePrinter, error) {
+				return inv.NodeView(args[0])
+			}),
+		newViewCommand("capacity <network>", "Show per-node address utilization of the network", cobra.ExactArgs(1), false,
+			func(inv *multinicctl.Inventory, args []string) (multinicctl.TablePrinter, error) {
+				return inv.CapacityView(args[0])
+			}),
+	)
+	return rootCmd
+}
+
+func main() {
+	if err := newRootCommand().ExecuteContext(context.Background()); err != nil {
+		fmt.Fprintln(os.Stderr, "Error:", err)
+		os.Exit(1)
+	}
+}
@@ -28,9 +28,14 @@ const (
 type IPRequest struct {
 	PodName          string   `json:"pod"`
 	PodNamespace     string   `json:"namespace"`
+	PodUID           string   `json:"podUID,omitempty"`
+	ContainerID      string   `json:"containerID,omitempty"`
 	HostName         string   `json:"host"`
 	NetAttachDefName string   `json:"def"`
 	InterfaceNames   []string `json:"masters"`
+	// AllocationStrategy and QuarantineSeconds are set from IPAM config of the network
+	AllocationStrategy string `json:"allocationStrategy,omitempty"`
+	QuarantineSeconds  int    `json:"quarantineSeconds,omitempty"`
 }
 
 type IPResponse struct {
@@ -39,8 +44,10 @@ type IPResponse struct {
 	VLANBlockSize string `json:"block"`
 }
 
-func RequestIP(daemonIP string, daemonPort int, podName string, podNamespace string, hostName string, defName string, masters []string) ([]IPResponse, error) {
+func RequestIP(ipamConf *IPAMConfig, podName string, podNamespace string, podUID string, containerID string, hostName string, masters []string) ([]IPResponse, error) {
 	var response []IPResponse
+	daemonIP := ipamConf.DaemonIP
+	daemonPort := ipamConf.DaemonPort
 	if daemonPort == 0 {
 		daemonPort = DEFAULT_DAEMON_PORT
 	}
@@ -49,11 +56,15 @@ func RequestIP(daemonIP string, daemonPort int, podName string, podNamespace str
 	}
 	address := fmt.Sprintf("http://%s:%d/%s", daemonIP, daemonPort, ALLOCATE_PATH)
 	request := IPRequest{
-		PodName:          podName,
-		PodNamespace:     podNamespace,
-		HostName:         hostName,
-		NetAttachDefName: defName,
-		InterfaceNames:   masters,
+		PodName:            podName,
+		PodNamespace:       podNamespace,
+		PodUID:             podUID,
+		ContainerID:        containerID,
+		HostName:           hostName,
+		NetAttachDefName:   ipamConf.Name,
+		InterfaceNames:     masters,
+		AllocationStrategy: ipamConf.AllocationStrategy,
+		QuarantineSeconds:  ipamConf.QuarantineSeconds,
 	}
 
 	jsonReq, err := json.Marshal(request)
@@ -85,7 +96,7 @@ func RequestIP(daemonIP string, daemonPort int, podName string, podNamespace str
 	}
 }
 
-func Deallocate(daemonPort int, podName string, podNamespace string, hostName string, defName string) ([]IPResponse, error) {
+func Deallocate(daemonPort int, podName string, podNamespace string, podUID string, containerID string, hostName string, defName string) ([]IPResponse, error) {
 	var response []IPResponse
 	if daemonPort == 0 {
 		daemonPort = DEFAULT_DAEMON_PORT
@@ -95,6 +106,8 @@ func Deallocate(daemonPort int, podName string, podNamespace string, hostName st
 	request := IPRequest{
 		PodName:          podName,
 		PodNamespace:     podNamespace,
+		PodUID:           podUID,
+		ContainerID:      containerID,
 		HostName:         hostName,
 		NetAttachDefName: defName,
 	}
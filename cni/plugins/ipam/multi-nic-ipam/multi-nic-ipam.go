@@ -49,6 +49,10 @@ type IPAMConfig struct {
 	ExcludeCIDRs   []string       `json:"excludeCIDRs"`
 	Routes         []*types.Route `json:"routes"`
 	DNS            types.DNS      `json:"dns"`
+	// AllocationStrategy is sequential (default), lowestFree, random, or lru
+	AllocationStrategy string `json:"allocationStrategy,omitempty"`
+	// QuarantineSeconds is time a released address is not allocated again by lru strategy
+	QuarantineSeconds int `json:"quarantineSeconds,omitempty"`
 }
 
 func main() {
@@ -99,6 +103,16 @@ func getPodInfo(cniArgs string) (string, string) {
 	return podName, podNamespace
 }
 
+// getPodUID extracts pod UID from cniArgs, empty if the runtime does not pass it
+func getPodUID(cniArgs string) string {
+	for _, split := range strings.Split(cniArgs, ";") {
+		if strings.HasPrefix(split, "K8S_POD_UID=") {
+			return strings.TrimPrefix(split, "K8S_POD_UID=")
+		}
+	}
+	return ""
+}
+
 func LoadIPAMConfig(bytes []byte) (*IPAMConfig, string, error) {
 	n := Net{}
 	if err := json.Unmarshal(bytes, &n); err != nil {
@@ -146,12 +160,24 @@ func cmdCheck(args *skel.CmdArgs) error {
 	}
 
 	if n.Subnet != "" {
-		for _, ips := range result.IPs {
-			_, subnetNet, err := net.ParseCIDR(n.Subnet)
+		// subnet can be a comma-separated dual-stack pair
+		subnetNets := []*net.IPNet{}
+		for _, subnet := range strings.Split(n.Subnet, ",") {
+			_, subnetNet, err := net.ParseCIDR(strings.TrimSpace(subnet))
 			if err != nil {
 				return fmt.Errorf("cannot parse subnet %s", n.Subnet)
 			}
-			if !subnetNet.Contains(ips.Address.IP) {
+			subnetNets = append(subnetNets, subnetNet)
+		}
+		for _, ips := range result.IPs {
+			contained := false
+			for _, subnetNet := range subnetNets {
+				if subnetNet.Contains(ips.Address.IP) {
+					contained = true
+					break
+				}
+			}
+			if !contained {
 				return fmt.Errorf("allocated ip %s is not in designated subnet %s", ips.Address.IP, n.Subnet)
 			}
 		}
@@ -200,8 +226,9 @@ func cmdAdd(args *skel.CmdArgs) error {
 			return fmt.Errorf("failed to get host name")
 		}
 		podName, podNamespace := getPodInfo(args.Args)
-		utils.Logger.Debug(fmt.Sprintf("RequestIP of %s net to %s:%d for %s/%s with %v", ipamConf.Name, ipamConf.DaemonIP, ipamConf.DaemonPort, podNamespace, podName, n.Masters))
-		ipResponses, err := RequestIP(ipamConf.DaemonIP, ipamConf.DaemonPort, podName, podNamespace, hostName, ipamConf.Name, n.Masters)
+		podUID := getPodUID(args.Args)
+		utils.Logger.Debug(fmt.Sprintf("RequestIP of %s net to %s:%d for %s/%s (uid=%s) with %v", ipamConf.Name, ipamConf.DaemonIP, ipamConf.DaemonPort, podNamespace, podName, podUID, n.Masters))
+		ipResponses, err := RequestIP(ipamConf, podName, podNamespace, podUID, args.ContainerID, hostName, n.Masters)
 
 		if err != nil {
 			return fmt.Errorf("failed to request ip %v", err)
@@ -209,20 +236,20 @@ func cmdAdd(args *skel.CmdArgs) error {
 
 		for index, master := range n.Masters {
 			// find match master information and add
+			// (one response of each address family in dual-stack network)
 			for _, ipResponse := range ipResponses {
 				if ipResponse.InterfaceName == master {
 					vlanPodCIDR := fmt.Sprintf("%s/%s", ipResponse.IPAddress, ipResponse.VLANBlockSize)
 					ipVal, reservedIP, err := net.ParseCIDR(vlanPodCIDR)
-					reservedIP.IP = ipVal
 					if err != nil {
 						return fmt.Errorf("failed to parse IP: %s: %v", ipResponse.IPAddress, err)
 					}
+					reservedIP.IP = ipVal
 					ipConf := &current.IPConfig{
 						Address:   *reservedIP,
 						Interface: current.Int(index),
 					}
 					result.IPs = append(result.IPs, ipConf)
-					break
 				}
 			}
 		}
@@ -267,8 +294,9 @@ func cmdDel(args *skel.CmdArgs) error {
 		return fmt.Errorf("failed to get host name")
 	}
 	podName, podNamespace := getPodInfo(args.Args)
-	utils.Logger.Debug(fmt.Sprintf("RequestDeallocateIP of %s/%s in %s net from %s:%d", podNamespace, podName, ipamConf.Name, ipamConf.DaemonIP, ipamConf.DaemonPort))
-	ipResponses, err := Deallocate(ipamConf.DaemonPort, podName, podNamespace, hostName, ipamConf.Name)
+	podUID := getPodUID(args.Args)
+	utils.Logger.Debug(fmt.Sprintf("RequestDeallocateIP of %s/%s (uid=%s) in %s net from %s:%d", podNamespace, podName, podUID, ipamConf.Name, ipamConf.DaemonIP, ipamConf.DaemonPort))
+	ipResponses, err := Deallocate(ipamConf.DaemonPort, podName, podNamespace, podUID, args.ContainerID, hostName, ipamConf.Name)
 	utils.Logger.Debug(fmt.Sprintf("ResponseDeallocateIP: %v", ipResponses))
 
 	for index, master := range n.Masters {
@@ -277,16 +305,15 @@ func cmdDel(args *skel.CmdArgs) error {
 			if ipResponse.InterfaceName == master {
 				vlanPodCIDR := fmt.Sprintf("%s/%s", ipResponse.IPAddress, ipResponse.VLANBlockSize)
 				ipVal, reservedIP, err := net.ParseCIDR(vlanPodCIDR)
-				reservedIP.IP = ipVal
 				if err != nil {
 					return fmt.Errorf("failed to parse IP: %s: %v", ipResponse.IPAddress, err)
 				}
+				reservedIP.IP = ipVal
 				ipConf := &current.IPConfig{
 					Address:   *reservedIP,
 					Interface: current.Int(index),
 				}
 				result.IPs = append(result.IPs, ipConf)
-				break
 			}
 		}
 	}
@@ -53,6 +53,8 @@ type NicArgs struct {
 	InterfaceNames  []string `json:"masters,omitempty"`
 	Target          string   `json:"target,omitempty"`
 	DevClass        string   `json:"class,omitempty"`
+	// Policy overrides attachment policy of the network, passed to the daemon as it is
+	Policy json.RawMessage `json:"policy,omitempty"`
 }
 
 func main() {
@@ -200,8 +202,8 @@ func cmdAdd(args *skel.CmdArgs) error {
 			return nil
 		})
 		result.Interfaces = append(result.Interfaces, interfaceItem)
-		if len(executeResult.IPs) > 0 {
-			ipConf := executeResult.IPs[0]
+		// one IP of each address family in dual-stack network
+		for _, ipConf := range executeResult.IPs {
 			ipConf.Interface = current.Int(index)
 			ips = append(ips, ipConf)
 		}
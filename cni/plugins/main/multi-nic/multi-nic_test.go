@@ -389,7 +389,7 @@ var _ = Describe("Operations", func() {
 					Name: master,
 				}
 				err = netlink.LinkAdd(&netlink.Dummy{
-					linkAttrs,
+					LinkAttrs: linkAttrs,
 				})
 				masterLink, err := netlink.LinkByName(master)
 				Expect(err).NotTo(HaveOccurred())
@@ -109,11 +109,26 @@ func getMultiIPAMConfigBytes(multiNicConfBytes []byte) (map[string][]byte, error
 
 // injectIPAM injects ipam bytes to config
 func injectMultiNicIPAM(singleNicConfBytes, multiNicConfBytes []byte, ipConfigs []*current.IPConfig, ipIndex int) ([]byte, map[string][]*netlink.NexthopInfo) {
-	var ipConfig *current.IPConfig
-	if ipIndex < len(ipConfigs) {
-		ipConfig = ipConfigs[ipIndex]
+	return replaceMultiNicIPAMWithIPs(singleNicConfBytes, multiNicConfBytes, getInterfaceIPConfigs(ipConfigs, ipIndex))
+}
+
+// getInterfaceIPConfigs returns IP configs of the interface index
+// (more than one IP config, one of each address family, in dual-stack network)
+func getInterfaceIPConfigs(ipConfigs []*current.IPConfig, ipIndex int) []*current.IPConfig {
+	interfaceIPConfigs := []*current.IPConfig{}
+	indexed := false
+	for _, ipConfig := range ipConfigs {
+		if ipConfig.Interface != nil {
+			indexed = true
+			if *ipConfig.Interface == ipIndex {
+				interfaceIPConfigs = append(interfaceIPConfigs, ipConfig)
+			}
+		}
+	}
+	if !indexed && ipIndex < len(ipConfigs) {
+		interfaceIPConfigs = append(interfaceIPConfigs, ipConfigs[ipIndex])
 	}
-	return replaceMultiNicIPAM(singleNicConfBytes, multiNicConfBytes, ipConfig)
+	return interfaceIPConfigs
 }
 
 func injectSingleNicIPAM(singleNicConfBytes []byte, multiNicConfBytes []byte) ([]byte, map[string][]*netlink.NexthopInfo) {
@@ -148,17 +163,23 @@ func replaceSingleNicIPAMWithMultiConfig(singleNicConfBytes, multiNicConfBytes,
 }
 
 func replaceMultiNicIPAM(singleNicConfBytes, multiNicConfBytes []byte, ipConfig *current.IPConfig) ([]byte, map[string][]*netlink.NexthopInfo) {
+	ipConfigs := []*current.IPConfig{}
+	if ipConfig != nil {
+		ipConfigs = append(ipConfigs, ipConfig)
+	}
+	return replaceMultiNicIPAMWithIPs(singleNicConfBytes, multiNicConfBytes, ipConfigs)
+}
+
+func replaceMultiNicIPAMWithIPs(singleNicConfBytes, multiNicConfBytes []byte, ipConfigs []*current.IPConfig) ([]byte, map[string][]*netlink.NexthopInfo) {
 	confStr := string(singleNicConfBytes)
 	ipamObject := &IPAMExtract{}
 	singleIPAMObject := make(map[string]interface{})
 	singleIPAMObject["type"] = "static"
-	if ipConfig != nil {
-		singleIPAMObject["addresses"] = []map[string]string{
-			map[string]string{"address": ipConfig.Address.String()},
-		}
-	} else {
-		singleIPAMObject["addresses"] = []map[string]string{}
+	addresses := []map[string]string{}
+	for _, ipConfig := range ipConfigs {
+		addresses = append(addresses, map[string]string{"address": ipConfig.Address.String()})
 	}
+	singleIPAMObject["addresses"] = addresses
 	var multiPathRoutes map[string][]*netlink.NexthopInfo
 	err := json.Unmarshal(multiNicConfBytes, ipamObject)
 	if err == nil {
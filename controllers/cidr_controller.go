@@ -34,6 +34,7 @@ type CIDRReconciler struct {
 //+kubebuilder:rbac:groups=multinic.fms.io,resources=cidrs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=multinic.fms.io,resources=cidrs/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=multinic.fms.io,resources=cidrs/finalizers,verbs=update
+//+kubebuilder:rbac:groups=multinic.fms.io,resources=ipreservations,verbs=get;list;watch
 
 const cidrFinalizer = "finalizers.cidr.multinic.fms.io"
 
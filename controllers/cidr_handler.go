@@ -21,6 +21,7 @@ import (
 	"github.com/foundation-model-stack/multi-nic-cni/internal/compute"
 	"github.com/foundation-model-stack/multi-nic-cni/internal/plugin"
 	"github.com/foundation-model-stack/multi-nic-cni/internal/vars"
+	"k8s.io/apimachinery/pkg/api/equality"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -32,7 +33,13 @@ import (
 	"sync"
 )
 
-const ()
+const (
+	// MAX_SCAN_HOST_BLOCK limits host block to scan for excluded host indexes in CIDR status
+	MAX_SCAN_HOST_BLOCK = 16
+
+	NO_INTERFACE_INDEX_REASON = "no available interface index"
+	NO_HOST_INDEX_REASON      = "no available host index"
+)
 
 // CIDRHandler handles CIDR object
 // - general handling: Get, List, Delete
@@ -274,10 +281,29 @@ func (h *CIDRHandler) GetAllNetAddrs() []string {
 /////////////////////////////////////////////////////////////////////////////////////////////////////////
 
 // NewCIDR returns new CIDR from PluginConfig
+// In dual-stack configuration, entries of each subnet are computed separately.
 func (h *CIDRHandler) NewCIDR(def multinicv1.PluginConfig, namespace string) (multinicv1.CIDRSpec, error) {
 	if def.Subnet == "" {
 		return h.GenerateCIDRFromHostSubnet(def)
 	}
+	entries := []multinicv1.CIDREntry{}
+	for _, subnet := range compute.SplitSubnets(def.Subnet) {
+		subnetEntries, err := h.newSubnetEntries(getSubnetConfig(def, subnet))
+		if err != nil {
+			return multinicv1.CIDRSpec{}, err
+		}
+		entries = append(entries, subnetEntries...)
+	}
+
+	cidrSpec := multinicv1.CIDRSpec{
+		Config: def,
+		CIDRs:  entries,
+	}
+	return cidrSpec, nil
+}
+
+// newSubnetEntries computes VLAN CIDR entries of master networks from single-subnet PluginConfig
+func (h *CIDRHandler) newSubnetEntries(def multinicv1.PluginConfig) ([]multinicv1.CIDREntry, error) {
 	entries := []multinicv1.CIDREntry{}
 	masterIndex := int(0)
 	// maxInterfaceIndex = 2^(interface bits) - 1
@@ -287,6 +313,9 @@ func (h *CIDRHandler) NewCIDR(def multinicv1.PluginConfig, namespace string) (mu
 		vlanCIDR := ""
 		// find available VLAN CIDR
 		for vlanCIDR == "" {
+			if masterIndex > maxInterfaceIndex {
+				return entries, errors.New("wrong request (overflow interface index)")
+			}
 			vlanInByte, err := h.CIDRCompute.ComputeNet(def.Subnet, masterIndex, def.InterfaceBlock)
 			if err != nil {
 				// invalid VLAN value (out of range), find next interface index
@@ -301,9 +330,6 @@ func (h *CIDRHandler) NewCIDR(def multinicv1.PluginConfig, namespace string) (mu
 			}
 			// if tabu, find next interface index
 			masterIndex = masterIndex + 1
-			if masterIndex > maxInterfaceIndex {
-				return multinicv1.CIDRSpec{}, errors.New("wrong request (overflow interface index)")
-			}
 		}
 
 		entry := multinicv1.CIDREntry{
@@ -315,12 +341,40 @@ func (h *CIDRHandler) NewCIDR(def multinicv1.PluginConfig, namespace string) (mu
 		entries = append(entries, entry)
 		masterIndex = masterIndex + 1
 	}
+	return entries, nil
+}
 
-	cidrSpec := multinicv1.CIDRSpec{
-		Config: def,
-		CIDRs:  entries,
+// getSubnetConfig returns a copy of PluginConfig for a single subnet with exclude CIDRs of the same address family
+func getSubnetConfig(def multinicv1.PluginConfig, subnet string) multinicv1.PluginConfig {
+	subnetDef := *def.DeepCopy()
+	subnetDef.Subnet = subnet
+	subnetDef.ExcludeCIDRs = compute.FilterByFamily(def.ExcludeCIDRs, subnet)
+	return subnetDef
+}
+
+// getSubnetEntries returns CIDR entries whose VLAN CIDR has the same address family as the subnet
+func getSubnetEntries(entries []multinicv1.CIDREntry, subnet string) []multinicv1.CIDREntry {
+	subnetEntries := []multinicv1.CIDREntry{}
+	for _, entry := range entries {
+		if compute.IsIPv6CIDR(entry.VlanCIDR) == compute.IsIPv6CIDR(subnet) {
+			subnetEntries = append(subnetEntries, entry)
+		}
 	}
-	return cidrSpec, nil
+	return subnetEntries
+}
+
+// getFamilyHostIP returns host IP of the interface in the same address family as the subnet,
+// or empty if the interface has no host IP of that family
+func getFamilyHostIP(iface multinicv1.InterfaceInfoType, subnet string) string {
+	if subnet == "" || compute.IsIPv6CIDR(iface.HostIP) == compute.IsIPv6CIDR(subnet) {
+		return iface.HostIP
+	}
+	for _, hostIP := range iface.HostIPs {
+		if compute.IsIPv6CIDR(hostIP) == compute.IsIPv6CIDR(subnet) {
+			return hostIP
+		}
+	}
+	return ""
 }
 
 // run through update queue
@@ -368,8 +422,37 @@ func (h *CIDRHandler) NewCIDRWithNewConfig(def multinicv1.PluginConfig, namespac
 }
 
 // UpdateEntries updates CIDR entry from current HostInterfaceCache
+// In dual-stack configuration, entries of each subnet are updated separately.
 func (h *CIDRHandler) UpdateEntries(cidrSpec multinicv1.CIDRSpec, excludes []compute.IPValue, changed bool) (map[string]multinicv1.CIDREntry, bool) {
 	vars.CIDRLog.V(7).Info("UpdateEntries")
+	subnets := compute.SplitSubnets(cidrSpec.Config.Subnet)
+	if len(subnets) < 2 {
+		return h.updateSubnetEntries(cidrSpec, excludes, changed)
+	}
+	entriesMap := make(map[string]multinicv1.CIDREntry)
+	for _, subnet := range subnets {
+		subnetSpec := multinicv1.CIDRSpec{
+			Config: getSubnetConfig(cidrSpec.Config, subnet),
+			CIDRs:  getSubnetEntries(cidrSpec.CIDRs, subnet),
+		}
+		subnetExcludes := []compute.IPValue{}
+		for _, exclude := range excludes {
+			if compute.IsIPv6CIDR(exclude.Address) == compute.IsIPv6CIDR(subnet) {
+				subnetExcludes = append(subnetExcludes, exclude)
+			}
+		}
+		subnetEntriesMap, subnetChanged := h.updateSubnetEntries(subnetSpec, subnetExcludes, changed)
+		changed = changed || subnetChanged
+		for netAddress, entry := range subnetEntriesMap {
+			// network address is shared by entries of both subnets
+			entriesMap[subnet+","+netAddress] = entry
+		}
+	}
+	return entriesMap, changed
+}
+
+// updateSubnetEntries updates CIDR entry of a single subnet from current HostInterfaceCache
+func (h *CIDRHandler) updateSubnetEntries(cidrSpec multinicv1.CIDRSpec, excludes []compute.IPValue, changed bool) (map[string]multinicv1.CIDREntry, bool) {
 	hostInterfaceSnapshot := h.HostInterfaceHandler.ListCache()
 	entries := cidrSpec.CIDRs
 	entriesMap := make(map[string]multinicv1.CIDREntry)
@@ -442,11 +525,21 @@ func (h *CIDRHandler) UpdateEntries(cidrSpec multinicv1.CIDRSpec, excludes []com
 				}
 			}
 			interfaceName := iface.InterfaceName
-			hostIP := iface.HostIP
+			hostIP := getFamilyHostIP(iface, def.Subnet)
 			success, entry := h.getInterfaceEntry(def, entriesMap, interfaceNetAddress)
 			if !success {
 				continue
 			}
+			if hostIP == "" {
+				// no host IP to route pod VLAN of this family via
+				vars.CIDRLog.V(3).Info(fmt.Sprintf("Skip %s of %s, no host IP in family of %s", interfaceName, hostName, def.Subnet))
+				if itemIndex := h.getHostIndex(entry.Hosts, hostName); itemIndex != -1 {
+					entry.Hosts = append(entry.Hosts[0:itemIndex], entry.Hosts[itemIndex+1:]...)
+					entriesMap[interfaceNetAddress] = entry
+					changed = true
+				}
+				continue
+			}
 			vlanCIDR := entry.VlanCIDR
 			existingHosts := entry.Hosts
 
@@ -600,6 +693,9 @@ func (h *CIDRHandler) updateCIDR(cidrSpec multinicv1.CIDRSpec, new bool) (bool,
 
 		// update IPPools
 		h.IPPoolHandler.UpdateIPPools(def.Name, newEntries, excludes)
+		if err = h.SyncCIDRStatus(def.Name, spec); err != nil {
+			vars.CIDRLog.V(3).Info(fmt.Sprintf("Failed to update status of CIDR %s: %v", def.Name, err))
+		}
 		vars.CIDRLog.V(7).Info(fmt.Sprintf("changeCIDR %s Done", def.Name))
 	}
 	h.Mutex.Unlock()
@@ -671,8 +767,14 @@ func (h *CIDRHandler) GetSyncAllocations(ippool multinicv1.IPPoolSpec, allocatio
 				continue
 			}
 			if crAllocations, found := crAllocationMap[defName]; found {
-				if crAllocation, found := crAllocations[syncIP]; found && crAllocation.Pod == allocation.Pod && crAllocation.Namespace == allocation.Namespace {
+				if crAllocation, found := crAllocations[syncIP]; found && crAllocation.Pod == allocation.Pod && crAllocation.Namespace == allocation.Namespace &&
+					(crAllocation.PodUID == "" || crAllocation.PodUID == allocation.PodUID) {
 					// existing item
+					if crAllocation.PodUID == "" && allocation.PodUID != "" {
+						// migrate allocation recorded before pod UID is tracked
+						crAllocation.PodUID = allocation.PodUID
+						changed = true
+					}
 					newAllocations = append(newAllocations, crAllocation)
 					delete(allocationMap[defName], syncIP)
 					delete(crAllocationMap[defName], syncIP)
@@ -733,11 +835,20 @@ func (h *CIDRHandler) SyncIPPoolWithActivePods(cidrMap map[string]multinicv1.CID
 		vars.CIDRLog.V(5).Info(fmt.Sprintf("Cannot getCurrentAllocationMap: %v", err))
 		return outputs
 	}
+	reservationMap, err := h.getReservationMap()
+	if err != nil {
+		vars.CIDRLog.V(5).Info(fmt.Sprintf("Cannot getReservationMap: %v", err))
+		return outputs
+	}
 	vars.CIDRLog.V(5).Info(fmt.Sprintf("allocationMap: %v", allocationMap))
 	vars.CIDRLog.V(5).Info(fmt.Sprintf("crAllocationMap: %v", crAllocationMap))
 	// check all valid ippool cache
 	for ippoolName, ippool := range ippoolSnapshot {
 		changed, newAllocations := h.GetSyncAllocations(ippool, allocationMap, crAllocationMap)
+		if keptAllocations := KeepReservedAllocations(ippool, newAllocations, reservationMap[ippool.NetAttachDefName]); len(keptAllocations) != len(newAllocations) {
+			newAllocations = keptAllocations
+			changed = !sameAllocations(ippool.Allocations, newAllocations)
+		}
 		if changed {
 			err = h.IPPoolHandler.PatchIPPoolAllocations(ippoolName, newAllocations)
 			if err != nil {
@@ -756,6 +867,63 @@ func (h *CIDRHandler) SyncIPPoolWithActivePods(cidrMap map[string]multinicv1.CID
 	return outputs
 }
 
+// KeepReservedAllocations adds back allocations of the IPPool which hold addresses reserved to their pods
+// reserved addresses are not reclaimed even if the owner pod is not running (same as the daemon)
+func KeepReservedAllocations(ippool multinicv1.IPPoolSpec, newAllocations []multinicv1.Allocation, reservations []multinicv1.IPReservation) []multinicv1.Allocation {
+	if len(reservations) == 0 {
+		return newAllocations
+	}
+	kept := make(map[string]bool)
+	for _, allocation := range newAllocations {
+		kept[allocation.Address] = true
+	}
+	for _, allocation := range ippool.Allocations {
+		if !kept[allocation.Address] && multinicv1.IsReservedAllocation(reservations, ippool.InterfaceName, allocation) {
+			vars.CIDRLog.V(5).Info(fmt.Sprintf("Keep reserved allocation %s of %s/%s", allocation.Address, allocation.Namespace, allocation.Pod))
+			newAllocations = append(newAllocations, allocation)
+			kept[allocation.Address] = true
+		}
+	}
+	return newAllocations
+}
+
+// sameAllocations returns true if both lists hold the same addresses for the same pods
+func sameAllocations(allocations, newAllocations []multinicv1.Allocation) bool {
+	if len(allocations) != len(newAllocations) {
+		return false
+	}
+	allocationMap := make(map[string]multinicv1.Allocation)
+	for _, allocation := range allocations {
+		allocationMap[allocation.Address] = allocation
+	}
+	for _, newAllocation := range newAllocations {
+		if allocation, found := allocationMap[newAllocation.Address]; !found || allocation != newAllocation {
+			return false
+		}
+	}
+	return true
+}
+
+// getReservationMap returns mapping of defName->valid reservations
+func (h *CIDRHandler) getReservationMap() (map[string][]multinicv1.IPReservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), vars.ContextTimeout)
+	defer cancel()
+	reservationList := &multinicv1.IPReservationList{}
+	err := h.Client.List(ctx, reservationList)
+	reservationMap := make(map[string][]multinicv1.IPReservation)
+	if err != nil {
+		return reservationMap, err
+	}
+	for _, reservation := range reservationList.Items {
+		if reservation.Spec.Validate() != nil {
+			continue
+		}
+		defName := reservation.Spec.Network
+		reservationMap[defName] = append(reservationMap[defName], reservation)
+	}
+	return reservationMap, nil
+}
+
 // getCurrentAllocationMap returns mapping of deName->allocations
 func (h *CIDRHandler) getCurrentAllocationMap(cidrMap map[string]multinicv1.CIDR) (map[string]map[string]multinicv1.Allocation, error) {
 	selectors := fmt.Sprintf("%s=%s", vars.PodStatusField, vars.PodStatusRunning)
@@ -796,6 +964,7 @@ func (h *CIDRHandler) getCurrentAllocationMap(cidrMap map[string]multinicv1.CIDR
 						allocation := multinicv1.Allocation{
 							Pod:       pod.GetName(),
 							Namespace: pod.GetNamespace(),
+							PodUID:    string(pod.GetUID()),
 							Address:   ip,
 						}
 						allocationMap[defName][ip] = allocation
@@ -1045,13 +1214,166 @@ func (h *CIDRHandler) GetHostAddressesToExclude() []string {
 	snapshot := h.HostInterfaceHandler.ListCache()
 	for _, hif := range snapshot {
 		for _, iface := range hif.Spec.Interfaces {
-			hostIPCIDR := fmt.Sprintf("%s/32", iface.HostIP)
+			hostIPCIDR := compute.HostCIDR(iface.HostIP)
 			excludes = append(excludes, hostIPCIDR)
+			for _, hostIP := range iface.HostIPs {
+				if hostIP != iface.HostIP {
+					excludes = append(excludes, compute.HostCIDR(hostIP))
+				}
+			}
 		}
 	}
 	return excludes
 }
 
+// ComputeCIDRStatus computes host index utilization of each CIDR entry, allocation of each pod CIDR,
+// and host interfaces that cannot get pod CIDR
+func (h *CIDRHandler) ComputeCIDRStatus(cidrSpec multinicv1.CIDRSpec) multinicv1.CIDRStatus {
+	def := cidrSpec.Config
+	status := multinicv1.CIDRStatus{}
+	for _, entry := range cidrSpec.CIDRs {
+		entryStatus := multinicv1.CIDREntryStatus{
+			NetAddress:           entry.NetAddress,
+			VlanCIDR:             entry.VlanCIDR,
+			HostCapacity:         compute.BlockCapacity(def.HostBlock),
+			AssignedHosts:        len(getAssignedHostIndexes(entry.Hosts)),
+			AvailableHostIndexes: h.getAvailableHostIndexes(def, entry),
+		}
+		if def.RailOptimized {
+			if rail := GetRailIndex(def.MasterNetAddrs, entry.NetAddress); rail >= 0 {
+				entryStatus.Rail = &rail
+			}
+		}
+		for _, host := range entry.Hosts {
+			hostStatus := multinicv1.HostUtilization{
+				HostName: host.HostName,
+				PodCIDR:  host.PodCIDR,
+				IPPool:   host.IPPool,
+				Capacity: compute.GetPodCIDRCapacity(host.PodCIDR),
+			}
+			if ippool, err := h.IPPoolHandler.GetCache(host.IPPool); err == nil {
+				hostStatus.Allocated = len(ippool.Allocations)
+			}
+			entryStatus.Hosts = append(entryStatus.Hosts, hostStatus)
+		}
+		status.Entries = append(status.Entries, entryStatus)
+	}
+	// entries are listed from map, sort to keep status stable
+	sort.SliceStable(status.Entries, func(i, j int) bool {
+		if status.Entries[i].NetAddress != status.Entries[j].NetAddress {
+			return status.Entries[i].NetAddress < status.Entries[j].NetAddress
+		}
+		return status.Entries[i].VlanCIDR < status.Entries[j].VlanCIDR
+	})
+	status.UnassignedHosts = h.getUnassignedHosts(cidrSpec)
+	return status
+}
+
+// SyncCIDRStatus updates CIDR status if utilization changes
+func (h *CIDRHandler) SyncCIDRStatus(name string, cidrSpec multinicv1.CIDRSpec) error {
+	instance, err := h.GetCIDR(name)
+	if err != nil {
+		return err
+	}
+	status := h.ComputeCIDRStatus(cidrSpec)
+	if equality.Semantic.DeepEqual(instance.Status, status) {
+		return nil
+	}
+	if len(status.UnassignedHosts) > len(instance.Status.UnassignedHosts) {
+		vars.CIDRLog.V(2).Info(fmt.Sprintf("CIDR %s has %d unassigned host interfaces", name, len(status.UnassignedHosts)))
+	}
+	instance.Status = status
+	ctx, cancel := context.WithTimeout(context.Background(), vars.ContextTimeout)
+	defer cancel()
+	return h.Client.Status().Update(ctx, instance)
+}
+
+// getAssignedHostIndexes returns a set of host indexes assigned in the entry
+func getAssignedHostIndexes(hosts []multinicv1.HostInterfaceInfo) map[int]bool {
+	assigned := make(map[int]bool)
+	for _, host := range hosts {
+		assigned[host.HostIndex] = true
+	}
+	return assigned
+}
+
+// getAvailableHostIndexes counts host indexes of the entry that are neither assigned nor excluded
+// excluded indexes are not checked for a large host block
+func (h *CIDRHandler) getAvailableHostIndexes(def multinicv1.PluginConfig, entry multinicv1.CIDREntry) int {
+	assigned := getAssignedHostIndexes(entry.Hosts)
+	capacity := compute.BlockCapacity(def.HostBlock)
+	excludes := compute.FilterByFamily(def.ExcludeCIDRs, entry.VlanCIDR)
+	if len(excludes) == 0 || def.HostBlock > MAX_SCAN_HOST_BLOCK {
+		return max(capacity-len(assigned), 0)
+	}
+	available := 0
+	for index := 0; index < capacity; index++ {
+		if assigned[index] {
+			continue
+		}
+		if h.CIDRCompute.CheckIfTabuIndex(entry.VlanCIDR, index, def.HostBlock, excludes) {
+			continue
+		}
+		available += 1
+	}
+	return available
+}
+
+// getUnassignedHosts lists host interfaces which have no entry or no host index in the entry
+func (h *CIDRHandler) getUnassignedHosts(cidrSpec multinicv1.CIDRSpec) []multinicv1.UnassignedHost {
+	def := cidrSpec.Config
+	checkInterfaceMap := make(map[string]bool)
+	for _, netAddr := range def.MasterNetAddrs {
+		checkInterfaceMap[netAddr] = true
+	}
+	subnets := compute.SplitSubnets(def.Subnet)
+	if len(subnets) == 0 {
+		// entries generated from host subnet
+		subnets = []string{""}
+	}
+	var unassignedHosts []multinicv1.UnassignedHost
+	hostInterfaceSnapshot := h.HostInterfaceHandler.ListCache()
+	for _, hif := range hostInterfaceSnapshot {
+		hostName := hif.Spec.HostName
+		for _, iface := range hif.Spec.Interfaces {
+			if len(checkInterfaceMap) > 0 && !checkInterfaceMap[iface.NetAddress] {
+				continue
+			}
+			for _, subnet := range subnets {
+				reason := NO_INTERFACE_INDEX_REASON
+				for _, entry := range cidrSpec.CIDRs {
+					if entry.NetAddress != iface.NetAddress {
+						continue
+					}
+					if subnet != "" && compute.IsIPv6CIDR(entry.VlanCIDR) != compute.IsIPv6CIDR(subnet) {
+						continue
+					}
+					reason = NO_HOST_INDEX_REASON
+					if h.getHostIndex(entry.Hosts, hostName) != -1 {
+						reason = ""
+					}
+					break
+				}
+				if reason != "" {
+					unassignedHosts = append(unassignedHosts, multinicv1.UnassignedHost{
+						HostName:      hostName,
+						InterfaceName: iface.InterfaceName,
+						NetAddress:    iface.NetAddress,
+						Reason:        reason,
+					})
+				}
+			}
+		}
+	}
+	sort.SliceStable(unassignedHosts, func(i, j int) bool {
+		if unassignedHosts[i].HostName != unassignedHosts[j].HostName {
+			return unassignedHosts[i].HostName < unassignedHosts[j].HostName
+		}
+		return unassignedHosts[i].InterfaceName < unassignedHosts[j].InterfaceName
+	})
+	return unassignedHosts
+}
+
 // handling CIDRCache
 func (h *CIDRHandler) SetCache(key string, value multinicv1.CIDRSpec) {
 	h.SafeCache.SetCache(key, value)
@@ -90,6 +90,54 @@ var _ = Describe("Test CIDR Handler	", func() {
 				handler.HostInterfaceHandler.SafeCache.UnsetCache(newHostName)
 			})
 
+			It("Compute CIDR status", func() {
+				status := handler.ComputeCIDRStatus(cidr)
+				Expect(status.Entries).To(HaveLen(len(cidr.CIDRs)))
+				hostCapacity := compute.BlockCapacity(cidr.Config.HostBlock)
+				for _, entryStatus := range status.Entries {
+					Expect(entryStatus.HostCapacity).To(Equal(hostCapacity))
+					Expect(entryStatus.AssignedHosts).To(Equal(len(entryStatus.Hosts)))
+					Expect(entryStatus.AvailableHostIndexes).To(Equal(hostCapacity - entryStatus.AssignedHosts))
+					for _, hostStatus := range entryStatus.Hosts {
+						Expect(hostStatus.Capacity).To(Equal(compute.GetPodCIDRCapacity(hostStatus.PodCIDR)))
+					}
+				}
+				Expect(status.UnassignedHosts).To(BeEmpty())
+				By("Add host not processed yet")
+				newHostName := "unassignedHost"
+				newHostIndex := handler.HostInterfaceHandler.SafeCache.GetSize()
+				newHif := GenerateNewHostInterface(newHostName, interfaceNames, networkPrefixes, newHostIndex)
+				handler.HostInterfaceHandler.SetCache(newHostName, newHif)
+				status = handler.ComputeCIDRStatus(cidr)
+				Expect(status.UnassignedHosts).To(HaveLen(len(interfaceNames)))
+				for _, unassignedHost := range status.UnassignedHosts {
+					Expect(unassignedHost.HostName).To(Equal(newHostName))
+					Expect(unassignedHost.Reason).To(Equal(NO_HOST_INDEX_REASON))
+				}
+				By("Clean up")
+				handler.HostInterfaceHandler.SafeCache.UnsetCache(newHostName)
+			})
+
+			It("Compute rail CIDR status", func() {
+				railCIDR := cidr
+				railCIDR.Config.RailOptimized = true
+				railCIDR.Config.MasterNetAddrs = []string{}
+				for _, entry := range cidr.CIDRs {
+					railCIDR.Config.MasterNetAddrs = append([]string{entry.NetAddress}, railCIDR.Config.MasterNetAddrs...)
+				}
+				status := handler.ComputeCIDRStatus(railCIDR)
+				Expect(status.Entries).To(HaveLen(len(cidr.CIDRs)))
+				for _, entryStatus := range status.Entries {
+					Expect(entryStatus.Rail).NotTo(BeNil())
+					Expect(*entryStatus.Rail).To(Equal(GetRailIndex(railCIDR.Config.MasterNetAddrs, entryStatus.NetAddress)))
+				}
+				By("Not rail-optimized")
+				status = handler.ComputeCIDRStatus(cidr)
+				for _, entryStatus := range status.Entries {
+					Expect(entryStatus.Rail).To(BeNil())
+				}
+			})
+
 			It("Empty subnet", func() {
 				emptySubnetMultinicnetwork := GetMultiNicCNINetwork("empty-ipam", cniVersion, cniType, cniArgs)
 				emptySubnetMultinicnetwork.Spec.Subnet = ""
@@ -739,6 +787,8 @@ var _ = Describe("Test CIDR Handler	", func() {
 				Entry("index at bytes[2]", "192.168.0.0/16", "192.168.1.1", true, 257),
 				Entry("index at bytes[1]", "10.0.0.0/8", "10.1.1.1", true, 256*256+256+1),
 				Entry("uncontained address", "192.168.0.0/26", "192.168.1.1", false, 0),
+				Entry("ipv6 index", "fd00:0:0:1::/64", "fd00:0:0:1::1:1", true, 65536+1),
+				Entry("ipv6 uncontained address", "fd00:0:0:1::/64", "fd00:0:0:2::1", false, 0),
 			)
 		})
 	})
@@ -88,6 +88,11 @@ func (r *ConfigReconciler) getDefaultConfigSpec() multinicv1.ConfigSpec {
 		PodCNIPath:  "/var/lib/kubelet/device-plugins",
 		HostCNIPath: "/var/lib/kubelet/device-plugins",
 	}
+	podResourcesMnt := multinicv1.HostPathMount{
+		Name:        "pod-resources",
+		PodCNIPath:  "/var/lib/kubelet/pod-resources",
+		HostCNIPath: "/var/lib/kubelet/pod-resources",
+	}
 	routeMnt := multinicv1.HostPathMount{
 		Name:        "rt-tables",
 		PodCNIPath:  "/opt/rt_tables",
@@ -98,7 +103,12 @@ func (r *ConfigReconciler) getDefaultConfigSpec() multinicv1.ConfigSpec {
 		PodCNIPath:  "/usr/share/hwdata",
 		HostCNIPath: "/usr/share/hwdata",
 	}
-	hostPathMounts := []multinicv1.HostPathMount{binMnt, devPluginMnt, routeMnt, hwDataMnt}
+	journalMnt := multinicv1.HostPathMount{
+		Name:        "allocation-journal",
+		PodCNIPath:  "/var/lib/multi-nic",
+		HostCNIPath: "/var/lib/multi-nic",
+	}
+	hostPathMounts := []multinicv1.HostPathMount{binMnt, devPluginMnt, podResourcesMnt, routeMnt, hwDataMnt, journalMnt}
 	resources := corev1.ResourceRequirements{
 		Requests: corev1.ResourceList{
 			corev1.ResourceCPU:    resource.MustParse("100m"),
@@ -250,6 +260,10 @@ func (r *ConfigReconciler) UpdateConfigBySpec(spec *multinicv1.ConfigSpec) {
 		vars.ConfigLog.Info(fmt.Sprintf("Configure ContextTimeoutMinutes = %d", spec.ContextTimeoutMinutes))
 		vars.ContextTimeout = time.Duration(spec.ContextTimeoutMinutes) * time.Minute
 	}
+	if spec.IPPoolNearlyExhaustedPercent > 0 && spec.IPPoolNearlyExhaustedPercent <= 100 {
+		vars.ConfigLog.Info(fmt.Sprintf("Configure IPPoolNearlyExhaustedPercent = %d", spec.IPPoolNearlyExhaustedPercent))
+		vars.IPPoolNearlyExhaustedPercent = spec.IPPoolNearlyExhaustedPercent
+	}
 	if spec.LogLevel >= 1 && spec.LogLevel <= 127 {
 		if !vars.ConfigLog.V(spec.LogLevel).Enabled() {
 			vars.ConfigLog.Info(fmt.Sprintf("Configure LogLevel = %d", spec.LogLevel))
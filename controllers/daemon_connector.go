@@ -47,6 +47,8 @@ type L3ConfigRequest struct {
 	Subnet string      `json:"subnet"`
 	Routes []HostRoute `json:"routes"`
 	Force  bool        `json:"force"`
+	// Rule defines priority and selectors of the policy routing rules to the route table
+	Rule *multinicv1.PolicyRule `json:"rule,omitempty"`
 }
 
 // HostRoute defines a route
@@ -121,17 +123,17 @@ func (dc DaemonConnector) Join(podAddress string, hifs []multinicv1.InterfaceInf
 }
 
 // AddRoute sends a request to add a new route to specific host
-func (dc DaemonConnector) ApplyL3Config(podAddress string, cidrName string, subnet string, routes []HostRoute, forceDelete bool) (RouteUpdateResponse, error) {
-	return dc.putRouteRequest(podAddress, ADD_ROUTE_PATH, cidrName, subnet, routes, forceDelete)
+func (dc DaemonConnector) ApplyL3Config(podAddress string, cidrName string, subnet string, rule *multinicv1.PolicyRule, routes []HostRoute, forceDelete bool) (RouteUpdateResponse, error) {
+	return dc.putRouteRequest(podAddress, ADD_ROUTE_PATH, cidrName, subnet, rule, routes, forceDelete)
 }
 
 // DeleteRoute sends a request to delete the route from specific host
 func (dc DaemonConnector) DeleteL3Config(podAddress string, cidrName string, subnet string) (RouteUpdateResponse, error) {
-	return dc.putRouteRequest(podAddress, DELETE_ROUTE_PATH, cidrName, subnet, []HostRoute{}, false)
+	return dc.putRouteRequest(podAddress, DELETE_ROUTE_PATH, cidrName, subnet, nil, []HostRoute{}, false)
 }
 
 // putRouteRequest sends a route adding/deleting request to specific host
-func (dc DaemonConnector) putRouteRequest(podAddress string, path string, cidrName string, subnet string, routes []HostRoute, forceDelete bool) (RouteUpdateResponse, error) {
+func (dc DaemonConnector) putRouteRequest(podAddress string, path string, cidrName string, subnet string, rule *multinicv1.PolicyRule, routes []HostRoute, forceDelete bool) (RouteUpdateResponse, error) {
 	address := podAddress + path
 	var response RouteUpdateResponse
 
@@ -140,6 +142,7 @@ func (dc DaemonConnector) putRouteRequest(podAddress string, path string, cidrNa
 		Subnet: subnet,
 		Routes: routes,
 		Force:  forceDelete,
+		Rule:   rule,
 	}
 
 	jsonReq, err := json.Marshal(requestL3Config)
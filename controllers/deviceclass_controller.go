@@ -0,0 +1,122 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	"github.com/foundation-model-stack/multi-nic-cni/internal/vars"
+)
+
+// DeviceClassReconciler reconciles a DeviceClass object
+// - update status with nodes and interfaces matching the class
+// - recompute all classes when HostInterface changes
+type DeviceClassReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups=multinic.fms.io,resources=deviceclasses,verbs=get;list;watch
+//+kubebuilder:rbac:groups=multinic.fms.io,resources=deviceclasses/status,verbs=get;update;patch
+
+func (r *DeviceClassReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	instance := &multinicv1.DeviceClass{}
+	err := r.Client.Get(ctx, req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		vars.DeviceClassLog.V(7).Info(fmt.Sprintf("Cannot get #%v ", err))
+		return ctrl.Result{RequeueAfter: vars.LongReconcileTime}, nil
+	}
+	if instance.GetDeletionTimestamp() != nil {
+		return ctrl.Result{}, nil
+	}
+
+	hifList := &multinicv1.HostInterfaceList{}
+	err = r.Client.List(ctx, hifList)
+	if err != nil {
+		vars.DeviceClassLog.V(4).Info(fmt.Sprintf("Cannot list HostInterface: %v", err))
+		return ctrl.Result{RequeueAfter: vars.LongReconcileTime}, nil
+	}
+	status := ComputeDeviceClassStatus(instance.Spec, hifList.Items)
+	if equality.Semantic.DeepEqual(instance.Status, status) {
+		return ctrl.Result{}, nil
+	}
+	instance.Status = status
+	err = r.Client.Status().Update(ctx, instance)
+	if err != nil {
+		vars.DeviceClassLog.V(4).Info(fmt.Sprintf("Failed to update status of DeviceClass %s: %v", instance.GetName(), err))
+		return ctrl.Result{}, err
+	}
+	vars.DeviceClassLog.V(4).Info(fmt.Sprintf("DeviceClass %s matches %d interfaces on %d nodes", instance.GetName(), status.NumOfInterfaces, status.NumOfNodes))
+	return ctrl.Result{}, nil
+}
+
+// ComputeDeviceClassStatus lists nodes and interfaces which match the device class spec
+func ComputeDeviceClassStatus(spec multinicv1.DeviceClassSpec, hifs []multinicv1.HostInterface) multinicv1.DeviceClassStatus {
+	status := multinicv1.DeviceClassStatus{}
+	if err := spec.Validate(); err != nil {
+		status.Message = err.Error()
+		return status
+	}
+	for _, hif := range hifs {
+		interfaces := []string{}
+		for _, info := range hif.Spec.Interfaces {
+			if matched, _ := spec.Match(info); matched {
+				interfaces = append(interfaces, info.InterfaceName)
+			}
+		}
+		if len(interfaces) == 0 {
+			continue
+		}
+		sort.Strings(interfaces)
+		status.Nodes = append(status.Nodes, multinicv1.DeviceClassNodeStatus{
+			HostName:   hif.Spec.HostName,
+			Interfaces: interfaces,
+		})
+		status.NumOfInterfaces += len(interfaces)
+	}
+	sort.Slice(status.Nodes, func(i, j int) bool {
+		return status.Nodes[i].HostName < status.Nodes[j].HostName
+	})
+	status.NumOfNodes = len(status.Nodes)
+	return status
+}
+
+// requestAllDeviceClasses maps a HostInterface event to all DeviceClasses
+func (r *DeviceClassReconciler) requestAllDeviceClasses(ctx context.Context, _ client.Object) []reconcile.Request {
+	devClassList := &multinicv1.DeviceClassList{}
+	if err := r.Client.List(ctx, devClassList); err != nil {
+		vars.DeviceClassLog.V(4).Info(fmt.Sprintf("Cannot list DeviceClass: %v", err))
+		return nil
+	}
+	requests := make([]reconcile.Request, len(devClassList.Items))
+	for i, devClass := range devClassList.Items {
+		requests[i] = reconcile.Request{NamespacedName: types.NamespacedName{Name: devClass.GetName()}}
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DeviceClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&multinicv1.DeviceClass{}).
+		Watches(&multinicv1.HostInterface{}, handler.EnqueueRequestsFromMapFunc(r.requestAllDeviceClasses)).
+		Complete(r)
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package controllers_test
+
+import (
+	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	"github.com/foundation-model-stack/multi-nic-cni/controllers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func genHostInterface(hostName string, infos ...multinicv1.InterfaceInfoType) multinicv1.HostInterface {
+	return multinicv1.HostInterface{
+		ObjectMeta: metav1.ObjectMeta{Name: hostName},
+		Spec: multinicv1.HostInterfaceSpec{
+			HostName:   hostName,
+			Interfaces: infos,
+		},
+	}
+}
+
+var _ = Describe("DeviceClass Test", func() {
+	fastInfo := genInterfaceInfo("eth2", "10.0.1.0/24")
+	fastInfo.Driver = "mlx5_core"
+	fastInfo.Speed = 200000
+	slowInfo := genInterfaceInfo("eth1", "10.0.0.0/24")
+	slowInfo.Driver = "mlx5_core"
+	slowInfo.Speed = 25000
+	hifs := []multinicv1.HostInterface{
+		genHostInterface("node-b", fastInfo, slowInfo),
+		genHostInterface("node-a", slowInfo, fastInfo),
+		genHostInterface("node-c", slowInfo),
+	}
+
+	It("lists matching nodes and interfaces", func() {
+		status := controllers.ComputeDeviceClassStatus(multinicv1.DeviceClassSpec{Drivers: []string{"mlx5_core"}, MinSpeed: 100000}, hifs)
+		Expect(status.Message).To(BeEmpty())
+		Expect(status.NumOfNodes).To(Equal(2))
+		Expect(status.NumOfInterfaces).To(Equal(2))
+		Expect(status.Nodes).To(Equal([]multinicv1.DeviceClassNodeStatus{
+			{HostName: "node-a", Interfaces: []string{"eth2"}},
+			{HostName: "node-b", Interfaces: []string{"eth2"}},
+		}))
+
+		status = controllers.ComputeDeviceClassStatus(multinicv1.DeviceClassSpec{Drivers: []string{"mlx5_core"}}, hifs)
+		Expect(status.NumOfNodes).To(Equal(3))
+		Expect(status.NumOfInterfaces).To(Equal(5))
+		Expect(status.Nodes[0].Interfaces).To(Equal([]string{"eth1", "eth2"}))
+	})
+
+	It("reports invalid spec", func() {
+		status := controllers.ComputeDeviceClassStatus(multinicv1.DeviceClassSpec{InterfaceName: "eth(1"}, hifs)
+		Expect(status.Message).NotTo(BeEmpty())
+		Expect(status.NumOfNodes).To(Equal(0))
+	})
+})
@@ -106,6 +106,24 @@ var _ = Describe("Host Interface Test", func() {
 		})
 	})
 
+	Context("UpdateNewInterfaces - device properties", func() {
+		It("can detect change", func() {
+			origInfo := genInterfaceInfo("eth1", "10.0.0.0/24")
+			origInfo.Driver = "mlx5_core"
+			newInfo := *origInfo.DeepCopy()
+			newInfo.Speed = 100000
+			_, updated := controllers.UpdateNewInterfaces([]multinicv1.InterfaceInfoType{origInfo}, []multinicv1.InterfaceInfoType{newInfo})
+			Expect(updated).To(BeTrue())
+			numaNode := 0
+			newInfo = *origInfo.DeepCopy()
+			newInfo.NumaNode = &numaNode
+			_, updated = controllers.UpdateNewInterfaces([]multinicv1.InterfaceInfoType{origInfo}, []multinicv1.InterfaceInfoType{newInfo})
+			Expect(updated).To(BeTrue())
+			_, updated = controllers.UpdateNewInterfaces([]multinicv1.InterfaceInfoType{origInfo}, []multinicv1.InterfaceInfoType{*origInfo.DeepCopy()})
+			Expect(updated).To(BeFalse())
+		})
+	})
+
 	Context("unmanaged host", func() {
 		It("can create/delete unmanaged host", func() {
 			ctx := context.Background()
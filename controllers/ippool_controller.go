@@ -71,6 +71,7 @@ func (r *IPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 	}
 
 	// If IPPool is deleted, delete corresponding routes
+	newAllocation := false
 	is_deleted := instance.GetDeletionTimestamp() != nil
 	if is_deleted {
 		if controllerutil.ContainsFinalizer(instance, ippoolFinalizer) {
@@ -95,7 +96,9 @@ func (r *IPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 		return ctrl.Result{}, nil
 	} else {
 		ippoolName := instance.GetName()
+		prevSpec, _ := r.CIDRHandler.IPPoolHandler.GetCache(ippoolName)
 		r.CIDRHandler.IPPoolHandler.SetCache(ippoolName, instance.Spec)
+		newAllocation = hasNewAllocation(prevSpec.Allocations, instance.Spec.Allocations)
 	}
 
 	// Add finalizer to instance
@@ -106,6 +109,12 @@ func (r *IPPoolReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctr
 			return ctrl.Result{}, err
 		}
 	}
+
+	// update address usage
+	err = r.CIDRHandler.IPPoolHandler.UpdateIPPoolStatus(instance, newAllocation)
+	if err != nil {
+		vars.IPPoolLog.V(4).Info(fmt.Sprintf("Failed to update status of IPPool %s: %v", instance.GetName(), err))
+	}
 	return ctrl.Result{}, nil
 }
 
@@ -15,9 +15,12 @@ import (
 	"fmt"
 	"net"
 
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"reflect"
+	"sort"
 
 	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
 	"github.com/foundation-model-stack/multi-nic-cni/internal/compute"
@@ -154,8 +157,15 @@ func (h *IPPoolHandler) extractMatchExcludesFromPodCIDR(excludes []compute.IPVal
 		// split network address of excluded CIDR and CIDR block (bits)
 		excludeIPSplits := strings.Split(excludeCIDR, "/")
 		excludeIPStr := excludeIPSplits[0]
-		// default CIDR block bits
+		if compute.IsIPv6CIDR(excludeIPStr) != compute.IsIPv6CIDR(podCIDR) {
+			// different address family, continue
+			continue
+		}
+		// default CIDR block bits (single address)
 		excludeBlock := int64(32)
+		if compute.IsIPv6CIDR(excludeIPStr) {
+			excludeBlock = int64(128)
+		}
 		if len(excludeIPSplits) >= 2 {
 			// update excludeBlock to defined CIDR block bits
 			// convert block string to number
@@ -179,8 +189,11 @@ func (h *IPPoolHandler) extractMatchExcludesFromPodCIDR(excludes []compute.IPVal
 }
 
 // GetIPPoolName returns IPPool name = <NetworkAttachmentDefinition name> - <Pod CIDR IP> - <Pod CIDR block>
+// colons of IPv6 Pod CIDR IP are replaced by dashes to form a valid object name
 func (h *IPPoolHandler) GetIPPoolName(netAttachDef string, podCIDR string) string {
-	return netAttachDef + "-" + strings.ReplaceAll(podCIDR, "/", "-")
+	podCIDRName := strings.ReplaceAll(podCIDR, "/", "-")
+	podCIDRName = strings.ReplaceAll(podCIDRName, ":", "-")
+	return netAttachDef + "-" + podCIDRName
 }
 
 // checkPoolValidity checks list of allocated IPs that is in exclude CIDRs
@@ -193,8 +206,8 @@ func (h *IPPoolHandler) checkPoolValidity(excludeCIDRs []string, allocations []m
 	for _, allocation := range allocations {
 		for _, cidr := range excludeCIDRs {
 			_, subnet, _ := net.ParseCIDR(cidr)
-			ip, _, _ := net.ParseCIDR(allocation.Address + "/32")
-			if subnet.Contains(ip) {
+			ip, _, _ := net.ParseCIDR(compute.HostCIDR(allocation.Address))
+			if subnet != nil && subnet.Contains(ip) {
 				// allocated IP in exclude list, append to invalid list
 				invalidAllocations = append(invalidAllocations, allocation)
 			}
@@ -226,6 +239,160 @@ func (h *IPPoolHandler) UpdateIPPools(defName string, entries []multinicv1.CIDRE
 	}
 }
 
+// indexRange is an inclusive range of address indexes in pod CIDR
+type indexRange struct {
+	min int
+	max int
+}
+
+// getIndexRanges returns index ranges of the given CIDRs or addresses within pod CIDR in [1, maxIndex]
+func getIndexRanges(podCIDR string, maxIndex int, cidrs []string) []indexRange {
+	ranges := []indexRange{}
+	cidrCompute := compute.CIDRCompute{}
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			cidr = compute.HostCIDR(cidr)
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			continue
+		}
+		contained, startIndex := cidrCompute.GetIndexInRange(podCIDR, ipNet.IP.String())
+		if !contained {
+			continue
+		}
+		ones, bits := ipNet.Mask.Size()
+		endIndex := startIndex + compute.BlockCapacity(bits-ones) - 1
+		if endIndex < startIndex {
+			// capacity overflow
+			endIndex = maxIndex
+		}
+		startIndex = max(startIndex, 1)
+		endIndex = min(endIndex, maxIndex)
+		if startIndex <= endIndex {
+			ranges = append(ranges, indexRange{min: startIndex, max: endIndex})
+		}
+	}
+	return ranges
+}
+
+// mergeIndexRanges sorts and merges overlapping or adjacent index ranges
+func mergeIndexRanges(ranges []indexRange) []indexRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].min < ranges[j].min
+	})
+	merged := []indexRange{}
+	for _, r := range ranges {
+		last := len(merged) - 1
+		if last >= 0 && r.min <= merged[last].max+1 {
+			merged[last].max = max(merged[last].max, r.max)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// countIndexes returns the number of indexes covered by merged ranges
+func countIndexes(ranges []indexRange) int {
+	count := 0
+	for _, r := range ranges {
+		count += r.max - r.min + 1
+	}
+	return count
+}
+
+// ComputeIPPoolStatus computes address usage of the IPPool and sets Exhausted and NearlyExhausted conditions
+// lastAllocationTime is kept from the previous status unless there is a new allocation
+func (h *IPPoolHandler) ComputeIPPoolStatus(instance *multinicv1.IPPool, hasNewAllocation bool) multinicv1.IPPoolStatus {
+	spec := instance.Spec
+	status := *instance.Status.DeepCopy()
+	_, ipNet, err := net.ParseCIDR(spec.PodCIDR)
+	if err != nil {
+		return status
+	}
+	ones, bits := ipNet.Mask.Size()
+	maxIndex := compute.GetPodCIDRCapacity(spec.PodCIDR)
+	excludedRanges := mergeIndexRanges(getIndexRanges(spec.PodCIDR, maxIndex, spec.Excludes))
+	allocatedAddresses := []string{}
+	for _, allocation := range spec.Allocations {
+		allocatedAddresses = append(allocatedAddresses, allocation.Address)
+	}
+	allocatedRanges := getIndexRanges(spec.PodCIDR, maxIndex, allocatedAddresses)
+	usedRanges := mergeIndexRanges(append(allocatedRanges, excludedRanges...))
+
+	status.Total = compute.BlockCapacity(bits - ones)
+	status.Excluded = countIndexes(excludedRanges)
+	status.Usable = maxIndex - status.Excluded
+	status.Allocated = len(spec.Allocations)
+	status.Free = max(maxIndex-countIndexes(usedRanges), 0)
+	// find the largest gap between used ranges in [1, maxIndex]
+	largestFreeRange := 0
+	nextFreeIndex := 1
+	for _, r := range usedRanges {
+		largestFreeRange = max(largestFreeRange, r.min-nextFreeIndex)
+		nextFreeIndex = r.max + 1
+	}
+	status.LargestFreeRange = max(largestFreeRange, maxIndex-nextFreeIndex+1)
+	if hasNewAllocation {
+		now := metav1.Now()
+		status.LastAllocationTime = &now
+	}
+
+	usage := fmt.Sprintf("%d/%d usable addresses allocated", status.Allocated, status.Usable)
+	exhaustedCondition := metav1.Condition{
+		Type:               multinicv1.IPPoolExhausted,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: instance.GetGeneration(),
+		Reason:             "FreeAddressAvailable",
+		Message:            usage,
+	}
+	if status.Free == 0 {
+		exhaustedCondition.Status = metav1.ConditionTrue
+		exhaustedCondition.Reason = "NoFreeAddress"
+	}
+	meta.SetStatusCondition(&status.Conditions, exhaustedCondition)
+	nearlyExhaustedCondition := metav1.Condition{
+		Type:               multinicv1.IPPoolNearlyExhausted,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: instance.GetGeneration(),
+		Reason:             "BelowThreshold",
+		Message:            fmt.Sprintf("%s (threshold: %d%%)", usage, vars.IPPoolNearlyExhaustedPercent),
+	}
+	if status.Allocated*100 >= status.Usable*vars.IPPoolNearlyExhaustedPercent {
+		nearlyExhaustedCondition.Status = metav1.ConditionTrue
+		nearlyExhaustedCondition.Reason = "AboveThreshold"
+	}
+	meta.SetStatusCondition(&status.Conditions, nearlyExhaustedCondition)
+	return status
+}
+
+// hasNewAllocation checks if any address is allocated in the new allocations but not in the previous ones
+func hasNewAllocation(prevAllocations, allocations []multinicv1.Allocation) bool {
+	prevAddresses := make(map[string]bool)
+	for _, allocation := range prevAllocations {
+		prevAddresses[allocation.Address] = true
+	}
+	for _, allocation := range allocations {
+		if !prevAddresses[allocation.Address] {
+			return true
+		}
+	}
+	return false
+}
+
+// UpdateIPPoolStatus updates IPPool status if address usage changes
+func (h *IPPoolHandler) UpdateIPPoolStatus(instance *multinicv1.IPPool, hasNewAllocation bool) error {
+	status := h.ComputeIPPoolStatus(instance, hasNewAllocation)
+	if equality.Semantic.DeepEqual(instance.Status, status) {
+		return nil
+	}
+	instance.Status = status
+	ctx, cancel := context.WithTimeout(context.Background(), vars.ContextTimeout)
+	defer cancel()
+	return h.Client.Status().Update(ctx, instance)
+}
+
 func (h *IPPoolHandler) SetCache(key string, value multinicv1.IPPoolSpec) {
 	h.SafeCache.SetCache(key, value)
 }
@@ -12,6 +12,8 @@ import (
 	"github.com/foundation-model-stack/multi-nic-cni/internal/compute"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	//+kubebuilder:scaffold:imports
 )
 
@@ -172,6 +174,28 @@ var _ = Describe("Unsync IPPool Test", func() {
 		checkSyncAllocation(allocationMap, pendingIndexes, newPodName, expectedChanged)
 	})
 
+	DescribeTable("Pod UID", func(crPodUID string, expectedChanged bool, expectedPodUID string) {
+		allocationMap := genAllocationMap(currentAllocations, newPodName, true)
+		crAllocationMap := genAllocationMap(currentAllocations, newPodName, false)
+		for ip, allocation := range allocationMap[defName] {
+			allocation.PodUID = "uid-2"
+			allocationMap[defName][ip] = allocation
+			crAllocation := crAllocationMap[defName][ip]
+			crAllocation.PodUID = crPodUID
+			crAllocationMap[defName][ip] = crAllocation
+		}
+		ippool := genIPPool(0, map[int]int{0: currentAllocations[0]}, newPodName)
+		ippool.Allocations[0].PodUID = crPodUID
+		changed, newAllocations := MultiNicnetworkReconcilerInstance.CIDRHandler.GetSyncAllocations(ippool, allocationMap, crAllocationMap)
+		Expect(changed).To(Equal(expectedChanged))
+		Expect(newAllocations).To(HaveLen(1))
+		Expect(newAllocations[0].PodUID).To(Equal(expectedPodUID))
+	},
+		Entry("same pod instance", "uid-2", false, "uid-2"),
+		Entry("previous pod instance", "uid-1", true, "uid-2"),
+		Entry("migrate allocation without UID", "", true, "uid-2"),
+	)
+
 	It("Should all clean", func() {
 		emptyIndexes := map[int]int{}
 		allocationMap := genAllocationMap(emptyIndexes, newPodName, true)
@@ -190,6 +214,41 @@ var _ = Describe("Unsync IPPool Test", func() {
 			Expect(len(newAllocations)).To(Equal(0))
 		}
 	})
+
+	It("Keep reserved allocations", func() {
+		emptyIndexes := map[int]int{}
+		allocationMap := genAllocationMap(emptyIndexes, newPodName, true)
+		pendingIndexes := map[int]int{0: 1, 1: 1}
+		crAllocationMap := genAllocationMap(pendingIndexes, deletedPodName, false)
+		// reserve the address to the deleted pod on the first interface only
+		// and the address on the second interface to another pod
+		reservations := []multinicv1.IPReservation{
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "deleted", Namespace: namespace},
+				Spec: multinicv1.IPReservationSpec{Network: defName, PodName: deletedPodName, Addresses: []multinicv1.ReservedAddress{
+					{InterfaceName: interfaceNames[0], Address: genIP(0, 1)},
+					{InterfaceName: interfaceNames[0], Address: genIP(1, 1)},
+				}},
+			},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: namespace},
+				Spec: multinicv1.IPReservationSpec{Network: defName, PodName: newPodName, Addresses: []multinicv1.ReservedAddress{
+					{Address: genIP(1, 1)},
+				}},
+			},
+		}
+		for interfaceIndex := range interfaceNames {
+			ippool := genIPPool(interfaceIndex, map[int]int{interfaceIndex: 1}, deletedPodName)
+			_, newAllocations := MultiNicnetworkReconcilerInstance.CIDRHandler.GetSyncAllocations(ippool, allocationMap, crAllocationMap)
+			Expect(newAllocations).To(BeEmpty())
+			keptAllocations := KeepReservedAllocations(ippool, newAllocations, reservations)
+			if interfaceIndex == 0 {
+				Expect(keptAllocations).To(Equal(ippool.Allocations))
+			} else {
+				Expect(keptAllocations).To(BeEmpty())
+			}
+		}
+	})
 })
 
 var _ = Describe("Common IPPool Test", func() {
@@ -208,6 +267,7 @@ var _ = Describe("Common IPPool Test", func() {
 		Entry("empty exclude", []string{}, []string{address1}, nil),
 		Entry("empty address", testExcludeCIDRs, []string{}, nil),
 		Entry("contains excluded address", testExcludeCIDRs, []string{address1, address2}, []string{address1}),
+		Entry("contains excluded ipv6 address", []string{"fd00:0:0:1::/120"}, []string{"fd00:0:0:1::1", "fd00:0:0:2::1"}, []string{"fd00:0:0:1::1"}),
 	)
 
 	DescribeTable("extractMatchExcludesFromPodCIDR", func(excludeCIDRs []string, podCIDR string, expected []string) {
@@ -218,6 +278,54 @@ var _ = Describe("Common IPPool Test", func() {
 		Entry("subset", []string{"10.0.1.0/24"}, "10.0.0.0/16", []string{"10.0.1.0/24"}),
 		Entry("unrelated", []string{"10.0.1.0/24"}, "10.0.2.0/24", []string{}),
 		Entry("cover", []string{"10.0.1.0/24"}, "10.0.1.128/25", []string{}), // should be handled by interface indexing step
+		Entry("ipv6 subset", []string{"fd00:0:0:1::/120", "fd00:0:0:1::1:1"}, "fd00:0:0:1::/64", []string{"fd00:0:0:1::/120", "fd00:0:0:1::1:1"}),
+		Entry("other family", []string{"10.0.1.0/24"}, "fd00:0:0:1::/64", []string{}),
+	)
+
+	DescribeTable("ComputeIPPoolStatus", func(podCIDR string, excludes []string, allocationAddresses []string,
+		expected multinicv1.IPPoolStatus, exhausted, nearlyExhausted metav1.ConditionStatus) {
+		instance := &multinicv1.IPPool{
+			Spec: multinicv1.IPPoolSpec{
+				PodCIDR:     podCIDR,
+				Excludes:    excludes,
+				Allocations: convertAddressesToAllocations(allocationAddresses),
+			},
+		}
+		status := ippoolHandler.ComputeIPPoolStatus(instance, len(allocationAddresses) > 0)
+		Expect(status.Total).To(Equal(expected.Total))
+		Expect(status.Usable).To(Equal(expected.Usable))
+		Expect(status.Allocated).To(Equal(expected.Allocated))
+		Expect(status.Free).To(Equal(expected.Free))
+		Expect(status.Excluded).To(Equal(expected.Excluded))
+		Expect(status.LargestFreeRange).To(Equal(expected.LargestFreeRange))
+		Expect(status.LastAllocationTime != nil).To(Equal(len(allocationAddresses) > 0))
+		Expect(meta.FindStatusCondition(status.Conditions, multinicv1.IPPoolExhausted).Status).To(Equal(exhausted))
+		Expect(meta.FindStatusCondition(status.Conditions, multinicv1.IPPoolNearlyExhausted).Status).To(Equal(nearlyExhausted))
+	},
+		Entry("empty", "10.0.0.0/28", nil, nil,
+			multinicv1.IPPoolStatus{Total: 16, Usable: 14, Free: 14, LargestFreeRange: 14},
+			metav1.ConditionFalse, metav1.ConditionFalse),
+		Entry("fragmented", "10.0.0.0/28", []string{"10.0.0.0/30"}, []string{"10.0.0.5", "10.0.0.10"},
+			multinicv1.IPPoolStatus{Total: 16, Usable: 11, Allocated: 2, Free: 9, Excluded: 3, LargestFreeRange: 4},
+			metav1.ConditionFalse, metav1.ConditionFalse),
+		Entry("nearly exhausted", "10.0.0.0/28", nil, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5",
+			"10.0.0.6", "10.0.0.7", "10.0.0.8", "10.0.0.9", "10.0.0.10", "10.0.0.11", "10.0.0.12", "10.0.0.13"},
+			multinicv1.IPPoolStatus{Total: 16, Usable: 14, Allocated: 13, Free: 1, LargestFreeRange: 1},
+			metav1.ConditionFalse, metav1.ConditionTrue),
+		Entry("exhausted", "10.0.0.0/28", []string{"10.0.0.8/29"}, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4",
+			"10.0.0.5", "10.0.0.6", "10.0.0.7"},
+			multinicv1.IPPoolStatus{Total: 16, Usable: 7, Allocated: 7, Free: 0, Excluded: 7, LargestFreeRange: 0},
+			metav1.ConditionTrue, metav1.ConditionTrue),
+		Entry("ipv6", "fd00::/120", []string{"fd00::10/124"}, []string{"fd00::1"},
+			multinicv1.IPPoolStatus{Total: 256, Usable: 239, Allocated: 1, Free: 238, Excluded: 16, LargestFreeRange: 224},
+			metav1.ConditionFalse, metav1.ConditionFalse),
+	)
+
+	DescribeTable("GetIPPoolName", func(podCIDR string, expected string) {
+		Expect(ippoolHandler.GetIPPoolName("netname", podCIDR)).To(Equal(expected))
+	},
+		Entry("ipv4", "10.0.1.0/24", "netname-10.0.1.0-24"),
+		Entry("ipv6", "fd00:0:0:1::/64", "netname-fd00-0-0-1---64"),
 	)
 })
 
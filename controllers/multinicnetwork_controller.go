@@ -263,6 +263,7 @@ func (r *MultiNicNetworkReconciler) GetIPAMConfig(instance *multinicv1.MultiNicN
 		ipamConfig.Type = instance.Spec.MainPlugin.Type
 		ipamConfig.Subnet = instance.Spec.Subnet
 		ipamConfig.MasterNetAddrs = instance.Spec.MasterNetAddrs
+		ipamConfig.RailOptimized = instance.Spec.RailOptimized
 		return ipamConfig, nil
 	}
 	vars.NetworkLog.V(3).Info("non-MultiNicIPAM")
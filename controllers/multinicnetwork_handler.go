@@ -14,6 +14,7 @@ import (
 
 	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
 	"github.com/foundation-model-stack/multi-nic-cni/internal/vars"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -111,7 +112,9 @@ func (h *MultiNicNetworkHandler) updateStatus(instance *multinicv1.MultiNicNetwo
 		NetConfigStatus: netConfigStatus,
 		Message:         message,
 		RouteStatus:     status,
+		Conditions:      instance.Status.DeepCopy().Conditions,
 	}
+	SetNetworkConditions(instance, &netStatus)
 
 	if !NetStatusUpdated(instance, netStatus) {
 		vars.NetworkLog.V(2).Info(fmt.Sprintf("No status update %s", instance.Name))
@@ -140,6 +143,9 @@ func NetStatusUpdated(instance *multinicv1.MultiNicNetwork, newStatus multinicv1
 	if len(prevStatus.ComputeResults) != len(newStatus.ComputeResults) {
 		return true
 	}
+	if conditionsUpdated(prevStatus.Conditions, newStatus.Conditions) {
+		return true
+	}
 	prevComputeMap := make(map[string]int)
 	for _, status := range prevStatus.ComputeResults {
 		prevComputeMap[status.NetAddress] = status.NumOfHost
@@ -154,6 +160,98 @@ func NetStatusUpdated(instance *multinicv1.MultiNicNetwork, newStatus multinicv1
 	return false
 }
 
+// conditionsUpdated checks if any condition is added, removed, or changed regardless of transition time
+func conditionsUpdated(prevConditions, newConditions []metav1.Condition) bool {
+	if len(prevConditions) != len(newConditions) {
+		return true
+	}
+	for _, newCondition := range newConditions {
+		prevCondition := meta.FindStatusCondition(prevConditions, newCondition.Type)
+		if prevCondition == nil || prevCondition.Status != newCondition.Status || prevCondition.Reason != newCondition.Reason ||
+			prevCondition.Message != newCondition.Message || prevCondition.ObservedGeneration != newCondition.ObservedGeneration {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNetworkConditions derives standard conditions from config, discovery, and route status
+func SetNetworkConditions(instance *multinicv1.MultiNicNetwork, status *multinicv1.MultiNicNetworkStatus) {
+	generation := instance.GetGeneration()
+	setCondition := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	// NetAttachDefReady
+	switch status.NetConfigStatus {
+	case multinicv1.ConfigFailed:
+		setCondition(multinicv1.NetAttachDefReady, metav1.ConditionFalse, "ConfigFailed", status.Message)
+	case multinicv1.WaitForConfig, multinicv1.ConfigComplete:
+		setCondition(multinicv1.NetAttachDefReady, metav1.ConditionTrue, "NetAttachDefCreated", "")
+	default:
+		setCondition(multinicv1.NetAttachDefReady, metav1.ConditionUnknown, "Pending", "")
+	}
+
+	// CIDRComputed
+	discovery := status.DiscoverStatus
+	isMultiNICIPAM, _ := IsMultiNICIPAM(instance)
+	hostMessage := fmt.Sprintf("%d/%d hosts processed", discovery.CIDRProcessedHost, discovery.InterfaceInfoAvailable)
+	if !isMultiNICIPAM {
+		setCondition(multinicv1.CIDRComputed, metav1.ConditionTrue, "NotMultiNICIPAM", "CIDR is not managed by "+vars.MultiNICIPAMType)
+	} else if len(status.ComputeResults) == 0 {
+		setCondition(multinicv1.CIDRComputed, metav1.ConditionFalse, "NoComputeResult", hostMessage)
+	} else if discovery.CIDRProcessedHost < discovery.InterfaceInfoAvailable {
+		setCondition(multinicv1.CIDRComputed, metav1.ConditionFalse, "WaitForHosts", hostMessage)
+	} else {
+		setCondition(multinicv1.CIDRComputed, metav1.ConditionTrue, "HostsProcessed", hostMessage)
+	}
+
+	// RoutesApplied
+	routeMessage := RouteMessage[status.RouteStatus]
+	switch status.RouteStatus {
+	case multinicv1.AllRouteApplied:
+		setCondition(multinicv1.RoutesApplied, metav1.ConditionTrue, "AllRoutesApplied", routeMessage)
+	case multinicv1.RouteNoApplied:
+		setCondition(multinicv1.RoutesApplied, metav1.ConditionTrue, "RouteNotRequired", "no L3 configuration applied")
+	case multinicv1.ApplyingRoute:
+		setCondition(multinicv1.RoutesApplied, metav1.ConditionFalse, "ApplyingRoutes", routeMessage)
+	case multinicv1.SomeRouteFailed:
+		setCondition(multinicv1.RoutesApplied, metav1.ConditionFalse, "SomeRouteFailed", routeMessage)
+	case multinicv1.RouteUnknown:
+		setCondition(multinicv1.RoutesApplied, metav1.ConditionUnknown, "DaemonUnreachable", routeMessage)
+	default:
+		setCondition(multinicv1.RoutesApplied, metav1.ConditionUnknown, "Pending", "")
+	}
+
+	// DaemonsDiscovered
+	daemonMessage := fmt.Sprintf("%d/%d daemons reported interfaces", discovery.InterfaceInfoAvailable, discovery.ExistDaemon)
+	if discovery.ExistDaemon == 0 {
+		setCondition(multinicv1.DaemonsDiscovered, metav1.ConditionFalse, "NoDaemon", daemonMessage)
+	} else if discovery.InterfaceInfoAvailable < discovery.ExistDaemon {
+		setCondition(multinicv1.DaemonsDiscovered, metav1.ConditionFalse, "WaitForInterfaceInfo", daemonMessage)
+	} else {
+		setCondition(multinicv1.DaemonsDiscovered, metav1.ConditionTrue, "AllDaemonsReported", daemonMessage)
+	}
+
+	// Degraded
+	switch {
+	case status.NetConfigStatus == multinicv1.ConfigFailed:
+		setCondition(multinicv1.Degraded, metav1.ConditionTrue, "ConfigFailed", status.Message)
+	case status.RouteStatus == multinicv1.SomeRouteFailed:
+		setCondition(multinicv1.Degraded, metav1.ConditionTrue, "SomeRouteFailed", routeMessage)
+	case status.RouteStatus == multinicv1.RouteUnknown:
+		setCondition(multinicv1.Degraded, metav1.ConditionTrue, "DaemonUnreachable", routeMessage)
+	default:
+		setCondition(multinicv1.Degraded, metav1.ConditionFalse, "AsExpected", "")
+	}
+}
+
 func (h *MultiNicNetworkHandler) UpdateNetConfigStatus(instance *multinicv1.MultiNicNetwork, netConfigStatus multinicv1.NetConfigStatus, message string) error {
 	if message != "" {
 		instance.Status.Message = message
@@ -166,6 +264,7 @@ func (h *MultiNicNetworkHandler) UpdateNetConfigStatus(instance *multinicv1.Mult
 	if instance.Status.LastSyncTime == emptyTime {
 		instance.Status.LastSyncTime = metav1.Now()
 	}
+	SetNetworkConditions(instance, &instance.Status)
 	ctx, cancel := context.WithTimeout(context.Background(), vars.ContextTimeout)
 	defer cancel()
 	err := h.Client.Status().Update(ctx, instance)
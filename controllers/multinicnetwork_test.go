@@ -15,6 +15,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	client "sigs.k8s.io/controller-runtime/pkg/client"
 	//+kubebuilder:scaffold:imports
@@ -239,4 +240,55 @@ var _ = Describe("Test multinicnetwork status change check", func() {
 		expectedChange = true
 		testNewNetStatus(multinicnetwork, newStatus, expectedChange)
 	})
+
+	It("set conditions from status", func() {
+		multinicnetwork := GetMultiNicCNINetwork("test-mn", cniVersion, cniType, cniArgs)
+		multinicnetwork.Generation = 2
+		computeResults := []multinicv1.NicNetworkResult{{NetAddress: "192.168.0.0/24", NumOfHost: 1}}
+		discoverStatus := multinicv1.DiscoverStatus{
+			ExistDaemon:            2,
+			InterfaceInfoAvailable: 2,
+			CIDRProcessedHost:      2,
+		}
+		status := getNetStatus(computeResults, discoverStatus, multinicv1.ConfigComplete, multinicv1.AllRouteApplied)
+		SetNetworkConditions(multinicnetwork, &status)
+		expectedConditions := map[string]metav1.ConditionStatus{
+			multinicv1.NetAttachDefReady: metav1.ConditionTrue,
+			multinicv1.CIDRComputed:      metav1.ConditionTrue,
+			multinicv1.RoutesApplied:     metav1.ConditionTrue,
+			multinicv1.DaemonsDiscovered: metav1.ConditionTrue,
+			multinicv1.Degraded:          metav1.ConditionFalse,
+		}
+		Expect(status.Conditions).To(HaveLen(len(expectedConditions)))
+		for conditionType, conditionStatus := range expectedConditions {
+			condition := meta.FindStatusCondition(status.Conditions, conditionType)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(conditionStatus))
+			Expect(condition.ObservedGeneration).To(Equal(int64(2)))
+		}
+		multinicnetwork.Status = status
+
+		// route failure on some host
+		newStatus := *status.DeepCopy()
+		newStatus.RouteStatus = multinicv1.SomeRouteFailed
+		SetNetworkConditions(multinicnetwork, &newStatus)
+		Expect(meta.IsStatusConditionFalse(newStatus.Conditions, multinicv1.RoutesApplied)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(newStatus.Conditions, multinicv1.Degraded)).To(BeTrue())
+		Expect(NetStatusUpdated(multinicnetwork, newStatus)).To(BeTrue())
+
+		// daemon not yet reported
+		newStatus = *status.DeepCopy()
+		newStatus.DiscoverStatus.ExistDaemon = 3
+		SetNetworkConditions(multinicnetwork, &newStatus)
+		condition := meta.FindStatusCondition(newStatus.Conditions, multinicv1.DaemonsDiscovered)
+		Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		Expect(condition.Reason).To(Equal("WaitForInterfaceInfo"))
+
+		// config failure
+		newStatus = *status.DeepCopy()
+		newStatus.NetConfigStatus = multinicv1.ConfigFailed
+		SetNetworkConditions(multinicnetwork, &newStatus)
+		Expect(meta.IsStatusConditionFalse(newStatus.Conditions, multinicv1.NetAttachDefReady)).To(BeTrue())
+		Expect(meta.IsStatusConditionTrue(newStatus.Conditions, multinicv1.Degraded)).To(BeTrue())
+	})
 })
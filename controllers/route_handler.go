@@ -7,6 +7,7 @@ package controllers
 
 import (
 	"fmt"
+	"strings"
 
 	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
 	"github.com/foundation-model-stack/multi-nic-cni/internal/vars"
@@ -46,11 +47,40 @@ func (h *RouteHandler) AddRoutesToHost(cidrSpec multinicv1.CIDRSpec, hostName st
 		// no change, connecion failed
 		return false, true
 	}
+	if cidrSpec.Config.RailOptimized {
+		return h.addRailRoutesToHost(cidrSpec, hostName, daemon, entries, forceDelete)
+	}
+	routes := h.getHostRoutes(hostName, daemon, entries)
+	return h.applyL3Config(hostName, daemon, cidrSpec.Config.Name, cidrSpec.Config.Subnet, cidrSpec.Config.Rule, routes, forceDelete)
+}
+
+// addRailRoutesToHost applies a separate L3 config to each rail of rail-optimized network
+// so that routes are only generated between interfaces of the same rail
+func (h *RouteHandler) addRailRoutesToHost(cidrSpec multinicv1.CIDRSpec, hostName string, daemon DaemonPod, entries []multinicv1.CIDREntry, forceDelete bool) (bool, bool) {
 	change := true
+	connectFail := false
+	railEntries := getRailEntries(cidrSpec.Config.MasterNetAddrs, entries)
+	for rail := range cidrSpec.Config.MasterNetAddrs {
+		routes := h.getHostRoutes(hostName, daemon, railEntries[rail])
+		railChange, railConnectFail := h.applyL3Config(hostName, daemon, GetRailL3ConfigName(cidrSpec.Config.Name, rail), getEntrySubnet(railEntries[rail]), cidrSpec.Config.Rule, routes, forceDelete)
+		if !railChange {
+			change = false
+		}
+		if railConnectFail {
+			connectFail = true
+		}
+	}
+	return change, connectFail
+}
+
+// getHostRoutes returns routes from the host to the other hosts of the CIDR entries
+func (h *RouteHandler) getHostRoutes(hostName string, daemon DaemonPod, entries []multinicv1.CIDREntry) []HostRoute {
 	mainSrcHostIP := daemon.HostIP
 	routes := []HostRoute{}
 	for _, entry := range entries {
-		interfaceIndex := entry.InterfaceIndex
+		// source host information is taken from the same entry
+		// so that next hop and destination are in the same address family
+		srcHost, srcExist := getEntryHost(entry, hostName)
 		for _, host := range entry.Hosts {
 			destHostName := host.HostName
 			destDaemon, err := h.DaemonCacheHandler.GetCache(destHostName)
@@ -61,9 +91,9 @@ func (h *RouteHandler) AddRoutesToHost(cidrSpec multinicv1.CIDRSpec, hostName st
 			mainDestHostIP := destDaemon.HostIP
 			net := host.PodCIDR
 			if mainDestHostIP != mainSrcHostIP {
-				if ifaceInfo, exist := hostInterfaceInfoMap[hostName][interfaceIndex]; exist {
-					iface := ifaceInfo.InterfaceName
-					via := hostInterfaceInfoMap[destHostName][interfaceIndex].HostIP
+				if srcExist {
+					iface := srcHost.InterfaceName
+					via := host.HostIP
 					route := HostRoute{
 						Subnet:        net,
 						NextHop:       via,
@@ -74,12 +104,18 @@ func (h *RouteHandler) AddRoutesToHost(cidrSpec multinicv1.CIDRSpec, hostName st
 			}
 		}
 	}
+	return routes
+}
+
+// applyL3Config applies routes of L3 config to the host daemon
+func (h *RouteHandler) applyL3Config(hostName string, daemon DaemonPod, name, subnet string, rule *multinicv1.PolicyRule, routes []HostRoute, forceDelete bool) (bool, bool) {
+	change := true
 	podAddress := GetDaemonAddressByPod(daemon)
-	res, err := h.DaemonConnector.ApplyL3Config(podAddress, cidrSpec.Config.Name, cidrSpec.Config.Subnet, routes, forceDelete)
+	res, err := h.DaemonConnector.ApplyL3Config(podAddress, name, subnet, rule, routes, forceDelete)
 	if err != nil {
-		vars.CIDRLog.V(6).Info(fmt.Sprintf("fail to apply L3config %s to %s: %v (%v)", cidrSpec.Config.Name, hostName, res, err))
+		vars.CIDRLog.V(6).Info(fmt.Sprintf("fail to apply L3config %s to %s: %v (%v)", name, hostName, res, err))
 	} else {
-		vars.CIDRLog.V(6).Info(fmt.Sprintf("Apply L3config %s to %s: %v", cidrSpec.Config.Name, hostName, res.Success))
+		vars.CIDRLog.V(6).Info(fmt.Sprintf("Apply L3config %s to %s: %v", name, hostName, res.Success))
 	}
 	if err != nil || !res.Success {
 		change = false
@@ -87,11 +123,67 @@ func (h *RouteHandler) AddRoutesToHost(cidrSpec multinicv1.CIDRSpec, hostName st
 	return change, res.Message == vars.ConnectionRefusedError
 }
 
+// GetRailIndex returns rail index of the network address which is the index in master networks, -1 if not a rail
+func GetRailIndex(masterNetAddrs []string, netAddress string) int {
+	for index, masterNetAddr := range masterNetAddrs {
+		if masterNetAddr == netAddress {
+			return index
+		}
+	}
+	return -1
+}
+
+// GetRailL3ConfigName returns name of L3 config (route table) of the rail
+func GetRailL3ConfigName(name string, rail int) string {
+	return fmt.Sprintf("%s-rail%d", name, rail)
+}
+
+// getRailEntries groups CIDR entries by rail index
+func getRailEntries(masterNetAddrs []string, entries []multinicv1.CIDREntry) map[int][]multinicv1.CIDREntry {
+	railEntries := make(map[int][]multinicv1.CIDREntry)
+	for _, entry := range entries {
+		rail := GetRailIndex(masterNetAddrs, entry.NetAddress)
+		if rail < 0 {
+			continue
+		}
+		railEntries[rail] = append(railEntries[rail], entry)
+	}
+	return railEntries
+}
+
+// getEntrySubnet returns comma-separated VLAN CIDRs of the entries as source subnet of L3 config
+func getEntrySubnet(entries []multinicv1.CIDREntry) string {
+	subnets := []string{}
+	for _, entry := range entries {
+		subnets = append(subnets, entry.VlanCIDR)
+	}
+	return strings.Join(subnets, ",")
+}
+
+// getEntryHost returns HostInterfaceInfo of the host in the CIDR entry
+func getEntryHost(entry multinicv1.CIDREntry, hostName string) (multinicv1.HostInterfaceInfo, bool) {
+	for _, host := range entry.Hosts {
+		if host.HostName == hostName {
+			return host, true
+		}
+	}
+	return multinicv1.HostInterfaceInfo{}, false
+}
+
 // DeleteRoutes deletes corresponding routes of CIDR
 func (h *RouteHandler) DeleteRoutes(cidrSpec multinicv1.CIDRSpec) {
 	daemonCache := h.DaemonCacheHandler.ListCache()
 	for hostName, daemon := range daemonCache {
 		podAddress := GetDaemonAddressByPod(daemon)
+		if cidrSpec.Config.RailOptimized {
+			railEntries := getRailEntries(cidrSpec.Config.MasterNetAddrs, cidrSpec.CIDRs)
+			for rail := range cidrSpec.Config.MasterNetAddrs {
+				name := GetRailL3ConfigName(cidrSpec.Config.Name, rail)
+				res, err := h.DaemonConnector.DeleteL3Config(podAddress, name, getEntrySubnet(railEntries[rail]))
+				vars.CIDRLog.V(6).Info(fmt.Sprintf("Delete L3config %s from %s: %v (%v)", name, hostName, res, err))
+			}
+			continue
+		}
 		res, err := h.DaemonConnector.DeleteL3Config(podAddress, cidrSpec.Config.Name, cidrSpec.Config.Subnet)
 		vars.CIDRLog.V(6).Info(fmt.Sprintf("Delete L3config %s from %s: %v (%v)", cidrSpec.Config.Name, hostName, res, err))
 	}
@@ -38,6 +38,9 @@ func RunPeriodicUpdate(ticker *time.Ticker, daemonWatcher *DaemonWatcher, cidrHa
 					for name, instanceSpec := range cidrSnapshot {
 						routeStatus := cidrHandler.SyncCIDRRoute(instanceSpec, false)
 						cidrHandler.CleanPendingIPPools(ippoolSnapshot, name, instanceSpec)
+						if err := cidrHandler.SyncCIDRStatus(name, instanceSpec); err != nil {
+							vars.SyncLog.V(3).Info(fmt.Sprintf("Failed to update status of CIDR %s: %v", name, err))
+						}
 						netStatus, err := cidrHandler.MultiNicNetworkHandler.SyncAllStatus(name, instanceSpec, routeStatus, daemonSize, infoAvailableSize, false)
 						if err != nil {
 							vars.SyncLog.V(3).Info(fmt.Sprintf("Failed to update route status of %s: %v", name, err))
@@ -10,24 +10,31 @@ import (
 	"fmt"
 	"log"
 	"math"
+	"math/big"
+	"net"
 	"os"
 	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
 )
 
 const (
-	SHIFT_BYTE_VAL  = 256
-	HISTORY_TIMEOUT = 60 // seconds
+	SHIFT_BYTE_VAL = 256
+
+	IPV4_BITS = 32
+	IPV6_BITS = 128
+	// MAX_IPV6_INDEX_BITS limits allocatable indexes of IPv6 pod CIDR (e.g., /64) to keep index in int range
+	MAX_IPV6_INDEX_BITS = 16
+	// MAX_EXCLUDE_BITS limits size of each exclude range
+	MAX_EXCLUDE_BITS = 32
 
 	HOSTNAME_LABEL_NAME = "hostname"
 	DEFNAME_LABEL_NAME  = "netname"
@@ -66,19 +73,18 @@ func getPFInterfaceName(vfInterfaceName string) string {
 	return pfInterfaceName
 }
 
-var allocatorLock sync.Mutex
-
 var K8sClientset *kubernetes.Clientset
 var IppoolHandler *backend.IPPoolHandler
+var IppoolCache *IPPoolCache
+var IpreservationHandler *backend.IPReservationHandler
+var IpreservationCache *IPReservationCache
+
+// PodEventHandler reports allocation failures as events on the pod, set by the daemon
+var PodEventHandler *backend.PodEventHandler
 
 type IPValue struct {
 	Address string
-	Value   int64
-}
-
-type allocateRecord struct {
-	time.Time
-	LastOffset int
+	Value   *big.Int
 }
 
 type allocation struct {
@@ -86,13 +92,6 @@ type allocation struct {
 	interfaceName string
 }
 
-func (r *allocateRecord) Expired() bool {
-	curr := time.Now()
-	return curr.Sub(r.Time).Seconds() > HISTORY_TIMEOUT
-}
-
-var deallocateHistory map[string]*allocateRecord = make(map[string]*allocateRecord)
-
 func FindAvailableIndex(indexes []int, leftIndex int) int {
 	if len(indexes) == 0 {
 		return -1
@@ -119,29 +118,43 @@ func FindAvailableIndex(indexes []int, leftIndex int) int {
 	}
 }
 
-func valueToAddr(value int64) [4]byte {
-	var output [4]byte
-	for index := 3; index >= 0; index-- {
-		output[index] = byte(value % SHIFT_BYTE_VAL)
-		value = value / SHIFT_BYTE_VAL
+func valueToAddr(value *big.Int, isIPv6 bool) net.IP {
+	size := net.IPv4len
+	if isIPv6 {
+		size = net.IPv6len
 	}
+	output := make(net.IP, size)
+	value.FillBytes(output)
 	return output
 }
 
-func valueToAddrStr(value int64) string {
-	ip := valueToAddr(value)
-	return fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3])
+func valueToAddrStr(value *big.Int, isIPv6 bool) string {
+	return valueToAddr(value, isIPv6).String()
 }
 
-func addrToValue(address string) int64 {
-	splits := strings.Split(address, ".")
-	var sumValue int64
-	sumValue = 0
-	for _, split := range splits {
-		val, _ := strconv.ParseInt(split, 10, 64)
-		sumValue = sumValue*SHIFT_BYTE_VAL + val
+func addrToValue(address string) *big.Int {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return big.NewInt(0)
 	}
-	return sumValue
+	if ip4 := ip.To4(); ip4 != nil {
+		return new(big.Int).SetBytes(ip4)
+	}
+	return new(big.Int).SetBytes(ip.To16())
+}
+
+// isIPv6CIDR returns true if the given CIDR or address is IPv6
+func isIPv6CIDR(cidr string) bool {
+	ip := net.ParseIP(strings.Split(cidr, "/")[0])
+	return ip != nil && ip.To4() == nil
+}
+
+// getAddressBits returns number of bits of address in the given CIDR family
+func getAddressBits(cidr string) int64 {
+	if isIPv6CIDR(cidr) {
+		return IPV6_BITS
+	}
+	return IPV4_BITS
 }
 
 func getIPValue(address string) IPValue {
@@ -155,8 +168,23 @@ func getIPValue(address string) IPValue {
 // 3. convert int back to string with valueToAddrStr
 func getAddressByIndex(cidr string, index int) string {
 	startIPInIpValue := getIPValue(cidr)
-	addressByIndex := startIPInIpValue.Value + int64(index)
-	return valueToAddrStr(addressByIndex)
+	addressByIndex := new(big.Int).Add(startIPInIpValue.Value, big.NewInt(int64(index)))
+	return valueToAddrStr(addressByIndex, isIPv6CIDR(cidr))
+}
+
+// getMaxIndex returns the upper bound of allocatable index within given pod CIDR.
+// IPv4 range excludes broadcast address; IPv6 range is limited by MAX_IPV6_INDEX_BITS.
+func getMaxIndex(podCIDR string) int {
+	cidrBlockStr := strings.Split(podCIDR, "/")[1]
+	cirdBlock, _ := strconv.ParseInt(cidrBlockStr, 10, 64)
+	availableBlock := getAddressBits(podCIDR) - cirdBlock
+	if isIPv6CIDR(podCIDR) {
+		if availableBlock > MAX_IPV6_INDEX_BITS {
+			availableBlock = MAX_IPV6_INDEX_BITS
+		}
+		return int(math.Pow(2, float64(availableBlock)) - 1)
+	}
+	return int(math.Pow(2, float64(availableBlock)) - 2) // except broadcast address
 }
 
 type ExcludeRange struct {
@@ -168,19 +196,30 @@ type ExcludeRange struct {
 func getExcludeRanges(cidr string, excludes []string) []ExcludeRange {
 	exludeRanges := []ExcludeRange{}
 	startIPInIpValue := getIPValue(cidr)
+	addressBits := getAddressBits(cidr)
 
 	for _, exclude := range excludes {
+		if isIPv6CIDR(exclude) != isIPv6CIDR(cidr) {
+			log.Println(fmt.Sprintf("exclude %s is not in the family of %s", exclude, cidr))
+			continue
+		}
 		excludeInIPValue := getIPValue(exclude)
-		excludeStartIndex := int(excludeInIPValue.Value - startIPInIpValue.Value)
-		if excludeStartIndex < 0 {
-			log.Println(fmt.Sprintf("exclude index %d < 0: %s", excludeStartIndex, exclude))
+		excludeStartValue := new(big.Int).Sub(excludeInIPValue.Value, startIPInIpValue.Value)
+		if excludeStartValue.Sign() < 0 {
+			log.Println(fmt.Sprintf("exclude index %s < 0: %s", excludeStartValue.String(), exclude))
+		} else if !excludeStartValue.IsInt64() || excludeStartValue.Int64() > math.MaxInt32 {
+			log.Println(fmt.Sprintf("exclude index %s out of range: %s", excludeStartValue.String(), exclude))
 		} else {
+			excludeStartIndex := int(excludeStartValue.Int64())
 			excludeIPSplits := strings.Split(exclude, "/")
-			excludeBlock := int64(32)
+			excludeBlock := addressBits
 			if len(excludeIPSplits) >= 2 {
 				excludeBlock, _ = strconv.ParseInt(excludeIPSplits[1], 10, 64)
 			}
-			availableBlock := 32 - excludeBlock
+			availableBlock := addressBits - excludeBlock
+			if availableBlock > MAX_EXCLUDE_BITS {
+				availableBlock = MAX_EXCLUDE_BITS
+			}
 			maxIndex := int(math.Pow(2, float64(availableBlock)) - 1)
 			r := ExcludeRange{
 				MinIndex: excludeStartIndex,
@@ -208,54 +247,166 @@ func GenerateAllocateIndexes(allocations []backend.Allocation, maxIndex int, exc
 }
 
 func AllocateIP(req IPRequest) []IPResponse {
-	podName := req.PodName
-	podNamespace := req.PodNamespace
 	defName := req.NetAttachDefName
 	hostName := req.HostName
-	interfaceNames := req.InterfaceNames
-
-	FlushExpiredHistory()
-	offset := 1
-	if record, ok := deallocateHistory[podName]; ok {
-		// anomaly
-		record.LastOffset += 1
-		offset = record.LastOffset
-		log.Printf("Found anomaly allocating %s: %d\n", podName, offset)
-	}
 
 	var responses []IPResponse
 	startAllocate := time.Now()
-	allocatorLock.Lock()
-	labelMap := map[string]string{HOSTNAME_LABEL_NAME: hostName, DEFNAME_LABEL_NAME: defName}
-	listOptions := metav1.ListOptions{
-		LabelSelector: labels.SelectorFromSet(labelMap).String(),
+	reservations := []backend.IPReservationType{}
+	if IpreservationCache != nil {
+		var err error
+		reservations, err = IpreservationCache.List(defName)
+		if err != nil {
+			log.Printf("Cannot list IPReservation of %s: %v", defName, err)
+		}
 	}
-	ippoolSpecMap, err := IppoolHandler.ListIPPool(listOptions)
-	if err != nil || len(ippoolSpecMap) == 0 {
-		log.Printf("Unable to proceed allocation without ippool or with error, ippools: %v, err: %v", ippoolSpecMap, err)
-		allocatorLock.Unlock()
-		return responses
+	newAllocations, ippoolSpecMap := IppoolCache.Allocate(hostName, defName, func(ippoolSpecMap map[string]backend.IPPoolType, releaseTimes map[string]map[int]time.Time) map[string]allocation {
+		return allocateIP(req, ippoolSpecMap, releaseTimes, reservations)
+	})
+	for ippoolName, newAllocation := range newAllocations {
+		response := IPResponse{
+			InterfaceName: newAllocation.interfaceName, // Use original VF name instead of PF name
+			IPAddress:     newAllocation.Address,
+			VLANBlockSize: strings.Split(ippoolSpecMap[ippoolName].VlanCIDR, "/")[1],
+		}
+		log.Println(fmt.Sprintf("Append response %v (ip=%s)", response, newAllocation.Address))
+		responses = append(responses, response)
+	}
+
+	if failures := getAllocationFailures(req, newAllocations, ippoolSpecMap); len(failures) > 0 {
+		message := fmt.Sprintf("%s: cannot allocate address for %s", defName, strings.Join(failures, ", "))
+		log.Println(message)
+		if PodEventHandler != nil {
+			PodEventHandler.EmitAsync(req.PodName, req.PodNamespace, corev1.EventTypeWarning, backend.IP_ALLOCATION_FAILED_REASON, message)
+		}
 	}
-	newAllocations := allocateIP(podName, podNamespace, interfaceNames, offset, ippoolSpecMap)
-	responses = applyNewAllocations(ippoolSpecMap, newAllocations)
-	allocatorLock.Unlock()
 
 	elapsed := time.Since(startAllocate)
 	log.Println(fmt.Sprintf("Allocate elapsed: %d us", int64(elapsed/time.Microsecond)))
 	return responses
 }
 
-func allocateIP(podName, podNamespace string, interfaceNames []string, offset int,
-	ippoolSpecMap map[string]backend.IPPoolType) map[string]allocation {
+// getAllocationFailures returns requested interfaces which are not assigned any address with the reason
+func getAllocationFailures(req IPRequest, newAllocations map[string]allocation, ippoolSpecMap map[string]backend.IPPoolType) []string {
+	allocatedInterfaces := make(map[string]bool)
+	for _, newAllocation := range newAllocations {
+		allocatedInterfaces[newAllocation.interfaceName] = true
+	}
+	failures := []string{}
+	for _, interfaceName := range req.InterfaceNames {
+		if allocatedInterfaces[interfaceName] {
+			continue
+		}
+		masterName := interfaceName
+		if isVF(interfaceName) {
+			masterName = getPFInterfaceName(interfaceName)
+		}
+		ippoolNames := []string{}
+		for ippoolName, spec := range ippoolSpecMap {
+			if spec.InterfaceName == masterName {
+				ippoolNames = append(ippoolNames, ippoolName)
+			}
+		}
+		sort.Strings(ippoolNames)
+		if len(ippoolNames) > 0 {
+			failures = append(failures, fmt.Sprintf("%s (no available address in %s)", interfaceName, strings.Join(ippoolNames, ",")))
+		} else {
+			failures = append(failures, fmt.Sprintf("%s (no IPPool on %s)", interfaceName, req.HostName))
+		}
+	}
+	return failures
+}
+
+// getIndexInPodCIDR returns index of the address in the pod CIDR or -1 if not allocatable
+func getIndexInPodCIDR(podCIDR string, address string) int {
+	_, ipNet, err := net.ParseCIDR(podCIDR)
+	ip := net.ParseIP(address)
+	if err != nil || ip == nil || isIPv6CIDR(address) != isIPv6CIDR(podCIDR) || !ipNet.Contains(ip) {
+		return -1
+	}
+	indexValue := new(big.Int).Sub(addrToValue(address), getIPValue(podCIDR).Value)
+	if !indexValue.IsInt64() || indexValue.Int64() <= 0 || indexValue.Int64() > int64(getMaxIndex(podCIDR)) {
+		return -1
+	}
+	return int(indexValue.Int64())
+}
+
+// getReservedIndexes returns the index reserved to the pod in the IPPool (-1 if none)
+// and exclude ranges of indexes reserved to other pods
+func getReservedIndexes(podName, podNamespace string, spec backend.IPPoolType, reservations []backend.IPReservationType) (int, []ExcludeRange) {
+	reservedIndex := -1
+	otherReserved := []ExcludeRange{}
+	for _, reservation := range reservations {
+		ownedAddresses := make(map[string]bool)
+		for _, reserved := range reservation.ReservedAddressesForPod(podName, podNamespace) {
+			ownedAddresses[reserved.Address] = true
+		}
+		for _, reserved := range reservation.AllReservedAddresses() {
+			if !reserved.IsOnInterface(spec.InterfaceName) {
+				continue
+			}
+			index := getIndexInPodCIDR(spec.PodCIDR, reserved.Address)
+			if index == -1 {
+				continue
+			}
+			if ownedAddresses[reserved.Address] {
+				if reservedIndex == -1 {
+					reservedIndex = index
+				}
+			} else {
+				otherReserved = append(otherReserved, ExcludeRange{MinIndex: index, MaxIndex: index})
+			}
+		}
+	}
+	return reservedIndex, otherReserved
+}
+
+// getReservedAllocatableIndex returns the index reserved to the pod if it is neither excluded nor held by others, otherwise -1
+func getReservedAllocatableIndex(podName, podNamespace string, spec backend.IPPoolType, reservedIndex int) int {
+	if reservedIndex == -1 {
+		return -1
+	}
+	for _, exclude := range getExcludeRanges(spec.PodCIDR, spec.Excludes) {
+		if reservedIndex >= exclude.MinIndex && reservedIndex <= exclude.MaxIndex {
+			log.Printf("Reserved index %d of %s/%s is excluded from %s", reservedIndex, podNamespace, podName, spec.PodCIDR)
+			return -1
+		}
+	}
+	for _, allocation := range spec.Allocations {
+		if allocation.Index == reservedIndex && (allocation.Pod != podName || allocation.Namespace != podNamespace) {
+			log.Printf("Reserved address %s of %s/%s is held by %s/%s", allocation.Address, podNamespace, podName, allocation.Namespace, allocation.Pod)
+			return -1
+		}
+	}
+	return reservedIndex
+}
+
+// allocateIP allocates an address of each requested interface by the allocation strategy of the request,
+// releaseTimes maps IPPool name to when each index was last released
+func allocateIP(req IPRequest, ippoolSpecMap map[string]backend.IPPoolType, releaseTimes map[string]map[int]time.Time,
+	reservations []backend.IPReservationType) map[string]allocation {
+	podName := req.PodName
+	podNamespace := req.PodNamespace
+	interfaceNames := req.InterfaceNames
+	quarantine := time.Duration(req.QuarantineSeconds) * time.Second
+	now := time.Now()
 
 	newAllocations := make(map[string]allocation)
+	// requested interface names of each address family
+	// (an interface is assigned one address of each family in dual-stack network)
+	familyInterfaceNames := make(map[bool][]string)
 	for ippoolName, _ := range ippoolSpecMap {
+		spec := ippoolSpecMap[ippoolName]
+		isIPv6 := isIPv6CIDR(spec.PodCIDR)
+		if _, found := familyInterfaceNames[isIPv6]; !found {
+			familyInterfaceNames[isIPv6] = append([]string{}, interfaceNames...)
+		}
+		interfaceNames := familyInterfaceNames[isIPv6]
 		if len(interfaceNames) == 0 {
 			// no more interfaces to allocate
 			log.Println("No more interfaces to assign")
-			break
+			continue
 		}
-		spec := ippoolSpecMap[ippoolName]
 		deleteIndex := -1
 		var originalInterfaceName string
 		for deleteIndex = 0; deleteIndex < len(interfaceNames); deleteIndex++ {
@@ -280,7 +431,7 @@ func allocateIP(podName, podNamespace string, interfaceNames []string, offset in
 			}
 		}
 		if deleteIndex >= 0 && deleteIndex != len(interfaceNames) {
-			interfaceNames = append(interfaceNames[0:deleteIndex], interfaceNames[deleteIndex+1:]...)
+			familyInterfaceNames[isIPv6] = append(interfaceNames[0:deleteIndex], interfaceNames[deleteIndex+1:]...)
 		} else {
 			// not match
 			log.Printf("Interface %s is not requested by %v\n", spec.InterfaceName, interfaceNames)
@@ -289,38 +440,30 @@ func allocateIP(podName, podNamespace string, interfaceNames []string, offset in
 
 		podCIDR := spec.PodCIDR
 		allocations := spec.Allocations
-		cidrBlockStr := strings.Split(podCIDR, "/")[1]
-		cirdBlock, _ := strconv.ParseInt(cidrBlockStr, 10, 64)
 		excludes := spec.Excludes
 
-		exludeRanges := getExcludeRanges(podCIDR, excludes)
-		availableBlock := 32 - cirdBlock
-		maxIndex := math.Pow(2, float64(availableBlock)) - 2 // except broadcast address
-		indexes := GenerateAllocateIndexes(allocations, int(maxIndex), exludeRanges)
+		reservedIndex, otherReserved := getReservedIndexes(podName, podNamespace, spec, reservations)
+		exludeRanges := append(getExcludeRanges(podCIDR, excludes), otherReserved...)
+		maxIndex := getMaxIndex(podCIDR)
+		indexes := GenerateAllocateIndexes(allocations, maxIndex, exludeRanges)
 		log.Printf("exclude %v, indexes %v\n", exludeRanges, indexes)
-		var nextIndex int
-		if len(indexes) > 0 {
-			lastIndex := indexes[len(indexes)-1]
-			nextIndex = lastIndex + offset
-		} else {
-			nextIndex = offset // except network address
+		nextIndex := getReservedAllocatableIndex(podName, podNamespace, spec, reservedIndex)
+		if nextIndex == -1 {
+			// reserved address is honored first
+			nextIndex = selectIndex(req.AllocationStrategy, indexes, maxIndex, releaseTimes[ippoolName], quarantine, now)
 		}
-
 		nextAddress := ""
-		if nextIndex < int(maxIndex) {
+		if nextIndex != -1 {
 			nextAddress = getAddressByIndex(podCIDR, nextIndex)
-		} else {
-			nextIndex = FindAvailableIndex(indexes, 0)
-			if nextIndex != -1 {
-				nextAddress = getAddressByIndex(podCIDR, nextIndex)
-			}
 		}
 		if nextAddress != "" {
 			newAllocation := backend.Allocation{
-				Pod:       podName,
-				Namespace: podNamespace,
-				Index:     nextIndex,
-				Address:   nextAddress,
+				Pod:         podName,
+				Namespace:   podNamespace,
+				PodUID:      req.PodUID,
+				ContainerID: req.ContainerID,
+				Index:       nextIndex,
+				Address:     nextAddress,
 			}
 			log.Println(newAllocation)
 			newAllocations[ippoolName] = allocation{
@@ -334,148 +477,106 @@ func allocateIP(podName, podNamespace string, interfaceNames []string, offset in
 	return newAllocations
 }
 
-func applyNewAllocations(ippoolSpecMap map[string]backend.IPPoolType, newAllocations map[string]allocation) []IPResponse {
-	var responses []IPResponse
-	for ippoolName, newAllocation := range newAllocations {
-		spec := ippoolSpecMap[ippoolName]
-		allocations := spec.Allocations
+func getPod(podName, podNamespace string) (*corev1.Pod, error) {
+	return K8sClientset.CoreV1().Pods(podNamespace).Get(context.TODO(), podName, metav1.GetOptions{})
 
-		toInsertIndex := -1
-		for allocationIndex, allocation := range allocations {
-			if allocation.Index > newAllocation.Index {
-				toInsertIndex = allocationIndex
-			}
-		}
-		if toInsertIndex == -1 {
-			allocations = append(allocations, newAllocation.Allocation)
-		} else {
-			appendedAllocation := append(allocations[0:toInsertIndex], newAllocation.Allocation)
-			allocations = append(appendedAllocation, allocations[toInsertIndex:]...)
-		}
+}
 
-		_, err := IppoolHandler.PatchIPPool(ippoolName, allocations)
-		if err == nil {
-			response := IPResponse{
-				InterfaceName: newAllocation.interfaceName, // Use original VF name instead of PF name
-				IPAddress:     newAllocation.Address,
-				VLANBlockSize: strings.Split(spec.VlanCIDR, "/")[1],
-			}
-			log.Println(fmt.Sprintf("Append response %v (ip=%s)", response, newAllocation.Address))
-			responses = append(responses, response)
-		} else {
-			log.Println(fmt.Sprintf("Cannot patch IPPool: %v", err))
-		}
+// getActiveAllocation returns the allocation if it belongs to the running pod instance,
+// an allocation made before pod UID is recorded is migrated with UID of the pod
+func getActiveAllocation(allocation backend.Allocation, pod *corev1.Pod) (backend.Allocation, bool) {
+	podUID := string(pod.GetUID())
+	if !allocation.IsOwnedBy(pod.GetName(), pod.GetNamespace(), podUID, "") {
+		log.Printf("Allocation %s of %s/%s belongs to previous pod instance %s", allocation.Address,
+			allocation.Namespace, allocation.Pod, allocation.PodUID)
+		return allocation, false
 	}
-	return responses
+	if allocation.PodUID == "" {
+		allocation.PodUID = podUID
+	}
+	return allocation, true
 }
 
-func getPod(podName, podNamespace string) (*corev1.Pod, error) {
-	return K8sClientset.CoreV1().Pods(podNamespace).Get(context.TODO(), podName, metav1.GetOptions{})
-
-}
+// CleanHangingAllocation releases allocations of the host whose pods are not found or replaced by another pod instance
+// through IPPool cache at start, allocations of completed pods are left to the allocation garbage collection
 func CleanHangingAllocation(hostName string) error {
-	labelMap := map[string]string{HOSTNAME_LABEL_NAME: hostName}
-	// hostName suffix
-	listOptions := metav1.ListOptions{
-		LabelSelector: labels.SelectorFromSet(labelMap).String(),
-	}
-	ippoolSpecMap, err := IppoolHandler.ListIPPool(listOptions)
+	ippoolSpecMap, err := IppoolCache.List(hostName, "")
 	if err != nil {
 		return err
 	}
-	for ippoolName, _ := range ippoolSpecMap {
-		spec := ippoolSpecMap[ippoolName]
-		allocations := spec.Allocations
-		remains := []backend.Allocation{}
-		for _, allocation := range allocations {
-			_, err := getPod(allocation.Pod, allocation.Namespace)
-			if err == nil {
-				remains = append(remains, allocation)
+	blockedPools := IppoolCache.replayBlockedPools()
+	reservationMap := make(map[string][]backend.IPReservationType)
+	for ippoolName, spec := range ippoolSpecMap {
+		if blockedPools[ippoolName] {
+			log.Printf("Skip cleaning %s before replaying journal", ippoolName)
+			continue
+		}
+		reservations, found := reservationMap[spec.NetAttachDefName]
+		if !found && IpreservationCache != nil {
+			reservations, err = IpreservationCache.List(spec.NetAttachDefName)
+			if err != nil {
+				// cannot tell which allocations are reserved
+				log.Printf("Skip cleaning %s, cannot list IPReservation: %v", ippoolName, err)
+				continue
 			}
+			reservationMap[spec.NetAttachDefName] = reservations
 		}
-		_, err = IppoolHandler.PatchIPPool(ippoolName, remains)
-		if err != nil {
-			log.Println(fmt.Sprintf("Cannot patch IPPool: %v", err))
+		for _, allocation := range spec.Allocations {
+			if backend.IsReservedAllocation(reservations, spec.InterfaceName, allocation) {
+				continue
+			}
+			pod, err := getPod(allocation.Pod, allocation.Namespace)
+			if err != nil && !errors.IsNotFound(err) {
+				// cannot tell whether the pod is running
+				log.Printf("Keep allocation %s of %s/%s, cannot get pod: %v", allocation.Address,
+					allocation.Namespace, allocation.Pod, err)
+				continue
+			}
+			if err == nil {
+				if _, ok := getActiveAllocation(allocation, pod); ok {
+					continue
+				}
+			}
+			if err := IppoolCache.Release(ippoolName, allocation); err != nil {
+				log.Printf("Cannot release hanging allocation %v: %v", allocation, err)
+			}
 		}
 	}
 	return nil
 }
 
 func DeallocateIP(req IPRequest) []IPResponse {
-	podName := req.PodName
-	podNamespace := req.PodNamespace
 	defName := req.NetAttachDefName
 	hostName := req.HostName
 	interfaceNames := req.InterfaceNames
 
-	// set first record
-	if _, ok := deallocateHistory[podName]; !ok {
-		log.Printf("Add %s to deallocateHistory\n", podName)
-		deallocateHistory[podName] = &allocateRecord{
-			Time:       time.Now(),
-			LastOffset: 1,
-		}
-	}
-
 	var responses []IPResponse
 	startDeallocate := time.Now()
-	allocatorLock.Lock()
-	labelMap := map[string]string{HOSTNAME_LABEL_NAME: hostName, DEFNAME_LABEL_NAME: defName}
-	listOptions := metav1.ListOptions{
-		LabelSelector: labels.SelectorFromSet(labelMap).String(),
-	}
-	ippoolSpecMap, err := IppoolHandler.ListIPPool(listOptions)
-	if err != nil {
-		return responses
-	}
-	for ippoolName, _ := range ippoolSpecMap {
+	removedAllocations, ippoolSpecMap := IppoolCache.Deallocate(hostName, defName, req.PodName, req.PodNamespace, req.PodUID, req.ContainerID)
+	for ippoolName, allocation := range removedAllocations {
 		spec := ippoolSpecMap[ippoolName]
-		if spec.NetAttachDefName == defName && strings.Contains(spec.HostName, hostName) {
-			allocations := spec.Allocations
-
-			for index, allocation := range allocations {
-				if allocation.Pod == podName && allocation.Namespace == podNamespace {
-					allocations = append(allocations[0:index], allocations[index+1:]...)
-					_, err = IppoolHandler.PatchIPPool(ippoolName, allocations)
-					if err != nil {
-						log.Println(fmt.Sprintf("Cannot patch IPPool: %v", err))
-					}
-					// Map PF interface name back to VF if needed
-					responseInterfaceName := spec.InterfaceName // Default to PF name
-					for _, vfInterfaceName := range interfaceNames {
-						if isVF(vfInterfaceName) {
-							pfInterfaceName := getPFInterfaceName(vfInterfaceName)
-							if pfInterfaceName == spec.InterfaceName {
-								responseInterfaceName = vfInterfaceName // Use VF name in response
-								log.Printf("Deallocate: mapping PF %s back to VF %s", spec.InterfaceName, vfInterfaceName)
-								break
-							}
-						}
-					}
-
-					response := IPResponse{
-						InterfaceName: responseInterfaceName, // Use VF name if available, otherwise PF name
-						IPAddress:     allocation.Address,
-						VLANBlockSize: strings.Split(spec.VlanCIDR, "/")[1],
-					}
-					responses = append(responses, response)
+		// Map PF interface name back to VF if needed
+		responseInterfaceName := spec.InterfaceName // Default to PF name
+		for _, vfInterfaceName := range interfaceNames {
+			if isVF(vfInterfaceName) {
+				pfInterfaceName := getPFInterfaceName(vfInterfaceName)
+				if pfInterfaceName == spec.InterfaceName {
+					responseInterfaceName = vfInterfaceName // Use VF name in response
+					log.Printf("Deallocate: mapping PF %s back to VF %s", spec.InterfaceName, vfInterfaceName)
 					break
 				}
 			}
 		}
+
+		response := IPResponse{
+			InterfaceName: responseInterfaceName, // Use VF name if available, otherwise PF name
+			IPAddress:     allocation.Address,
+			VLANBlockSize: strings.Split(spec.VlanCIDR, "/")[1],
+		}
+		responses = append(responses, response)
 	}
-	allocatorLock.Unlock()
 
 	elapsed := time.Since(startDeallocate)
 	log.Println(fmt.Sprintf("Deallocate elapsed: %d us", int64(elapsed/time.Microsecond)))
 	return responses
 }
-
-func FlushExpiredHistory() {
-	for podName, record := range deallocateHistory {
-		if record.Expired() {
-			log.Printf("Flush expired deallocateHistory: %s\n", podName)
-			delete(deallocateHistory, podName)
-		}
-	}
-}
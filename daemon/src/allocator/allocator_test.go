@@ -12,12 +12,11 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
 
-	"time"
-
 	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
 )
 
@@ -83,6 +82,8 @@ var _ = Describe("Test Allocator", func() {
 			Entry("zero index", "10.0.0.0/16", 0, "10.0.0.0"),
 			Entry("first index", "10.0.0.0/16", 1, "10.0.0.1"),
 			Entry("shifted index", "10.0.0.0/16", 256, "10.0.1.0"),
+			Entry("ipv6 first index", "fd00:0:0:1::/64", 1, "fd00:0:0:1::1"),
+			Entry("ipv6 shifted index", "fd00:0:0:1::/64", 65536, "fd00::1:0:0:1:0"),
 		)
 
 		DescribeTable("getExcludeRanges", func(cidr string, excludes []string, expected []ExcludeRange) {
@@ -118,10 +119,23 @@ var _ = Describe("Test Allocator", func() {
 					},
 				},
 			),
+			Entry("ipv6 inner excludes", "fd00::/64", []string{"fd00::/120", "fd00::1:1/128"},
+				[]ExcludeRange{
+					ExcludeRange{
+						MinIndex: 0,
+						MaxIndex: 255,
+					},
+					ExcludeRange{
+						MinIndex: 65537,
+						MaxIndex: 65537,
+					},
+				},
+			),
+			Entry("other family exclude", "fd00::/64", []string{"10.0.0.0/24"}, []ExcludeRange{}),
 		)
 
 		DescribeTable("allocateIP", func(interfaceNames []string, ippoolSpecMap map[string]backend.IPPoolType, expectedAddress map[string]string) {
-			newAllocations := allocateIP("test-pod", "test-namespace", interfaceNames, 1, ippoolSpecMap)
+			newAllocations := allocateIP(IPRequest{PodName: "test-pod", PodNamespace: "test-namespace", InterfaceNames: interfaceNames}, ippoolSpecMap, nil, nil)
 			Expect(newAllocations).To(HaveLen(len(expectedAddress)))
 			for ippoolName, allocation := range newAllocations {
 				address, found := expectedAddress[ippoolName]
@@ -168,24 +182,118 @@ var _ = Describe("Test Allocator", func() {
 			}, map[string]string{
 				"eth0": "192.168.0.1",
 			}),
+			Entry("ipv6 first allocation", []string{"eth0"}, map[string]backend.IPPoolType{
+				"eth0-v6": backend.IPPoolType{InterfaceName: "eth0", PodCIDR: "fd00:0:0:1::/64"},
+			}, map[string]string{
+				"eth0-v6": "fd00:0:0:1::1",
+			}),
+			Entry("ipv6 allocation with excludes", []string{"eth0"}, map[string]backend.IPPoolType{
+				"eth0-v6": backend.IPPoolType{
+					InterfaceName: "eth0",
+					PodCIDR:       "fd00:0:0:1::/64",
+					Excludes:      []string{"fd00:0:0:1::/126"},
+				},
+			}, map[string]string{
+				"eth0-v6": "fd00:0:0:1::4",
+			}),
+			Entry("dual-stack allocation", []string{"eth0", "eth1"}, map[string]backend.IPPoolType{
+				"eth0":    backend.IPPoolType{InterfaceName: "eth0", PodCIDR: "192.168.0.0/24"},
+				"eth0-v6": backend.IPPoolType{InterfaceName: "eth0", PodCIDR: "fd00:0:0:1::/64"},
+				"eth1":    backend.IPPoolType{InterfaceName: "eth1", PodCIDR: "192.168.1.0/24"},
+				"eth1-v6": backend.IPPoolType{InterfaceName: "eth1", PodCIDR: "fd00:0:0:2::/64"},
+			}, map[string]string{
+				"eth0":    "192.168.0.1",
+				"eth0-v6": "fd00:0:0:1::1",
+				"eth1":    "192.168.1.1",
+				"eth1-v6": "fd00:0:0:2::1",
+			}),
 		)
-	})
 
-	Context("Deallocate", func() {
+		DescribeTable("getAllocationFailures", func(interfaceNames []string, ippoolSpecMap map[string]backend.IPPoolType, expected []string) {
+			req := IPRequest{PodName: "test-pod", PodNamespace: "test-namespace", HostName: "host1", InterfaceNames: interfaceNames}
+			newAllocations := allocateIP(req, ippoolSpecMap, nil, nil)
+			Expect(getAllocationFailures(req, newAllocations, ippoolSpecMap)).To(Equal(expected))
+		},
+			Entry("allocated", []string{"eth0"}, map[string]backend.IPPoolType{
+				"eth0": backend.IPPoolType{InterfaceName: "eth0", PodCIDR: "192.168.0.0/24"},
+			}, []string{}),
+			Entry("no ippool", []string{"eth0", "eth1"}, map[string]backend.IPPoolType{
+				"eth0": backend.IPPoolType{InterfaceName: "eth0", PodCIDR: "192.168.0.0/24"},
+			}, []string{"eth1 (no IPPool on host1)"}),
+			Entry("full ippool", []string{"eth0"}, map[string]backend.IPPoolType{
+				"eth0": backend.IPPoolType{InterfaceName: "eth0", PodCIDR: "192.168.0.0/30", Allocations: []backend.Allocation{
+					{Pod: "a", Namespace: "test-namespace", Index: 1, Address: "192.168.0.1"},
+					{Pod: "b", Namespace: "test-namespace", Index: 2, Address: "192.168.0.2"},
+					{Pod: "c", Namespace: "test-namespace", Index: 3, Address: "192.168.0.3"},
+				}},
+			}, []string{"eth0 (no available address in eth0)"}),
+		)
+
+		podReservation := backend.IPReservationType{
+			Name:      "pod",
+			Namespace: "test-namespace",
+			Spec: backend.IPReservationSpec{
+				PodName:   "test-pod",
+				Addresses: []backend.ReservedAddress{{InterfaceName: "eth0", Address: "192.168.0.10"}},
+			},
+		}
+		stsReservation := backend.IPReservationType{
+			Name:      "sts",
+			Namespace: "test-namespace",
+			Spec: backend.IPReservationSpec{
+				StatefulSet: &backend.StatefulSetReservation{Name: "worker", Replicas: 3},
+				Addresses:   []backend.ReservedAddress{{Address: "192.168.0.1"}},
+			},
+		}
+
+		DescribeTable("allocateIP with IPReservation", func(podName string, reservations []backend.IPReservationType, allocations []backend.Allocation, expectedAddress string) {
+			ippoolSpecMap := map[string]backend.IPPoolType{
+				"eth0": backend.IPPoolType{InterfaceName: "eth0", PodCIDR: "192.168.0.0/24", Allocations: allocations},
+			}
+			newAllocations := allocateIP(IPRequest{PodName: podName, PodNamespace: "test-namespace", InterfaceNames: []string{"eth0"}}, ippoolSpecMap, nil, reservations)
+			Expect(newAllocations).To(HaveKey("eth0"))
+			Expect(newAllocations["eth0"].Address).To(Equal(expectedAddress))
+		},
+			Entry("reserved pod name", "test-pod", []backend.IPReservationType{podReservation}, nil, "192.168.0.10"),
+			Entry("other pod skips reserved addresses", "other-pod", []backend.IPReservationType{stsReservation}, nil, "192.168.0.4"),
+			Entry("statefulset ordinal", "worker-2", []backend.IPReservationType{stsReservation}, nil, "192.168.0.3"),
+			Entry("statefulset ordinal out of replicas", "worker-3", []backend.IPReservationType{stsReservation}, nil, "192.168.0.4"),
+			Entry("reserved address held by previous pod with the same name", "worker-1", []backend.IPReservationType{stsReservation},
+				[]backend.Allocation{{Pod: "worker-1", Namespace: "test-namespace", Index: 2, Address: "192.168.0.2"}}, "192.168.0.2"),
+			Entry("reserved address held by other pod", "test-pod", []backend.IPReservationType{podReservation},
+				[]backend.Allocation{{Pod: "dummy", Namespace: "test-namespace", Index: 10, Address: "192.168.0.10"}}, "192.168.0.11"),
+			Entry("reservation on other interface", "test-pod", []backend.IPReservationType{{
+				Namespace: "test-namespace",
+				Spec: backend.IPReservationSpec{
+					PodName:   "test-pod",
+					Addresses: []backend.ReservedAddress{{InterfaceName: "eth1", Address: "192.168.0.10"}},
+				},
+			}}, nil, "192.168.0.1"),
+		)
 
-		It("force expired", func() {
-			podName := "A"
-			deallocateHistory[podName] = &allocateRecord{
-				Time:       time.Now(),
-				LastOffset: 1,
+		It("allocateIP skips excluded reserved address", func() {
+			ippoolSpecMap := map[string]backend.IPPoolType{
+				"eth0": backend.IPPoolType{InterfaceName: "eth0", PodCIDR: "192.168.0.0/24", Excludes: []string{"192.168.0.8/29"}},
 			}
-			Expect(deallocateHistory[podName].Expired()).To(Equal(false))
-			deallocateHistory[podName].Time = deallocateHistory[podName].Time.Add(time.Duration(-HISTORY_TIMEOUT-1) * time.Second)
-			Expect(deallocateHistory[podName].Expired()).To(Equal(true))
-			FlushExpiredHistory()
-			_, found := deallocateHistory[podName]
-			Expect(found).To(BeFalse())
+			newAllocations := allocateIP(IPRequest{PodName: "test-pod", PodNamespace: "test-namespace", InterfaceNames: []string{"eth0"}}, ippoolSpecMap, nil, []backend.IPReservationType{podReservation})
+			Expect(newAllocations).To(HaveKey("eth0"))
+			// the first address after the excluded range
+			Expect(newAllocations["eth0"].Address).To(Equal("192.168.0.16"))
 		})
+	})
+
+	Context("Deallocate", func() {
+
+		DescribeTable("getActiveAllocation", func(allocation backend.Allocation, expectedActive bool, expectedUID string) {
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "A", Namespace: "default", UID: "uid-2"}}
+			remain, active := getActiveAllocation(allocation, pod)
+			Expect(active).To(Equal(expectedActive))
+			Expect(remain.PodUID).To(Equal(expectedUID))
+		},
+			Entry("same pod instance", backend.Allocation{Pod: "A", Namespace: "default", PodUID: "uid-2"}, true, "uid-2"),
+			Entry("previous pod instance", backend.Allocation{Pod: "A", Namespace: "default", PodUID: "uid-1"}, false, "uid-1"),
+			Entry("migrate allocation without UID", backend.Allocation{Pod: "A", Namespace: "default"}, true, "uid-2"),
+		)
 
 	})
 
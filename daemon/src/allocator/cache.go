@@ -0,0 +1,583 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// MAX_ALLOCATE_ATTEMPTS limits how many times allocation is recomputed
+	// when the chosen address is taken by an external update of IPPool
+	MAX_ALLOCATE_ATTEMPTS = 3
+)
+
+// ippoolStore reads and writes IPPool on API server
+type ippoolStore interface {
+	ListIPPoolObject(listOptions metav1.ListOptions) ([]backend.IPPoolObject, error)
+	GetIPPoolObject(poolname string) (backend.IPPoolObject, error)
+	UpdateIPPoolAllocations(poolname string, resourceVersion string, allocations []backend.Allocation) (backend.IPPoolObject, error)
+}
+
+// errIndexConflict is returned when the allocated index is taken by another pod in the latest IPPool
+type errIndexConflict struct {
+	ippoolName string
+	allocation backend.Allocation
+}
+
+func (e errIndexConflict) Error() string {
+	return fmt.Sprintf("index %d of %s is held by another pod", e.allocation.Index, e.ippoolName)
+}
+
+// poolOperation adds or removes an allocation of IPPool
+// done receives the result once the operation is written to API server
+// seq is the sequence number of its intent in the allocation journal
+type poolOperation struct {
+	allocation backend.Allocation
+	remove     bool
+	done       chan error
+	seq        uint64
+}
+
+// cachedIPPool keeps the IPPool last seen on API server and local operations on top of it
+// inflight operations are being written, pending operations wait for the next write
+// released keeps when each index was last released, restored from the journal
+type cachedIPPool struct {
+	backend.IPPoolObject
+	inflight []*poolOperation
+	pending  []*poolOperation
+	flushing bool
+	released map[int]time.Time
+}
+
+// IPPoolCache keeps IPPools of the node in memory, allocates on the cached view,
+// and coalesces concurrent changes of each IPPool into a single resourceVersion-guarded update
+type IPPoolCache struct {
+	sync.Mutex
+	store      ippoolStore
+	pools      map[string]*cachedIPPool
+	hasSynced  func() bool
+	backoff    wait.Backoff
+	journal    *AllocationJournal
+	replayLock sync.Mutex
+}
+
+// NewIPPoolCache returns a cache which reads through to API server until an informer is started
+func NewIPPoolCache(store ippoolStore) *IPPoolCache {
+	return &IPPoolCache{
+		store:     store,
+		pools:     make(map[string]*cachedIPPool),
+		hasSynced: func() bool { return false },
+		backoff:   retry.DefaultRetry,
+	}
+}
+
+// Start watches IPPools of the host and waits for the initial list
+func (c *IPPoolCache) Start(handler *backend.IPPoolHandler, hostName string, stopCh <-chan struct{}) {
+	gvr, _ := schema.ParseResourceArg(backend.IPPOOL_RESOURCE)
+	labelMap := map[string]string{HOSTNAME_LABEL_NAME: hostName}
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(handler.DYN, 0, metav1.NamespaceAll, func(options *metav1.ListOptions) {
+		options.LabelSelector = labels.SelectorFromSet(labelMap).String()
+	})
+	informer := factory.ForResource(*gvr).Informer()
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			if uobj, ok := obj.(*unstructured.Unstructured); ok {
+				c.set(handler.ParseIPPoolObject(*uobj))
+			}
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			if uobj, ok := obj.(*unstructured.Unstructured); ok {
+				c.set(handler.ParseIPPoolObject(*uobj))
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if uobj, ok := obj.(*unstructured.Unstructured); ok {
+				c.delete(uobj.GetName())
+			}
+		},
+	})
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		log.Println("IPPool cache is not synced, read through API server")
+		return
+	}
+	c.Lock()
+	c.hasSynced = informer.HasSynced
+	poolCount := len(c.pools)
+	c.Unlock()
+	log.Printf("IPPool cache synced with %d IPPools", poolCount)
+}
+
+// SetJournal sets the journal to record operations before they are written to IPPools
+func (c *IPPoolCache) SetJournal(journal *AllocationJournal) {
+	c.Lock()
+	defer c.Unlock()
+	c.journal = journal
+}
+
+// ReplayJournal applies unresolved intents of the journal to IPPools,
+// intents of IPPools which cannot be updated are kept for the next replay
+func (c *IPPoolCache) ReplayJournal() error {
+	c.replayLock.Lock()
+	defer c.replayLock.Unlock()
+	records := c.journal.getUnreplayed()
+	if len(records) == 0 {
+		return nil
+	}
+	recordMap := make(map[string][]journalRecord)
+	for _, record := range records {
+		recordMap[record.IPPool] = append(recordMap[record.IPPool], record)
+	}
+	remains := []journalRecord{}
+	var replayErr error
+	for ippoolName, ops := range getReplayOperations(records) {
+		err := retry.RetryOnConflict(c.backoff, func() error {
+			latest, err := c.store.GetIPPoolObject(ippoolName)
+			if err != nil {
+				return err
+			}
+			allocations, _ := applyPoolOperations(ippoolName, latest.Spec.Allocations, ops)
+			if len(allocations) == len(latest.Spec.Allocations) {
+				c.set(latest)
+				return nil
+			}
+			updated, err := c.store.UpdateIPPoolAllocations(ippoolName, latest.ResourceVersion, allocations)
+			if err == nil {
+				c.set(updated)
+			}
+			return err
+		})
+		if errors.IsNotFound(err) {
+			log.Printf("Skip replaying journal of deleted IPPool %s", ippoolName)
+			continue
+		}
+		if err != nil {
+			log.Printf("Cannot replay journal of IPPool %s: %v", ippoolName, err)
+			remains = append(remains, recordMap[ippoolName]...)
+			replayErr = err
+			continue
+		}
+		for _, record := range recordMap[ippoolName] {
+			log.Printf("Replay journal: %s", describeReplay(record))
+		}
+	}
+	if err := c.journal.markReplayed(remains); err != nil {
+		log.Printf("Cannot compact allocation journal: %v", err)
+	}
+	return replayErr
+}
+
+// replayBlockedPools replays the journal and returns IPPools whose intents are still unresolved,
+// operations on those IPPools wait for the next replay while other IPPools proceed
+func (c *IPPoolCache) replayBlockedPools() map[string]bool {
+	if err := c.ReplayJournal(); err != nil {
+		log.Printf("Cannot replay allocation journal: %v", err)
+	}
+	return c.journal.getUnreplayedPools()
+}
+
+// isNewerResourceVersion returns true if incoming resource version is newer than current one
+// resource versions are compared as numbers, an unparsable version is taken as newer if it differs
+func isNewerResourceVersion(incoming, current string) bool {
+	if current == "" {
+		return true
+	}
+	incomingValue, incomingErr := strconv.ParseUint(incoming, 10, 64)
+	currentValue, currentErr := strconv.ParseUint(current, 10, 64)
+	if incomingErr != nil || currentErr != nil {
+		return incoming != current
+	}
+	return incomingValue > currentValue
+}
+
+// set updates the IPPool if it is newer than the cached one
+func (c *IPPoolCache) set(obj backend.IPPoolObject) {
+	c.Lock()
+	defer c.Unlock()
+	c.setLocked(obj)
+}
+
+func (c *IPPoolCache) setLocked(obj backend.IPPoolObject) bool {
+	pool, found := c.pools[obj.Name]
+	if !found {
+		c.pools[obj.Name] = &cachedIPPool{IPPoolObject: obj, released: c.journal.getReleaseTimes(obj.Name)}
+		return true
+	}
+	if !isNewerResourceVersion(obj.ResourceVersion, pool.ResourceVersion) {
+		return false
+	}
+	pool.IPPoolObject = obj
+	return true
+}
+
+// delete removes the IPPool and fails its pending operations,
+// inflight operations fail when the writer cannot find the IPPool
+func (c *IPPoolCache) delete(name string) {
+	c.Lock()
+	defer c.Unlock()
+	if pool, found := c.pools[name]; found {
+		for _, op := range pool.pending {
+			op.done <- fmt.Errorf("IPPool %s is deleted", name)
+		}
+		delete(c.pools, name)
+		c.journal.forgetReleases(name)
+	}
+}
+
+// refresh reads IPPools from API server if the informer is not synced
+func (c *IPPoolCache) refresh(hostName, defName string) error {
+	c.Lock()
+	synced := c.hasSynced()
+	c.Unlock()
+	if synced {
+		return nil
+	}
+	labelMap := map[string]string{HOSTNAME_LABEL_NAME: hostName}
+	if defName != "" {
+		labelMap[DEFNAME_LABEL_NAME] = defName
+	}
+	objects, err := c.store.ListIPPoolObject(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labelMap).String(),
+	})
+	if err != nil {
+		return err
+	}
+	c.Lock()
+	defer c.Unlock()
+	for _, obj := range objects {
+		c.setLocked(obj)
+	}
+	return nil
+}
+
+// listLocked returns IPPools of the host and network (all networks if defName is empty) with local operations applied
+func (c *IPPoolCache) listLocked(hostName, defName string) map[string]backend.IPPoolType {
+	ippoolSpecMap := make(map[string]backend.IPPoolType)
+	for name, pool := range c.pools {
+		if pool.Spec.HostName != hostName || (defName != "" && pool.Spec.NetAttachDefName != defName) {
+			continue
+		}
+		spec := pool.Spec
+		spec.Allocations, _ = applyPoolOperations(name, pool.Spec.Allocations, append(append([]*poolOperation{}, pool.inflight...), pool.pending...))
+		spec.Excludes = append([]string{}, pool.Spec.Excludes...)
+		ippoolSpecMap[name] = spec
+	}
+	return ippoolSpecMap
+}
+
+// releaseTimesLocked returns when each index of the IPPools was last released
+func (c *IPPoolCache) releaseTimesLocked(ippoolSpecMap map[string]backend.IPPoolType) map[string]map[int]time.Time {
+	releaseTimes := make(map[string]map[int]time.Time)
+	for name := range ippoolSpecMap {
+		releaseTimes[name] = make(map[int]time.Time)
+		for index, releaseTime := range c.pools[name].released {
+			releaseTimes[name][index] = releaseTime
+		}
+	}
+	return releaseTimes
+}
+
+// List returns IPPools of the host and network as seen by the allocator
+func (c *IPPoolCache) List(hostName, defName string) (map[string]backend.IPPoolType, error) {
+	if err := c.refresh(hostName, defName); err != nil {
+		return nil, err
+	}
+	c.Lock()
+	defer c.Unlock()
+	return c.listLocked(hostName, defName), nil
+}
+
+// GetAttachedPods returns namespace/name of pods with allocations on each interface of the host over all networks
+func (c *IPPoolCache) GetAttachedPods(hostName string) (map[string][]string, error) {
+	ippoolSpecMap, err := c.List(hostName, "")
+	if err != nil {
+		return nil, err
+	}
+	podSets := make(map[string]map[string]bool)
+	for _, spec := range ippoolSpecMap {
+		if _, found := podSets[spec.InterfaceName]; !found {
+			podSets[spec.InterfaceName] = make(map[string]bool)
+		}
+		for _, allocation := range spec.Allocations {
+			podSets[spec.InterfaceName][allocation.Namespace+"/"+allocation.Pod] = true
+		}
+	}
+	attachedPods := make(map[string][]string)
+	for interfaceName, podSet := range podSets {
+		pods := []string{}
+		for pod := range podSet {
+			pods = append(pods, pod)
+		}
+		sort.Strings(pods)
+		attachedPods[interfaceName] = pods
+	}
+	return attachedPods, nil
+}
+
+// synced returns true if the IPPools are kept updated by the informer
+func (c *IPPoolCache) synced() bool {
+	c.Lock()
+	defer c.Unlock()
+	return c.hasSynced()
+}
+
+// Release removes the allocation from the cached IPPool and waits until it is written
+func (c *IPPoolCache) Release(ippoolName string, allocation backend.Allocation) error {
+	c.Lock()
+	op := c.enqueueLocked(ippoolName, allocation, true)
+	c.Unlock()
+	return <-op.done
+}
+
+// enqueueLocked adds the operation to the IPPool and starts a writer if none is running
+func (c *IPPoolCache) enqueueLocked(ippoolName string, allocation backend.Allocation, remove bool) *poolOperation {
+	op := &poolOperation{allocation: allocation, remove: remove, done: make(chan error, 1)}
+	pool, found := c.pools[ippoolName]
+	if !found {
+		op.done <- fmt.Errorf("IPPool %s not found", ippoolName)
+		return op
+	}
+	pool.pending = append(pool.pending, op)
+	if !pool.flushing {
+		pool.flushing = true
+		go c.flush(ippoolName)
+	}
+	return op
+}
+
+// flush writes pending operations of the IPPool until no more operation is waiting
+func (c *IPPoolCache) flush(ippoolName string) {
+	for {
+		c.Lock()
+		pool, found := c.pools[ippoolName]
+		if !found || len(pool.pending) == 0 {
+			if found {
+				pool.flushing = false
+			}
+			c.Unlock()
+			return
+		}
+		pool.inflight = pool.pending
+		pool.pending = nil
+		ops := pool.inflight
+		c.Unlock()
+		c.write(ippoolName, ops)
+	}
+}
+
+// write applies operations on the latest IPPool and updates allocations with resourceVersion guard,
+// the IPPool is read again from API server on conflict
+func (c *IPPoolCache) write(ippoolName string, ops []*poolOperation) {
+	var results map[*poolOperation]error
+	start := time.Now()
+	if err := c.journal.Intent(ippoolName, ops); err != nil {
+		log.Printf("Cannot write intent to allocation journal: %v", err)
+	}
+	err := retry.RetryOnConflict(c.backoff, func() error {
+		c.Lock()
+		pool, found := c.pools[ippoolName]
+		if !found {
+			c.Unlock()
+			return fmt.Errorf("IPPool %s not found", ippoolName)
+		}
+		base := pool.IPPoolObject
+		c.Unlock()
+		var allocations []backend.Allocation
+		allocations, results = applyPoolOperations(ippoolName, base.Spec.Allocations, ops)
+		updated, err := c.store.UpdateIPPoolAllocations(ippoolName, base.ResourceVersion, allocations)
+		if err == nil {
+			c.Lock()
+			c.setLocked(updated)
+			if pool, found := c.pools[ippoolName]; found {
+				pool.inflight = nil
+				now := time.Now()
+				for _, op := range ops {
+					if op.remove {
+						pool.released[op.allocation.Index] = now
+					}
+				}
+			}
+			c.Unlock()
+			return nil
+		}
+		if errors.IsConflict(err) {
+			log.Printf("Conflict updating IPPool %s at %s, read latest", ippoolName, base.ResourceVersion)
+			if latest, getErr := c.store.GetIPPoolObject(ippoolName); getErr == nil {
+				c.set(latest)
+			}
+		}
+		return err
+	})
+	if err != nil {
+		log.Printf("Cannot update IPPool %s: %v", ippoolName, err)
+		c.Lock()
+		if pool, found := c.pools[ippoolName]; found {
+			pool.inflight = nil
+		}
+		c.Unlock()
+	}
+	log.Printf("Write %d operations to IPPool %s elapsed: %d us", len(ops), ippoolName, int64(time.Since(start)/time.Microsecond))
+	committed := func(op *poolOperation) bool {
+		return err == nil && results[op] == nil
+	}
+	if journalErr := c.journal.Resolve(ops, committed); journalErr != nil {
+		log.Printf("Cannot resolve allocation journal: %v", journalErr)
+	}
+	for _, op := range ops {
+		if err != nil {
+			op.done <- err
+		} else {
+			op.done <- results[op]
+		}
+	}
+}
+
+// applyPoolOperations returns allocations sorted by index after the operations
+// and the result of each operation; an add fails if the index is held by another pod
+func applyPoolOperations(ippoolName string, allocations []backend.Allocation, ops []*poolOperation) ([]backend.Allocation, map[*poolOperation]error) {
+	indexMap := make(map[int]backend.Allocation)
+	for _, allocation := range allocations {
+		indexMap[allocation.Index] = allocation
+	}
+	results := make(map[*poolOperation]error)
+	for _, op := range ops {
+		existing, found := indexMap[op.allocation.Index]
+		samePod := found && existing.Pod == op.allocation.Pod && existing.Namespace == op.allocation.Namespace
+		if op.remove {
+			// a late deallocation of the previous pod instance with the same name keeps the new allocation
+			if found && existing.IsOwnedBy(op.allocation.Pod, op.allocation.Namespace, op.allocation.PodUID, op.allocation.ContainerID) {
+				delete(indexMap, op.allocation.Index)
+			}
+			continue
+		}
+		if found && !samePod {
+			results[op] = errIndexConflict{ippoolName: ippoolName, allocation: op.allocation}
+			continue
+		}
+		// an allocation of the same pod at the index is replaced (e.g., reserved address)
+		indexMap[op.allocation.Index] = op.allocation
+	}
+	newAllocations := []backend.Allocation{}
+	for _, allocation := range indexMap {
+		newAllocations = append(newAllocations, allocation)
+	}
+	sort.Slice(newAllocations, func(i, j int) bool {
+		return newAllocations[i].Index < newAllocations[j].Index
+	})
+	return newAllocations, results
+}
+
+// Allocate computes allocations on the cached IPPools of the host and network and their release times
+// and waits until they are written.
+// IPPools whose allocated index is taken by an external update are allocated again on the latest view.
+func (c *IPPoolCache) Allocate(hostName, defName string, allocate func(map[string]backend.IPPoolType, map[string]map[int]time.Time) map[string]allocation) (map[string]allocation, map[string]backend.IPPoolType) {
+	committed := make(map[string]allocation)
+	committedSpecs := make(map[string]backend.IPPoolType)
+	blockedPools := c.replayBlockedPools()
+	for attempt := 0; attempt < MAX_ALLOCATE_ATTEMPTS; attempt++ {
+		if err := c.refresh(hostName, defName); err != nil {
+			log.Printf("Cannot list IPPool: %v", err)
+			break
+		}
+		c.Lock()
+		ippoolSpecMap := c.listLocked(hostName, defName)
+		if attempt == 0 && len(ippoolSpecMap) == 0 {
+			c.Unlock()
+			log.Printf("Unable to proceed allocation without ippool of %s on %s", defName, hostName)
+			break
+		}
+		for ippoolName := range committed {
+			delete(ippoolSpecMap, ippoolName)
+		}
+		for ippoolName := range blockedPools {
+			if _, found := ippoolSpecMap[ippoolName]; found && attempt == 0 {
+				log.Printf("Unable to allocate from %s before replaying journal", ippoolName)
+			}
+			delete(ippoolSpecMap, ippoolName)
+		}
+		newAllocations := allocate(ippoolSpecMap, c.releaseTimesLocked(ippoolSpecMap))
+		ops := make(map[string]*poolOperation)
+		for ippoolName, newAllocation := range newAllocations {
+			ops[ippoolName] = c.enqueueLocked(ippoolName, newAllocation.Allocation, false)
+		}
+		c.Unlock()
+
+		retryNeeded := false
+		for ippoolName, op := range ops {
+			err := <-op.done
+			if err == nil {
+				committed[ippoolName] = newAllocations[ippoolName]
+				committedSpecs[ippoolName] = ippoolSpecMap[ippoolName]
+				continue
+			}
+			log.Printf("Cannot allocate %v: %v", newAllocations[ippoolName].Allocation, err)
+			if _, isConflict := err.(errIndexConflict); isConflict {
+				retryNeeded = true
+			}
+		}
+		if !retryNeeded {
+			break
+		}
+	}
+	return committed, committedSpecs
+}
+
+// Deallocate removes allocations of the pod from the cached IPPools of the host and network and waits until they are written.
+// Allocations recorded with another pod UID or container ID are kept.
+func (c *IPPoolCache) Deallocate(hostName, defName, podName, podNamespace, podUID, containerID string) (map[string]backend.Allocation, map[string]backend.IPPoolType) {
+	removed := make(map[string]backend.Allocation)
+	removedSpecs := make(map[string]backend.IPPoolType)
+	blockedPools := c.replayBlockedPools()
+	if err := c.refresh(hostName, defName); err != nil {
+		log.Printf("Cannot list IPPool: %v", err)
+		return removed, removedSpecs
+	}
+	c.Lock()
+	ippoolSpecMap := c.listLocked(hostName, defName)
+	ops := make(map[string]*poolOperation)
+	for ippoolName, spec := range ippoolSpecMap {
+		if blockedPools[ippoolName] {
+			log.Printf("Unable to deallocate from %s before replaying journal", ippoolName)
+			continue
+		}
+		for _, allocation := range spec.Allocations {
+			if allocation.IsOwnedBy(podName, podNamespace, podUID, containerID) {
+				ops[ippoolName] = c.enqueueLocked(ippoolName, allocation, true)
+				break
+			}
+		}
+	}
+	c.Unlock()
+	for ippoolName, op := range ops {
+		if err := <-op.done; err != nil {
+			log.Printf("Cannot deallocate %v: %v", op.allocation, err)
+			continue
+		}
+		removed[ippoolName] = op.allocation
+		removedSpecs[ippoolName] = ippoolSpecMap[ippoolName]
+	}
+	return removed, removedSpecs
+}
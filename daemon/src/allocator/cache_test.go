@@ -0,0 +1,331 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+)
+
+const (
+	cacheTestHostName = "node-1"
+	cacheTestDefName  = "multinic-sample"
+)
+
+// fakeIPPoolStore keeps IPPools in memory and rejects updates at an old resource version
+// latency is added to each update to simulate API server round trip
+type fakeIPPoolStore struct {
+	sync.Mutex
+	pools   map[string]backend.IPPoolObject
+	version int
+	latency time.Duration
+	updates int
+	// unavailable simulates API server outage
+	unavailable bool
+	// unavailablePools simulates failures of updating specific IPPools
+	unavailablePools map[string]bool
+}
+
+func newFakeIPPoolStore(latency time.Duration, interfaceNames ...string) *fakeIPPoolStore {
+	store := &fakeIPPoolStore{pools: make(map[string]backend.IPPoolObject), latency: latency}
+	for i, interfaceName := range interfaceNames {
+		podCIDR := fmt.Sprintf("192.168.%d.0/22", i*4)
+		store.setAllocations(cacheTestDefName+"-"+interfaceName, backend.IPPoolType{
+			PodCIDR:          podCIDR,
+			VlanCIDR:         "192.168.0.0/16",
+			NetAttachDefName: cacheTestDefName,
+			HostName:         cacheTestHostName,
+			InterfaceName:    interfaceName,
+		})
+	}
+	return store
+}
+
+// setAllocations updates IPPool as an external writer
+func (s *fakeIPPoolStore) setAllocations(name string, spec backend.IPPoolType) {
+	s.Lock()
+	defer s.Unlock()
+	s.version += 1
+	s.pools[name] = backend.IPPoolObject{Name: name, ResourceVersion: strconv.Itoa(s.version), Spec: spec}
+}
+
+func (s *fakeIPPoolStore) ListIPPoolObject(listOptions metav1.ListOptions) ([]backend.IPPoolObject, error) {
+	s.Lock()
+	defer s.Unlock()
+	objects := []backend.IPPoolObject{}
+	for _, obj := range s.pools {
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
+
+func (s *fakeIPPoolStore) GetIPPoolObject(poolname string) (backend.IPPoolObject, error) {
+	s.Lock()
+	defer s.Unlock()
+	if s.unavailable || s.unavailablePools[poolname] {
+		return backend.IPPoolObject{}, errors.NewServiceUnavailable("API server is unavailable")
+	}
+	obj, found := s.pools[poolname]
+	if !found {
+		return obj, errors.NewNotFound(schema.GroupResource{Group: "multinic.fms.io", Resource: "ippools"}, poolname)
+	}
+	return obj, nil
+}
+
+func (s *fakeIPPoolStore) UpdateIPPoolAllocations(poolname string, resourceVersion string, allocations []backend.Allocation) (backend.IPPoolObject, error) {
+	time.Sleep(s.latency)
+	s.Lock()
+	defer s.Unlock()
+	if s.unavailable || s.unavailablePools[poolname] {
+		return backend.IPPoolObject{}, errors.NewServiceUnavailable("API server is unavailable")
+	}
+	obj, found := s.pools[poolname]
+	if !found {
+		return obj, errors.NewNotFound(schema.GroupResource{Group: "multinic.fms.io", Resource: "ippools"}, poolname)
+	}
+	if obj.ResourceVersion != resourceVersion {
+		return obj, errors.NewConflict(schema.GroupResource{Group: "multinic.fms.io", Resource: "ippools"}, poolname,
+			fmt.Errorf("resource version %s is not %s", resourceVersion, obj.ResourceVersion))
+	}
+	s.updates += 1
+	s.version += 1
+	obj.ResourceVersion = strconv.Itoa(s.version)
+	obj.Spec.Allocations = append([]backend.Allocation{}, allocations...)
+	s.pools[poolname] = obj
+	return obj, nil
+}
+
+// allocateByCache allocates an address for each interface of the pod through the cache
+func allocateByCache(c *IPPoolCache, podName string, interfaceNames []string) map[string]allocation {
+	return allocateRequestByCache(c, IPRequest{PodName: podName, PodNamespace: "default", InterfaceNames: interfaceNames})
+}
+
+// allocateRequestByCache allocates addresses of the request through the cache
+func allocateRequestByCache(c *IPPoolCache, req IPRequest) map[string]allocation {
+	newAllocations, _ := c.Allocate(cacheTestHostName, cacheTestDefName, func(ippoolSpecMap map[string]backend.IPPoolType, releaseTimes map[string]map[int]time.Time) map[string]allocation {
+		return allocateIP(req, ippoolSpecMap, releaseTimes, nil)
+	})
+	return newAllocations
+}
+
+// allocateConcurrently allocates addresses for podCount pods at the same time
+func allocateConcurrently(c *IPPoolCache, podCount int, interfaceNames []string) []map[string]allocation {
+	results := make([]map[string]allocation, podCount)
+	var wg sync.WaitGroup
+	for i := 0; i < podCount; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = allocateByCache(c, fmt.Sprintf("pod-%d", i), interfaceNames)
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
+
+var _ = Describe("Test IPPool Cache", func() {
+	interfaceNames := []string{"eth1", "eth2"}
+
+	It("allocates unique addresses and coalesces concurrent updates", func() {
+		podCount := 100
+		store := newFakeIPPoolStore(5*time.Millisecond, interfaceNames...)
+		c := NewIPPoolCache(store)
+		results := allocateConcurrently(c, podCount, interfaceNames)
+		addresses := make(map[string]bool)
+		for _, result := range results {
+			Expect(result).To(HaveLen(len(interfaceNames)))
+			for _, newAllocation := range result {
+				Expect(addresses).NotTo(HaveKey(newAllocation.Address))
+				addresses[newAllocation.Address] = true
+			}
+		}
+		for _, obj := range store.pools {
+			Expect(obj.Spec.Allocations).To(HaveLen(podCount))
+		}
+		Expect(store.updates).To(BeNumerically("<", podCount*len(interfaceNames)))
+	})
+
+	It("retries on conflict with external update", func() {
+		store := newFakeIPPoolStore(0, "eth1")
+		c := NewIPPoolCache(store)
+		Expect(c.refresh(cacheTestHostName, cacheTestDefName)).To(Succeed())
+		// stop reading through API server to keep the cache stale
+		c.hasSynced = func() bool { return true }
+		ippoolName := cacheTestDefName + "-eth1"
+		spec := store.pools[ippoolName].Spec
+		spec.Allocations = []backend.Allocation{{Pod: "other", Namespace: "default", Index: 1, Address: "192.168.0.1"}}
+		store.setAllocations(ippoolName, spec)
+
+		result := allocateByCache(c, "pod", []string{"eth1"})
+		Expect(result).To(HaveKey(ippoolName))
+		Expect(result[ippoolName].Address).To(Equal("192.168.0.2"))
+		Expect(store.pools[ippoolName].Spec.Allocations).To(HaveLen(2))
+		Expect(store.pools[ippoolName].Spec.Allocations[0].Pod).To(Equal("other"))
+	})
+
+	It("deallocates", func() {
+		store := newFakeIPPoolStore(0, interfaceNames...)
+		c := NewIPPoolCache(store)
+		allocateConcurrently(c, 3, interfaceNames)
+		removed, _ := c.Deallocate(cacheTestHostName, cacheTestDefName, "pod-1", "default", "", "")
+		Expect(removed).To(HaveLen(len(interfaceNames)))
+		for _, obj := range store.pools {
+			Expect(obj.Spec.Allocations).To(HaveLen(2))
+			for _, allocation := range obj.Spec.Allocations {
+				Expect(allocation.Pod).NotTo(Equal("pod-1"))
+			}
+		}
+	})
+
+	It("lists attached pods per interface", func() {
+		store := newFakeIPPoolStore(0, interfaceNames...)
+		c := NewIPPoolCache(store)
+		allocateConcurrently(c, 2, interfaceNames)
+		allocateByCache(c, "pod-eth1", []string{"eth1"})
+		attachedPods, err := c.GetAttachedPods(cacheTestHostName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attachedPods).To(Equal(map[string][]string{
+			"eth1": {"default/pod-0", "default/pod-1", "default/pod-eth1"},
+			"eth2": {"default/pod-0", "default/pod-1"},
+		}))
+	})
+
+	It("keeps allocation of new pod instance on late deallocation of previous one", func() {
+		store := newFakeIPPoolStore(0, "eth1")
+		c := NewIPPoolCache(store)
+		ippoolName := cacheTestDefName + "-eth1"
+		previous := IPRequest{PodName: "worker-0", PodNamespace: "default", PodUID: "uid-1", ContainerID: "c1", InterfaceNames: []string{"eth1"}}
+		current := IPRequest{PodName: "worker-0", PodNamespace: "default", PodUID: "uid-2", ContainerID: "c2", InterfaceNames: []string{"eth1"}}
+		Expect(allocateRequestByCache(c, previous)).To(HaveLen(1))
+		Expect(allocateRequestByCache(c, current)).To(HaveLen(1))
+		Expect(store.pools[ippoolName].Spec.Allocations).To(HaveLen(2))
+
+		removed, _ := c.Deallocate(cacheTestHostName, cacheTestDefName, "worker-0", "default", "uid-1", "c1")
+		Expect(removed).To(HaveKey(ippoolName))
+		Expect(removed[ippoolName].PodUID).To(Equal("uid-1"))
+		allocations := store.pools[ippoolName].Spec.Allocations
+		Expect(allocations).To(HaveLen(1))
+		Expect(allocations[0].PodUID).To(Equal("uid-2"))
+		Expect(allocations[0].ContainerID).To(Equal("c2"))
+
+		removed, _ = c.Deallocate(cacheTestHostName, cacheTestDefName, "worker-0", "default", "uid-1", "c1")
+		Expect(removed).To(BeEmpty())
+		Expect(store.pools[ippoolName].Spec.Allocations).To(HaveLen(1))
+	})
+
+	It("ignores stale update", func() {
+		c := NewIPPoolCache(newFakeIPPoolStore(0))
+		c.set(backend.IPPoolObject{Name: "pool", ResourceVersion: "10", Spec: backend.IPPoolType{PodCIDR: "192.168.0.0/24"}})
+		c.set(backend.IPPoolObject{Name: "pool", ResourceVersion: "9", Spec: backend.IPPoolType{PodCIDR: "192.168.1.0/24"}})
+		Expect(c.pools["pool"].Spec.PodCIDR).To(Equal("192.168.0.0/24"))
+		c.set(backend.IPPoolObject{Name: "pool", ResourceVersion: "11", Spec: backend.IPPoolType{PodCIDR: "192.168.2.0/24"}})
+		Expect(c.pools["pool"].Spec.PodCIDR).To(Equal("192.168.2.0/24"))
+	})
+
+	DescribeTable("applyPoolOperations", func(allocations []backend.Allocation, op *poolOperation, expectedIndexes []int, expectConflict bool) {
+		newAllocations, results := applyPoolOperations("pool", allocations, []*poolOperation{op})
+		indexes := []int{}
+		for _, allocation := range newAllocations {
+			indexes = append(indexes, allocation.Index)
+		}
+		Expect(indexes).To(Equal(expectedIndexes))
+		Expect(results[op] != nil).To(Equal(expectConflict))
+	},
+		Entry("add sorted", []backend.Allocation{{Pod: "a", Index: 3}}, &poolOperation{allocation: backend.Allocation{Pod: "b", Index: 1}},
+			[]int{1, 3}, false),
+		Entry("replace same pod", []backend.Allocation{{Pod: "a", Index: 3}}, &poolOperation{allocation: backend.Allocation{Pod: "a", Index: 3}},
+			[]int{3}, false),
+		Entry("conflict with other pod", []backend.Allocation{{Pod: "a", Index: 3}}, &poolOperation{allocation: backend.Allocation{Pod: "b", Index: 3}},
+			[]int{3}, true),
+		Entry("remove", []backend.Allocation{{Pod: "a", Index: 3}, {Pod: "b", Index: 4}}, &poolOperation{allocation: backend.Allocation{Pod: "a", Index: 3}, remove: true},
+			[]int{4}, false),
+		Entry("remove missing", []backend.Allocation{{Pod: "a", Index: 3}}, &poolOperation{allocation: backend.Allocation{Pod: "b", Index: 3}, remove: true},
+			[]int{3}, false),
+		Entry("remove other pod instance", []backend.Allocation{{Pod: "a", PodUID: "uid-2", Index: 3}},
+			&poolOperation{allocation: backend.Allocation{Pod: "a", PodUID: "uid-1", Index: 3}, remove: true},
+			[]int{3}, false),
+	)
+
+	DescribeTable("isNewerResourceVersion", func(incoming, current string, expected bool) {
+		Expect(isNewerResourceVersion(incoming, current)).To(Equal(expected))
+	},
+		Entry("empty current", "1", "", true),
+		Entry("newer", "10", "9", true),
+		Entry("older", "9", "10", false),
+		Entry("same", "10", "10", false),
+		Entry("opaque different", "b", "a", true),
+	)
+})
+
+// benchmarkConcurrentAllocation measures pods allocated per second when podCount pods are created at the same time on a node
+func benchmarkConcurrentAllocation(b *testing.B, podCount int, allocateAll func(store *fakeIPPoolStore, podCount int, interfaceNames []string)) {
+	interfaceNames := []string{"eth1", "eth2"}
+	updates := 0
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		store := newFakeIPPoolStore(time.Millisecond, interfaceNames...)
+		allocateAll(store, podCount, interfaceNames)
+		updates += store.updates
+	}
+	b.ReportMetric(float64(podCount*b.N)/time.Since(start).Seconds(), "pods/s")
+	b.ReportMetric(float64(updates)/float64(b.N), "updates/op")
+}
+
+// BenchmarkCachedAllocation allocates through IPPool cache with coalesced updates
+func BenchmarkCachedAllocation(b *testing.B) {
+	for _, podCount := range []int{100, 300, 500} {
+		b.Run(fmt.Sprintf("pods-%d", podCount), func(b *testing.B) {
+			benchmarkConcurrentAllocation(b, podCount, func(store *fakeIPPoolStore, podCount int, interfaceNames []string) {
+				allocateConcurrently(NewIPPoolCache(store), podCount, interfaceNames)
+			})
+		})
+	}
+}
+
+// BenchmarkSerializedAllocation lists and updates IPPools for each pod under a process-wide lock
+func BenchmarkSerializedAllocation(b *testing.B) {
+	for _, podCount := range []int{100, 300, 500} {
+		b.Run(fmt.Sprintf("pods-%d", podCount), func(b *testing.B) {
+			benchmarkConcurrentAllocation(b, podCount, func(store *fakeIPPoolStore, podCount int, interfaceNames []string) {
+				var lock sync.Mutex
+				var wg sync.WaitGroup
+				for i := 0; i < podCount; i++ {
+					wg.Add(1)
+					go func(i int) {
+						defer wg.Done()
+						lock.Lock()
+						defer lock.Unlock()
+						objects, _ := store.ListIPPoolObject(metav1.ListOptions{})
+						ippoolSpecMap := make(map[string]backend.IPPoolType)
+						resourceVersions := make(map[string]string)
+						for _, obj := range objects {
+							ippoolSpecMap[obj.Name] = obj.Spec
+							resourceVersions[obj.Name] = obj.ResourceVersion
+						}
+						newAllocations := allocateIP(IPRequest{PodName: fmt.Sprintf("pod-%d", i), PodNamespace: "default", InterfaceNames: interfaceNames}, ippoolSpecMap, nil, nil)
+						for ippoolName, newAllocation := range newAllocations {
+							allocations, _ := applyPoolOperations(ippoolName, ippoolSpecMap[ippoolName].Allocations,
+								[]*poolOperation{{allocation: newAllocation.Allocation}})
+							store.UpdateIPPoolAllocations(ippoolName, resourceVersions[ippoolName], allocations)
+						}
+					}(i)
+				}
+				wg.Wait()
+			})
+		})
+	}
+}
@@ -0,0 +1,300 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	ALLOCATION_GC_INTERVAL_ENV     = "ALLOCATION_GC_INTERVAL"
+	ALLOCATION_GC_GRACE_PERIOD_ENV = "ALLOCATION_GC_GRACE_PERIOD"
+	// DEFAULT_ALLOCATION_GC_INTERVAL is default interval in seconds to collect leaked allocations
+	DEFAULT_ALLOCATION_GC_INTERVAL = 60
+	// DEFAULT_ALLOCATION_GC_GRACE_PERIOD is default time in seconds an allocation must stay without its pod before reclaimed
+	DEFAULT_ALLOCATION_GC_GRACE_PERIOD = 120
+
+	RECLAIM_EVENT_REASON       = "AllocationReclaimed"
+	RECLAIMED_ALLOCATIONS_NAME = "multinic_daemon_reclaimed_allocations_total"
+
+	// reasons to reclaim allocation
+	POD_NOT_FOUND = "PodNotFound"
+	POD_COMPLETED = "PodCompleted"
+	POD_REPLACED  = "PodReplaced"
+)
+
+// getDurationEnv returns duration from the environment in seconds
+func getDurationEnv(env string, defaultSeconds int) time.Duration {
+	seconds := defaultSeconds
+	if val, found := os.LookupEnv(env); found && val != "" {
+		if secondsInt, err := strconv.Atoi(val); err == nil && secondsInt >= 0 {
+			seconds = secondsInt
+		} else {
+			log.Printf("invalid %s=%s, use default %d", env, val, defaultSeconds)
+		}
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// GetAllocationGCInterval returns collection interval from ALLOCATION_GC_INTERVAL (seconds), 0 disables the collection
+func GetAllocationGCInterval() time.Duration {
+	return getDurationEnv(ALLOCATION_GC_INTERVAL_ENV, DEFAULT_ALLOCATION_GC_INTERVAL)
+}
+
+// GetAllocationGCGracePeriod returns grace period from ALLOCATION_GC_GRACE_PERIOD (seconds)
+func GetAllocationGCGracePeriod() time.Duration {
+	return getDurationEnv(ALLOCATION_GC_GRACE_PERIOD_ENV, DEFAULT_ALLOCATION_GC_GRACE_PERIOD)
+}
+
+// reclaimCounter counts reclaimed allocations by network and reason
+type reclaimCounter struct {
+	sync.Mutex
+	counts map[[2]string]int
+}
+
+var reclaimedAllocations = &reclaimCounter{counts: make(map[[2]string]int)}
+
+func (r *reclaimCounter) inc(defName, reason string) {
+	r.Lock()
+	defer r.Unlock()
+	r.counts[[2]string{defName, reason}] += 1
+}
+
+func (r *reclaimCounter) get(defName, reason string) int {
+	r.Lock()
+	defer r.Unlock()
+	return r.counts[[2]string{defName, reason}]
+}
+
+// WriteMetrics writes the daemon metrics in Prometheus text format
+func WriteMetrics(w io.Writer) {
+	reclaimedAllocations.Lock()
+	defer reclaimedAllocations.Unlock()
+	keys := [][2]string{}
+	for key := range reclaimedAllocations.counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	fmt.Fprintf(w, "# HELP %s Number of allocations reclaimed by garbage collection of the daemon.\n", RECLAIMED_ALLOCATIONS_NAME)
+	fmt.Fprintf(w, "# TYPE %s counter\n", RECLAIMED_ALLOCATIONS_NAME)
+	for _, key := range keys {
+		fmt.Fprintf(w, "%s{network=%q,reason=%q} %d\n", RECLAIMED_ALLOCATIONS_NAME, key[0], key[1], reclaimedAllocations.counts[key])
+	}
+}
+
+// AllocationGC reclaims allocations of the host whose pods are gone for longer than the grace period
+type AllocationGC struct {
+	cache        *IPPoolCache
+	reservations *IPReservationCache
+	hostName     string
+	gracePeriod  time.Duration
+	podLister    corelisters.PodLister
+	// events reports reclaims on the pods, nil to skip
+	events *backend.PodEventHandler
+	// orphans keeps when the allocation is first seen without its pod
+	orphans map[string]time.Time
+	trigger chan struct{}
+	now     func() time.Time
+}
+
+// NewAllocationGC returns a collector of the cached IPPools of the host,
+// allocations holding addresses reserved to their pods are never collected
+func NewAllocationGC(c *IPPoolCache, reservations *IPReservationCache, hostName string, gracePeriod time.Duration, podLister corelisters.PodLister, clientset kubernetes.Interface) *AllocationGC {
+	gc := &AllocationGC{
+		cache:        c,
+		reservations: reservations,
+		hostName:     hostName,
+		gracePeriod:  gracePeriod,
+		podLister:    podLister,
+		orphans:      make(map[string]time.Time),
+		trigger:      make(chan struct{}, 1),
+		now:          time.Now,
+	}
+	if clientset != nil {
+		gc.events = backend.NewPodEventHandler(clientset, hostName)
+	}
+	return gc
+}
+
+// StartAllocationGC watches pods bound to the host and collects leaked allocations every interval
+// and whenever a pod is deleted
+func StartAllocationGC(clientset kubernetes.Interface, hostName string, interval, gracePeriod time.Duration, stopCh <-chan struct{}) {
+	if interval == 0 {
+		log.Println("Allocation garbage collection is disabled")
+		return
+	}
+	factory := informers.NewSharedInformerFactoryWithOptions(clientset, 0, informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+		options.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", hostName).String()
+	}))
+	podInformer := factory.Core().V1().Pods()
+	gc := NewAllocationGC(IppoolCache, IpreservationCache, hostName, gracePeriod, podInformer.Lister(), clientset)
+	podInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			gc.Trigger()
+		},
+	})
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, podInformer.Informer().HasSynced) {
+		log.Println("Pod cache is not synced, allocation garbage collection is not started")
+		return
+	}
+	log.Printf("Start allocation garbage collection every %v with grace period %v", interval, gracePeriod)
+	gc.Run(interval, stopCh)
+}
+
+// Trigger requests a collection without waiting for the next interval
+func (gc *AllocationGC) Trigger() {
+	select {
+	case gc.trigger <- struct{}{}:
+	default:
+	}
+}
+
+// Run collects leaked allocations until stopped
+func (gc *AllocationGC) Run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		case <-gc.trigger:
+		}
+		gc.Collect()
+	}
+}
+
+// getOrphanReason returns why the allocation has no running pod, empty if the pod is running
+func (gc *AllocationGC) getOrphanReason(allocation backend.Allocation) string {
+	pod, err := gc.podLister.Pods(allocation.Namespace).Get(allocation.Pod)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return POD_NOT_FOUND
+		}
+		return ""
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		return POD_COMPLETED
+	}
+	if !allocation.IsOwnedBy(pod.GetName(), pod.GetNamespace(), string(pod.GetUID()), "") {
+		return POD_REPLACED
+	}
+	return ""
+}
+
+func getOrphanKey(ippoolName string, allocation backend.Allocation) string {
+	return fmt.Sprintf("%s/%d/%s/%s/%s", ippoolName, allocation.Index, allocation.Namespace, allocation.Pod, allocation.PodUID)
+}
+
+// Collect reclaims allocations whose pods have been gone longer than the grace period
+// and returns the number of reclaimed allocations
+func (gc *AllocationGC) Collect() int {
+	if !gc.cache.synced() {
+		log.Println("Skip allocation garbage collection until IPPool cache is synced")
+		return 0
+	}
+	blockedPools := gc.cache.replayBlockedPools()
+	gc.cache.Lock()
+	ippoolSpecMap := gc.cache.listLocked(gc.hostName, "")
+	gc.cache.Unlock()
+
+	now := gc.now()
+	orphanKeys := make(map[string]bool)
+	reclaimed := 0
+	// skipped IPPools keep their orphans for the next collection
+	skippedPools := make(map[string]bool)
+	reservationMap := make(map[string][]backend.IPReservationType)
+	for ippoolName, spec := range ippoolSpecMap {
+		if blockedPools[ippoolName] {
+			log.Printf("Skip collecting %s before replaying journal", ippoolName)
+			skippedPools[ippoolName] = true
+			continue
+		}
+		reservations, found := reservationMap[spec.NetAttachDefName]
+		if !found && gc.reservations != nil {
+			var err error
+			reservations, err = gc.reservations.List(spec.NetAttachDefName)
+			if err != nil {
+				log.Printf("Skip collecting %s, cannot list IPReservation: %v", ippoolName, err)
+				skippedPools[ippoolName] = true
+				continue
+			}
+			reservationMap[spec.NetAttachDefName] = reservations
+		}
+		for _, allocation := range spec.Allocations {
+			if backend.IsReservedAllocation(reservations, spec.InterfaceName, allocation) {
+				continue
+			}
+			reason := gc.getOrphanReason(allocation)
+			if reason == "" {
+				continue
+			}
+			key := getOrphanKey(ippoolName, allocation)
+			orphanKeys[key] = true
+			firstSeen, found := gc.orphans[key]
+			if !found {
+				firstSeen = now
+				gc.orphans[key] = now
+				log.Printf("Found allocation %s of %s/%s in %s without running pod (%s)", allocation.Address,
+					allocation.Namespace, allocation.Pod, ippoolName, reason)
+			}
+			if now.Sub(firstSeen) < gc.gracePeriod {
+				continue
+			}
+			if err := gc.cache.Release(ippoolName, allocation); err != nil {
+				log.Printf("Cannot reclaim %v: %v", allocation, err)
+				continue
+			}
+			delete(gc.orphans, key)
+			reclaimed += 1
+			gc.report(ippoolName, spec, allocation, reason)
+		}
+	}
+	// pods come back or allocations are released by CNI
+	for key := range gc.orphans {
+		if !orphanKeys[key] && !skippedPools[strings.SplitN(key, "/", 2)[0]] {
+			delete(gc.orphans, key)
+		}
+	}
+	return reclaimed
+}
+
+// report emits an event on the pod of the allocation and counts the reclaimed allocation
+func (gc *AllocationGC) report(ippoolName string, spec backend.IPPoolType, allocation backend.Allocation, reason string) {
+	message := fmt.Sprintf("Reclaimed %s of %s/%s on %s in %s (%s)", allocation.Address, allocation.Namespace, allocation.Pod, spec.InterfaceName, ippoolName, reason)
+	log.Println(message)
+	reclaimedAllocations.inc(spec.NetAttachDefName, reason)
+	if gc.events == nil {
+		return
+	}
+	if err := gc.events.EmitWithUID(allocation.Pod, allocation.Namespace, allocation.PodUID, corev1.EventTypeNormal, RECLAIM_EVENT_REASON, message); err != nil {
+		log.Printf("Cannot create event %s: %v", message, err)
+	}
+}
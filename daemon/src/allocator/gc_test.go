@@ -0,0 +1,180 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"bytes"
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fakeIPReservationStore lists the reservations of the network
+type fakeIPReservationStore []backend.IPReservationType
+
+func (s fakeIPReservationStore) ListIPReservation(network string) ([]backend.IPReservationType, error) {
+	reservations := []backend.IPReservationType{}
+	for _, reservation := range s {
+		if reservation.Spec.Network == network {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
+
+var _ = Describe("Test Allocation GC", func() {
+	ippoolName := cacheTestDefName + "-eth1"
+	gracePeriod := 2 * time.Minute
+
+	var (
+		store     *fakeIPPoolStore
+		podStore  cache.Indexer
+		clientset *fake.Clientset
+		gc        *AllocationGC
+		now       time.Time
+	)
+
+	addPod := func(name, uid string, phase corev1.PodPhase) {
+		podStore.Add(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", UID: types.UID(uid)},
+			Spec:       corev1.PodSpec{NodeName: cacheTestHostName},
+			Status:     corev1.PodStatus{Phase: phase},
+		})
+	}
+
+	setAllocations := func(allocations ...backend.Allocation) {
+		spec := store.pools[ippoolName].Spec
+		spec.Allocations = allocations
+		store.setAllocations(ippoolName, spec)
+		// read the latest IPPool and take it as synced by informer
+		gc.cache.hasSynced = func() bool { return false }
+		Expect(gc.cache.refresh(cacheTestHostName, cacheTestDefName)).To(Succeed())
+		gc.cache.hasSynced = func() bool { return true }
+	}
+
+	getPods := func() []string {
+		pods := []string{}
+		for _, allocation := range store.pools[ippoolName].Spec.Allocations {
+			pods = append(pods, allocation.Pod)
+		}
+		return pods
+	}
+
+	BeforeEach(func() {
+		store = newFakeIPPoolStore(0, "eth1")
+		podStore = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+		clientset = fake.NewSimpleClientset()
+		c := NewIPPoolCache(store)
+		gc = NewAllocationGC(c, nil, cacheTestHostName, gracePeriod, corelisters.NewPodLister(podStore), clientset)
+		now = time.Now()
+		gc.now = func() time.Time { return now }
+	})
+
+	It("reclaims allocation of deleted pod after grace period", func() {
+		addPod("running", "uid-1", corev1.PodRunning)
+		setAllocations(
+			backend.Allocation{Pod: "running", Namespace: "default", PodUID: "uid-1", Index: 1, Address: "192.168.0.1"},
+			backend.Allocation{Pod: "deleted", Namespace: "team-a", PodUID: "uid-2", Index: 2, Address: "192.168.0.2"},
+		)
+		reclaimedBefore := reclaimedAllocations.get(cacheTestDefName, POD_NOT_FOUND)
+
+		Expect(gc.Collect()).To(Equal(0))
+		Expect(getPods()).To(Equal([]string{"running", "deleted"}))
+
+		now = now.Add(gracePeriod)
+		Expect(gc.Collect()).To(Equal(1))
+		Expect(getPods()).To(Equal([]string{"running"}))
+		Expect(reclaimedAllocations.get(cacheTestDefName, POD_NOT_FOUND)).To(Equal(reclaimedBefore + 1))
+
+		// event is reported on the deleted pod instance in its namespace
+		events, err := clientset.CoreV1().Events("team-a").List(context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events.Items).To(HaveLen(1))
+		Expect(events.Items[0].Reason).To(Equal(RECLAIM_EVENT_REASON))
+		Expect(events.Items[0].InvolvedObject.Kind).To(Equal("Pod"))
+		Expect(events.Items[0].InvolvedObject.Name).To(Equal("deleted"))
+		Expect(events.Items[0].InvolvedObject.UID).To(BeEquivalentTo("uid-2"))
+		Expect(events.Items[0].Message).To(ContainSubstring("192.168.0.2"))
+		Expect(events.Items[0].Message).To(ContainSubstring(ippoolName))
+		events, err = clientset.CoreV1().Events(metav1.NamespaceDefault).List(context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(events.Items).To(BeEmpty())
+
+		buf := &bytes.Buffer{}
+		WriteMetrics(buf)
+		Expect(buf.String()).To(ContainSubstring(`multinic_daemon_reclaimed_allocations_total{network="multinic-sample",reason="PodNotFound"}`))
+	})
+
+	DescribeTable("reclaims allocation without running pod", func(phase corev1.PodPhase, uid string, expectedReason string) {
+		addPod("pod", uid, phase)
+		setAllocations(backend.Allocation{Pod: "pod", Namespace: "default", PodUID: "uid-1", Index: 1, Address: "192.168.0.1"})
+		gc.gracePeriod = 0
+		reclaimedBefore := reclaimedAllocations.get(cacheTestDefName, expectedReason)
+		reclaimed := gc.Collect()
+		if expectedReason == "" {
+			Expect(reclaimed).To(Equal(0))
+			Expect(getPods()).To(HaveLen(1))
+			return
+		}
+		Expect(reclaimed).To(Equal(1))
+		Expect(getPods()).To(BeEmpty())
+		Expect(reclaimedAllocations.get(cacheTestDefName, expectedReason)).To(Equal(reclaimedBefore + 1))
+	},
+		Entry("running pod", corev1.PodRunning, "uid-1", ""),
+		Entry("completed pod", corev1.PodSucceeded, "uid-1", POD_COMPLETED),
+		Entry("failed pod", corev1.PodFailed, "uid-1", POD_COMPLETED),
+		Entry("replaced pod", corev1.PodRunning, "uid-2", POD_REPLACED),
+	)
+
+	It("resets grace period when pod comes back", func() {
+		setAllocations(backend.Allocation{Pod: "late", Namespace: "default", PodUID: "uid-1", Index: 1, Address: "192.168.0.1"})
+		Expect(gc.Collect()).To(Equal(0))
+		// pod is seen by the informer after the allocation
+		addPod("late", "uid-1", corev1.PodRunning)
+		now = now.Add(gracePeriod)
+		Expect(gc.Collect()).To(Equal(0))
+		Expect(gc.orphans).To(BeEmpty())
+		Expect(getPods()).To(Equal([]string{"late"}))
+	})
+
+	It("keeps allocation reserved to the deleted pod", func() {
+		gc.reservations = NewIPReservationCache(fakeIPReservationStore{{
+			Name:      "reserved",
+			Namespace: "default",
+			Spec: backend.IPReservationSpec{
+				Network:   cacheTestDefName,
+				PodName:   "reserved",
+				Addresses: []backend.ReservedAddress{{InterfaceName: "eth1", Address: "192.168.0.1"}, {Address: "192.168.0.2"}},
+			},
+		}})
+		setAllocations(
+			backend.Allocation{Pod: "reserved", Namespace: "default", PodUID: "uid-1", Index: 1, Address: "192.168.0.1"},
+			backend.Allocation{Pod: "deleted", Namespace: "default", PodUID: "uid-2", Index: 2, Address: "192.168.0.2"},
+		)
+		gc.gracePeriod = 0
+		Expect(gc.Collect()).To(Equal(1))
+		Expect(getPods()).To(Equal([]string{"reserved"}))
+	})
+
+	It("skips collection until IPPool cache is synced", func() {
+		spec := store.pools[ippoolName].Spec
+		spec.Allocations = []backend.Allocation{{Pod: "deleted", Namespace: "default", Index: 1, Address: "192.168.0.1"}}
+		store.setAllocations(ippoolName, spec)
+		gc.gracePeriod = 0
+		Expect(gc.Collect()).To(Equal(0))
+		Expect(getPods()).To(HaveLen(1))
+	})
+})
@@ -9,9 +9,14 @@ package allocator
 type IPRequest struct {
 	PodName          string   `json:"pod"`
 	PodNamespace     string   `json:"namespace"`
+	PodUID           string   `json:"podUID,omitempty"`
+	ContainerID      string   `json:"containerID,omitempty"`
 	HostName         string   `json:"host"`
 	NetAttachDefName string   `json:"def"`
 	InterfaceNames   []string `json:"masters"`
+	// AllocationStrategy and QuarantineSeconds are set from IPAM config of the network
+	AllocationStrategy string `json:"allocationStrategy,omitempty"`
+	QuarantineSeconds  int    `json:"quarantineSeconds,omitempty"`
 }
 type IPResponse struct {
 	InterfaceName string `json:"interface"`
@@ -0,0 +1,386 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+)
+
+const (
+	ALLOCATION_JOURNAL_PATH_ENV = "ALLOCATION_JOURNAL_PATH"
+	DEFAULT_ALLOCATION_JOURNAL  = "/var/lib/multi-nic/allocation.journal"
+	// JOURNAL_COMPACT_RECORDS is number of records after which a journal without unresolved records is truncated
+	JOURNAL_COMPACT_RECORDS = 1000
+
+	JOURNAL_PHASE_INTENT = "intent"
+	JOURNAL_PHASE_COMMIT = "commit"
+	JOURNAL_PHASE_ABORT  = "abort"
+	// JOURNAL_PHASE_RELEASED keeps when the index of IPPool was last released across compaction
+	JOURNAL_PHASE_RELEASED = "released"
+
+	MOUNT_INFO_PATH = "/proc/self/mountinfo"
+)
+
+// journalRecord is a line of allocation journal
+// intent records the operation before IPPool is updated,
+// commit or abort with the same sequence number resolves the intent,
+// a committed deallocation is the release time of the index used by the lru strategy
+type journalRecord struct {
+	Seq        uint64             `json:"seq"`
+	Phase      string             `json:"phase"`
+	IPPool     string             `json:"ippool,omitempty"`
+	Remove     bool               `json:"remove,omitempty"`
+	Allocation backend.Allocation `json:"allocation,omitempty"`
+	Time       time.Time          `json:"time"`
+}
+
+// AllocationJournal is a write-ahead journal of allocate and deallocate operations on the host
+// unresolved intents read at start-up must be replayed against IPPools before a new operation
+type AllocationJournal struct {
+	sync.Mutex
+	path       string
+	file       *os.File
+	nextSeq    uint64
+	records    int
+	pending    map[uint64]journalRecord
+	unreplayed []journalRecord
+	// released keeps when each index of IPPool was last released
+	released map[string]map[int]time.Time
+}
+
+// GetAllocationJournalPath returns journal path from ALLOCATION_JOURNAL_PATH
+func GetAllocationJournalPath() string {
+	if val, found := os.LookupEnv(ALLOCATION_JOURNAL_PATH_ENV); found && val != "" {
+		return val
+	}
+	return DEFAULT_ALLOCATION_JOURNAL
+}
+
+// IsOnHostMount returns true if the path is under a mount point other than the container root filesystem
+// according to mountinfo (e.g., hostPath volume), a journal on the container root is lost with the container
+func IsOnHostMount(path, mountInfoPath string) (bool, error) {
+	file, err := os.Open(mountInfoPath)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	path = filepath.Clean(path)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		// mount ID, parent ID, major:minor, root, mount point, ...
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		mountPoint := filepath.Clean(fields[4])
+		if mountPoint == "/" {
+			continue
+		}
+		if path == mountPoint || strings.HasPrefix(path, mountPoint+"/") {
+			return true, nil
+		}
+	}
+	return false, scanner.Err()
+}
+
+// OpenAllocationJournal reads unresolved intents from the journal and opens it for append
+func OpenAllocationJournal(path string) (*AllocationJournal, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, err
+	}
+	j := &AllocationJournal{
+		path:     path,
+		nextSeq:  1,
+		pending:  make(map[uint64]journalRecord),
+		released: make(map[string]map[int]time.Time),
+	}
+	intents := make(map[uint64]journalRecord)
+	if file, err := os.Open(path); err == nil {
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var record journalRecord
+			if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+				// the last line can be partially written at crash
+				log.Printf("Skip invalid journal record %q: %v", scanner.Text(), err)
+				continue
+			}
+			if record.Seq >= j.nextSeq {
+				j.nextSeq = record.Seq + 1
+			}
+			switch record.Phase {
+			case JOURNAL_PHASE_INTENT:
+				intents[record.Seq] = record
+			case JOURNAL_PHASE_RELEASED:
+				j.setReleasedLocked(record.IPPool, record.Allocation.Index, record.Time)
+			default:
+				if intent, found := intents[record.Seq]; found && intent.Remove && record.Phase == JOURNAL_PHASE_COMMIT {
+					j.setReleasedLocked(intent.IPPool, intent.Allocation.Index, record.Time)
+				}
+				delete(intents, record.Seq)
+			}
+		}
+		file.Close()
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, record := range intents {
+		j.unreplayed = append(j.unreplayed, record)
+	}
+	sort.Slice(j.unreplayed, func(i, k int) bool {
+		return j.unreplayed[i].Seq < j.unreplayed[k].Seq
+	})
+	if err := j.rewriteLocked(j.unreplayed); err != nil {
+		return nil, err
+	}
+	log.Printf("Open allocation journal %s with %d unresolved records", path, len(j.unreplayed))
+	return j, nil
+}
+
+// setReleasedLocked keeps the release time of the index if it is the latest one
+func (j *AllocationJournal) setReleasedLocked(ippoolName string, index int, releaseTime time.Time) {
+	if _, found := j.released[ippoolName]; !found {
+		j.released[ippoolName] = make(map[int]time.Time)
+	}
+	if releaseTime.After(j.released[ippoolName][index]) {
+		j.released[ippoolName][index] = releaseTime
+	}
+}
+
+// releasedRecordsLocked returns records of the release times
+func (j *AllocationJournal) releasedRecordsLocked() []journalRecord {
+	records := []journalRecord{}
+	for ippoolName, releaseTimes := range j.released {
+		for index, releaseTime := range releaseTimes {
+			records = append(records, journalRecord{
+				Phase:      JOURNAL_PHASE_RELEASED,
+				IPPool:     ippoolName,
+				Allocation: backend.Allocation{Index: index},
+				Time:       releaseTime,
+			})
+		}
+	}
+	sort.Slice(records, func(i, k int) bool {
+		if records[i].IPPool != records[k].IPPool {
+			return records[i].IPPool < records[k].IPPool
+		}
+		return records[i].Allocation.Index < records[k].Allocation.Index
+	})
+	return records
+}
+
+// rewriteLocked replaces the journal with the given records and the release times,
+// only the given records count toward compaction
+func (j *AllocationJournal) rewriteLocked(records []journalRecord) error {
+	tmpPath := j.path + ".tmp"
+	file, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err = writeRecords(file, append(j.releasedRecordsLocked(), records...)); err != nil {
+		file.Close()
+		return err
+	}
+	file.Close()
+	if err = os.Rename(tmpPath, j.path); err != nil {
+		return err
+	}
+	if j.file != nil {
+		j.file.Close()
+	}
+	j.file, err = os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0644)
+	j.records = len(records)
+	return err
+}
+
+// writeRecords writes records as JSON lines and syncs to disk
+func writeRecords(file *os.File, records []journalRecord) error {
+	writer := bufio.NewWriter(file)
+	for _, record := range records {
+		line, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+		writer.Write(line)
+		writer.WriteByte('\n')
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Intent records operations of the IPPool before they are written, all in a single sync
+func (j *AllocationJournal) Intent(ippoolName string, ops []*poolOperation) error {
+	if j == nil {
+		return nil
+	}
+	j.Lock()
+	defer j.Unlock()
+	now := time.Now()
+	records := []journalRecord{}
+	for _, op := range ops {
+		op.seq = j.nextSeq
+		j.nextSeq += 1
+		records = append(records, journalRecord{
+			Seq:        op.seq,
+			Phase:      JOURNAL_PHASE_INTENT,
+			IPPool:     ippoolName,
+			Remove:     op.remove,
+			Allocation: op.allocation,
+			Time:       now,
+		})
+	}
+	if err := writeRecords(j.file, records); err != nil {
+		return err
+	}
+	for _, record := range records {
+		j.pending[record.Seq] = record
+	}
+	j.records += len(records)
+	return nil
+}
+
+// Resolve records whether each operation is written to IPPool
+func (j *AllocationJournal) Resolve(ops []*poolOperation, committed func(op *poolOperation) bool) error {
+	if j == nil {
+		return nil
+	}
+	j.Lock()
+	defer j.Unlock()
+	now := time.Now()
+	records := []journalRecord{}
+	for _, op := range ops {
+		if _, found := j.pending[op.seq]; !found {
+			continue
+		}
+		phase := JOURNAL_PHASE_ABORT
+		if committed(op) {
+			phase = JOURNAL_PHASE_COMMIT
+		}
+		records = append(records, journalRecord{Seq: op.seq, Phase: phase, Time: now})
+	}
+	if err := writeRecords(j.file, records); err != nil {
+		return err
+	}
+	for _, record := range records {
+		if intent := j.pending[record.Seq]; intent.Remove && record.Phase == JOURNAL_PHASE_COMMIT {
+			j.setReleasedLocked(intent.IPPool, intent.Allocation.Index, now)
+		}
+		delete(j.pending, record.Seq)
+	}
+	j.records += len(records)
+	if len(j.pending) == 0 && len(j.unreplayed) == 0 && j.records >= JOURNAL_COMPACT_RECORDS {
+		return j.rewriteLocked(nil)
+	}
+	return nil
+}
+
+// getUnreplayed returns unresolved intents left by the previous daemon
+func (j *AllocationJournal) getUnreplayed() []journalRecord {
+	if j == nil {
+		return nil
+	}
+	j.Lock()
+	defer j.Unlock()
+	return append([]journalRecord{}, j.unreplayed...)
+}
+
+// getUnreplayedPools returns IPPools having unresolved intents left by the previous daemon
+func (j *AllocationJournal) getUnreplayedPools() map[string]bool {
+	ippoolNames := make(map[string]bool)
+	for _, record := range j.getUnreplayed() {
+		ippoolNames[record.IPPool] = true
+	}
+	return ippoolNames
+}
+
+// getReleaseTimes returns when each index of the IPPool was last released
+func (j *AllocationJournal) getReleaseTimes(ippoolName string) map[int]time.Time {
+	releaseTimes := make(map[int]time.Time)
+	if j == nil {
+		return releaseTimes
+	}
+	j.Lock()
+	defer j.Unlock()
+	for index, releaseTime := range j.released[ippoolName] {
+		releaseTimes[index] = releaseTime
+	}
+	return releaseTimes
+}
+
+// forgetReleases drops release times of the deleted IPPool at the next compaction
+func (j *AllocationJournal) forgetReleases(ippoolName string) {
+	if j == nil {
+		return
+	}
+	j.Lock()
+	defer j.Unlock()
+	delete(j.released, ippoolName)
+}
+
+// markReplayed drops replayed intents and keeps the rest for the next replay
+func (j *AllocationJournal) markReplayed(remains []journalRecord) error {
+	j.Lock()
+	defer j.Unlock()
+	j.unreplayed = remains
+	records := append([]journalRecord{}, remains...)
+	for _, record := range j.pending {
+		records = append(records, record)
+	}
+	sort.Slice(records, func(i, k int) bool {
+		return records[i].Seq < records[k].Seq
+	})
+	return j.rewriteLocked(records)
+}
+
+// Close closes the journal file
+func (j *AllocationJournal) Close() error {
+	if j == nil {
+		return nil
+	}
+	j.Lock()
+	defer j.Unlock()
+	return j.file.Close()
+}
+
+// getReplayOperations returns operations to replay unresolved intents:
+// an allocation whose response may not have reached CNI is rolled back,
+// and a deallocation is rolled forward
+func getReplayOperations(records []journalRecord) map[string][]*poolOperation {
+	opsMap := make(map[string][]*poolOperation)
+	for _, record := range records {
+		opsMap[record.IPPool] = append(opsMap[record.IPPool], &poolOperation{
+			allocation: record.Allocation,
+			remove:     true,
+			seq:        record.Seq,
+		})
+	}
+	return opsMap
+}
+
+// describeReplay returns a log message of the replayed record
+func describeReplay(record journalRecord) string {
+	action := "roll back allocation"
+	if record.Remove {
+		action = "roll forward deallocation"
+	}
+	return fmt.Sprintf("%s of %s/%s (%s) in %s", action, record.Allocation.Namespace, record.Allocation.Pod,
+		record.Allocation.Address, record.IPPool)
+}
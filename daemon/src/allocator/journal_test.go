@@ -0,0 +1,178 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+)
+
+var _ = Describe("Test Allocation Journal", func() {
+	var journalPath string
+	ippoolName := cacheTestDefName + "-eth1"
+
+	BeforeEach(func() {
+		journalPath = filepath.Join(GinkgoT().TempDir(), "multi-nic", "allocation.journal")
+	})
+
+	openJournal := func() *AllocationJournal {
+		journal, err := OpenAllocationJournal(journalPath)
+		Expect(err).NotTo(HaveOccurred())
+		return journal
+	}
+
+	// writeIntents leaves intents of the operations unresolved as if the daemon crashed
+	writeIntents := func(ops ...*poolOperation) {
+		journal := openJournal()
+		Expect(journal.Intent(ippoolName, ops)).To(Succeed())
+		Expect(journal.Close()).To(Succeed())
+	}
+
+	It("keeps unresolved intents", func() {
+		committedOp := &poolOperation{allocation: backend.Allocation{Pod: "pod-a", Namespace: "default", Index: 1}}
+		crashedOp := &poolOperation{allocation: backend.Allocation{Pod: "pod-b", Namespace: "default", Index: 2}}
+		journal := openJournal()
+		Expect(journal.Intent(ippoolName, []*poolOperation{committedOp, crashedOp})).To(Succeed())
+		Expect(journal.Resolve([]*poolOperation{committedOp}, func(*poolOperation) bool { return true })).To(Succeed())
+		Expect(journal.Close()).To(Succeed())
+
+		journal = openJournal()
+		unreplayed := journal.getUnreplayed()
+		Expect(unreplayed).To(HaveLen(1))
+		Expect(unreplayed[0].Allocation.Pod).To(Equal("pod-b"))
+		Expect(unreplayed[0].IPPool).To(Equal(ippoolName))
+	})
+
+	It("skips partially written record", func() {
+		writeIntents(&poolOperation{allocation: backend.Allocation{Pod: "pod-a", Namespace: "default", Index: 1}})
+		file, err := os.OpenFile(journalPath, os.O_APPEND|os.O_WRONLY, 0644)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = file.WriteString(`{"seq":2,"phase":"int`)
+		Expect(err).NotTo(HaveOccurred())
+		file.Close()
+		Expect(openJournal().getUnreplayed()).To(HaveLen(1))
+	})
+
+	It("replays unresolved intents against IPPool", func() {
+		store := newFakeIPPoolStore(0, "eth1")
+		spec := store.pools[ippoolName].Spec
+		spec.Allocations = []backend.Allocation{
+			{Pod: "pod-a", Namespace: "default", Index: 1, Address: "192.168.0.1"},
+			{Pod: "pod-b", Namespace: "default", Index: 2, Address: "192.168.0.2"},
+			{Pod: "pod-c", Namespace: "default", Index: 3, Address: "192.168.0.3"},
+		}
+		store.setAllocations(ippoolName, spec)
+		writeIntents(
+			// allocation written to IPPool but not returned to CNI
+			&poolOperation{allocation: spec.Allocations[0]},
+			// deallocation not written to IPPool
+			&poolOperation{allocation: spec.Allocations[1], remove: true},
+			// allocation not written to IPPool
+			&poolOperation{allocation: backend.Allocation{Pod: "pod-d", Namespace: "default", Index: 4, Address: "192.168.0.4"}},
+		)
+
+		c := NewIPPoolCache(store)
+		c.SetJournal(openJournal())
+		Expect(c.ReplayJournal()).To(Succeed())
+		Expect(store.pools[ippoolName].Spec.Allocations).To(Equal([]backend.Allocation{spec.Allocations[2]}))
+		Expect(openJournal().getUnreplayed()).To(BeEmpty())
+	})
+
+	It("keeps intents and blocks allocation until replayed", func() {
+		store := newFakeIPPoolStore(0, "eth1")
+		writeIntents(&poolOperation{allocation: backend.Allocation{Pod: "pod-a", Namespace: "default", Index: 1, Address: "192.168.0.1"}})
+		c := NewIPPoolCache(store)
+		c.backoff.Steps = 1
+		c.SetJournal(openJournal())
+
+		store.unavailable = true
+		Expect(c.ReplayJournal()).NotTo(Succeed())
+		Expect(allocateByCache(c, "pod-b", []string{"eth1"})).To(BeEmpty())
+		Expect(c.journal.getUnreplayed()).To(HaveLen(1))
+
+		store.unavailable = false
+		Expect(allocateByCache(c, "pod-b", []string{"eth1"})).To(HaveLen(1))
+		Expect(c.journal.getUnreplayed()).To(BeEmpty())
+	})
+
+	It("blocks only IPPools with unreplayed intents", func() {
+		store := newFakeIPPoolStore(0, "eth1", "eth2")
+		writeIntents(&poolOperation{allocation: backend.Allocation{Pod: "pod-a", Namespace: "default", Index: 1, Address: "192.168.0.1"}})
+		c := NewIPPoolCache(store)
+		c.backoff.Steps = 1
+		c.SetJournal(openJournal())
+
+		store.unavailablePools = map[string]bool{ippoolName: true}
+		newAllocations := allocateByCache(c, "pod-b", []string{"eth1", "eth2"})
+		Expect(newAllocations).To(HaveLen(1))
+		Expect(newAllocations).To(HaveKey(cacheTestDefName + "-eth2"))
+		Expect(c.journal.getUnreplayedPools()).To(Equal(map[string]bool{ippoolName: true}))
+
+		removed, _ := c.Deallocate(cacheTestHostName, cacheTestDefName, "pod-b", "default", "", "")
+		Expect(removed).To(HaveKey(cacheTestDefName + "-eth2"))
+	})
+
+	It("checks journal on host mount", func() {
+		mountInfoPath := filepath.Join(GinkgoT().TempDir(), "mountinfo")
+		Expect(os.WriteFile(mountInfoPath, []byte(
+			"1 0 0:1 / / rw - overlay overlay rw\n"+
+				"2 1 0:2 / /proc rw - proc proc rw\n"+
+				"3 1 8:1 /var/lib/multi-nic /var/lib/multi-nic rw - xfs /dev/sda1 rw\n"), 0644)).To(Succeed())
+		onHost, err := IsOnHostMount(DEFAULT_ALLOCATION_JOURNAL, mountInfoPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(onHost).To(BeTrue())
+		onHost, err = IsOnHostMount("/var/lib/multi-nic-old/allocation.journal", mountInfoPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(onHost).To(BeFalse())
+		onHost, err = IsOnHostMount("/tmp/allocation.journal", mountInfoPath)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(onHost).To(BeFalse())
+	})
+
+	It("keeps release times across restart and compaction", func() {
+		store := newFakeIPPoolStore(0, "eth1")
+		c := NewIPPoolCache(store)
+		c.SetJournal(openJournal())
+		allocateByCache(c, "pod-a", []string{"eth1"})
+		removed, _ := c.Deallocate(cacheTestHostName, cacheTestDefName, "pod-a", "default", "", "")
+		Expect(removed).To(HaveKey(ippoolName))
+		releaseTime := c.journal.getReleaseTimes(ippoolName)[1]
+		Expect(releaseTime).NotTo(BeZero())
+		Expect(c.journal.Close()).To(Succeed())
+
+		// restarted daemon quarantines the released address
+		journal := openJournal()
+		Expect(journal.getReleaseTimes(ippoolName)).To(HaveKeyWithValue(1, BeTemporally("==", releaseTime)))
+		c = NewIPPoolCache(store)
+		c.SetJournal(journal)
+		req := IPRequest{PodName: "pod-b", PodNamespace: "default", InterfaceNames: []string{"eth1"},
+			AllocationStrategy: LRU_STRATEGY, QuarantineSeconds: 3600}
+		Expect(allocateRequestByCache(c, req)[ippoolName].Address).To(Equal("192.168.0.2"))
+
+		// compaction keeps release times
+		journal.Lock()
+		Expect(journal.rewriteLocked(nil)).To(Succeed())
+		journal.Unlock()
+		Expect(journal.Close()).To(Succeed())
+		Expect(openJournal().getReleaseTimes(ippoolName)).To(HaveKey(1))
+	})
+
+	It("resolves intents of allocations", func() {
+		store := newFakeIPPoolStore(0, "eth1", "eth2")
+		c := NewIPPoolCache(store)
+		c.SetJournal(openJournal())
+		allocateConcurrently(c, 10, []string{"eth1", "eth2"})
+		removed, _ := c.Deallocate(cacheTestHostName, cacheTestDefName, "pod-1", "default", "", "")
+		Expect(removed).To(HaveLen(2))
+		Expect(c.journal.Close()).To(Succeed())
+		Expect(openJournal().getUnreplayed()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"log"
+	"sync"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ipreservationStore reads IPReservation on API server
+type ipreservationStore interface {
+	ListIPReservation(network string) ([]backend.IPReservationType, error)
+}
+
+// IPReservationCache keeps valid IPReservations of all namespaces in memory
+type IPReservationCache struct {
+	sync.Mutex
+	store        ipreservationStore
+	reservations map[string]backend.IPReservationType
+	hasSynced    func() bool
+}
+
+// NewIPReservationCache returns a cache which reads through to API server until an informer is started
+func NewIPReservationCache(store ipreservationStore) *IPReservationCache {
+	return &IPReservationCache{
+		store:        store,
+		reservations: make(map[string]backend.IPReservationType),
+		hasSynced:    func() bool { return false },
+	}
+}
+
+// Start watches IPReservations and waits for the initial list
+func (c *IPReservationCache) Start(handler *backend.IPReservationHandler, stopCh <-chan struct{}) {
+	gvr, _ := schema.ParseResourceArg(backend.IPRESERVATION_RESOURCE)
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(handler.DYN, 0)
+	informer := factory.ForResource(*gvr).Informer()
+	setFunc := func(obj interface{}) {
+		uobj, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return
+		}
+		reservation, err := handler.ParseIPReservation(*uobj)
+		if err != nil {
+			log.Printf("Ignore IPReservation %s/%s: %v", uobj.GetNamespace(), uobj.GetName(), err)
+			c.delete(uobj.GetNamespace(), uobj.GetName())
+			return
+		}
+		c.set(reservation)
+	}
+	informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: setFunc,
+		UpdateFunc: func(_, obj interface{}) {
+			setFunc(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if uobj, ok := obj.(*unstructured.Unstructured); ok {
+				c.delete(uobj.GetNamespace(), uobj.GetName())
+			}
+		},
+	})
+	factory.Start(stopCh)
+	if !cache.WaitForCacheSync(stopCh, informer.HasSynced) {
+		log.Println("IPReservation cache is not synced, read through API server")
+		return
+	}
+	c.Lock()
+	c.hasSynced = informer.HasSynced
+	reservationCount := len(c.reservations)
+	c.Unlock()
+	log.Printf("IPReservation cache synced with %d IPReservations", reservationCount)
+}
+
+func (c *IPReservationCache) set(reservation backend.IPReservationType) {
+	c.Lock()
+	defer c.Unlock()
+	c.reservations[reservation.Namespace+"/"+reservation.Name] = reservation
+}
+
+func (c *IPReservationCache) delete(namespace, name string) {
+	c.Lock()
+	defer c.Unlock()
+	delete(c.reservations, namespace+"/"+name)
+}
+
+// List returns reservations of the network, read from API server if the informer is not synced
+func (c *IPReservationCache) List(network string) ([]backend.IPReservationType, error) {
+	c.Lock()
+	synced := c.hasSynced()
+	c.Unlock()
+	if !synced {
+		return c.store.ListIPReservation(network)
+	}
+	c.Lock()
+	defer c.Unlock()
+	reservations := []backend.IPReservationType{}
+	for _, reservation := range c.reservations {
+		if reservation.Spec.Network == network {
+			reservations = append(reservations, reservation)
+		}
+	}
+	return reservations, nil
+}
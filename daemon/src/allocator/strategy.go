@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"log"
+	"math/rand"
+	"time"
+)
+
+const (
+	// SEQUENTIAL_STRATEGY allocates next to the last allocated index, then the lowest free index (default)
+	SEQUENTIAL_STRATEGY = "sequential"
+	// LOWEST_FREE_STRATEGY allocates the lowest free index
+	LOWEST_FREE_STRATEGY = "lowestFree"
+	// RANDOM_STRATEGY allocates a free index at random
+	RANDOM_STRATEGY = "random"
+	// LRU_STRATEGY allocates the least recently released index which is out of quarantine
+	LRU_STRATEGY = "lru"
+)
+
+// getFreeIndexes returns free indexes from 1 to maxIndex in ascending order
+func getFreeIndexes(indexes []int, maxIndex int) []int {
+	used := make(map[int]bool)
+	for _, index := range indexes {
+		used[index] = true
+	}
+	freeIndexes := []int{}
+	for index := 1; index <= maxIndex; index++ {
+		if !used[index] {
+			freeIndexes = append(freeIndexes, index)
+		}
+	}
+	return freeIndexes
+}
+
+// selectSequentialIndex returns the index next to the last allocated index or the lowest free index,
+// maxIndex is the highest allocatable index as in getFreeIndexes
+func selectSequentialIndex(indexes []int, maxIndex int) int {
+	nextIndex := 1 // except network address
+	if len(indexes) > 0 {
+		nextIndex = indexes[len(indexes)-1] + 1
+	}
+	if nextIndex <= maxIndex {
+		return nextIndex
+	}
+	return FindAvailableIndex(indexes, 0)
+}
+
+// selectLRUIndex returns the free index released the longest time ago, never-released indexes first;
+// indexes released within the quarantine are not allocatable
+func selectLRUIndex(freeIndexes []int, releaseTimes map[int]time.Time, quarantine time.Duration, now time.Time) int {
+	selected := -1
+	var selectedTime time.Time
+	for _, index := range freeIndexes {
+		releaseTime, released := releaseTimes[index]
+		if released && now.Sub(releaseTime) < quarantine {
+			continue
+		}
+		if selected == -1 || releaseTime.Before(selectedTime) {
+			selected = index
+			selectedTime = releaseTime
+		}
+	}
+	if selected == -1 {
+		log.Printf("All %d free indexes are in quarantine of %v", len(freeIndexes), quarantine)
+	}
+	return selected
+}
+
+// selectIndex returns an allocatable index of the pod CIDR by the strategy, -1 if none;
+// indexes are sorted allocated and excluded indexes
+func selectIndex(strategy string, indexes []int, maxIndex int, releaseTimes map[int]time.Time, quarantine time.Duration, now time.Time) int {
+	switch strategy {
+	case "", SEQUENTIAL_STRATEGY:
+		return selectSequentialIndex(indexes, maxIndex)
+	}
+	freeIndexes := getFreeIndexes(indexes, maxIndex)
+	if len(freeIndexes) == 0 {
+		return -1
+	}
+	switch strategy {
+	case LOWEST_FREE_STRATEGY:
+		return freeIndexes[0]
+	case RANDOM_STRATEGY:
+		return freeIndexes[rand.Intn(len(freeIndexes))]
+	case LRU_STRATEGY:
+		return selectLRUIndex(freeIndexes, releaseTimes, quarantine, now)
+	}
+	log.Printf("Unknown allocation strategy %s, allocate sequentially", strategy)
+	return selectSequentialIndex(indexes, maxIndex)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package allocator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test Allocation Strategy", func() {
+	quarantine := 10 * time.Second
+
+	// sortedIndexes returns allocated and excluded indexes as given to selectIndex
+	sortedIndexes := func(allocated, excluded map[int]bool) []int {
+		indexes := []int{}
+		for index := range allocated {
+			indexes = append(indexes, index)
+		}
+		for index := range excluded {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		return indexes
+	}
+
+	DescribeTable("never selects allocated, excluded or quarantined index", func(strategy string) {
+		for seed := int64(0); seed < 50; seed++ {
+			rng := rand.New(rand.NewSource(seed))
+			maxIndex := 1 + rng.Intn(60)
+			allocated := make(map[int]bool)
+			excluded := make(map[int]bool)
+			for index := 1; index <= maxIndex; index++ {
+				if rng.Intn(8) == 0 {
+					excluded[index] = true
+				}
+			}
+			releaseTimes := make(map[int]time.Time)
+			now := time.Now()
+			for step := 0; step < 300; step++ {
+				now = now.Add(time.Duration(rng.Intn(5000)) * time.Millisecond)
+				if len(allocated) > 0 && rng.Intn(3) == 0 {
+					released := sortedIndexes(allocated, nil)[rng.Intn(len(allocated))]
+					delete(allocated, released)
+					releaseTimes[released] = now
+					continue
+				}
+				index := selectIndex(strategy, sortedIndexes(allocated, excluded), maxIndex, releaseTimes, quarantine, now)
+				description := fmt.Sprintf("seed %d step %d: index %d of %d", seed, step, index, maxIndex)
+				if index == -1 {
+					for free := 1; free <= maxIndex; free++ {
+						if allocated[free] || excluded[free] {
+							continue
+						}
+						inQuarantine := !releaseTimes[free].IsZero() && now.Sub(releaseTimes[free]) < quarantine
+						if strategy == LRU_STRATEGY {
+							Expect(inQuarantine).To(BeTrue(), description)
+						} else {
+							Fail(fmt.Sprintf("%s: free index %d is not selected", description, free))
+						}
+					}
+					continue
+				}
+				Expect(index).To(BeNumerically(">=", 1), description)
+				Expect(index).To(BeNumerically("<=", maxIndex), description)
+				Expect(allocated).NotTo(HaveKey(index), description)
+				Expect(excluded).NotTo(HaveKey(index), description)
+				if strategy == LRU_STRATEGY && !releaseTimes[index].IsZero() {
+					Expect(now.Sub(releaseTimes[index])).To(BeNumerically(">=", quarantine), description)
+				}
+				allocated[index] = true
+			}
+		}
+	},
+		Entry("sequential", SEQUENTIAL_STRATEGY),
+		Entry("lowest free", LOWEST_FREE_STRATEGY),
+		Entry("random", RANDOM_STRATEGY),
+		Entry("least recently used", LRU_STRATEGY),
+	)
+
+	DescribeTable("selectIndex", func(strategy string, indexes []int, releasedAgo map[int]time.Duration, expectedIndex int) {
+		now := time.Now()
+		releaseTimes := make(map[int]time.Time)
+		for index, ago := range releasedAgo {
+			releaseTimes[index] = now.Add(-ago)
+		}
+		Expect(selectIndex(strategy, indexes, 10, releaseTimes, quarantine, now)).To(Equal(expectedIndex))
+	},
+		Entry("sequential next to last", SEQUENTIAL_STRATEGY, []int{1, 3}, nil, 4),
+		Entry("default is sequential", "", []int{1, 3}, nil, 4),
+		Entry("sequential hole", SEQUENTIAL_STRATEGY, []int{1, 3, 4, 5, 6, 7, 8, 9, 10}, nil, 2),
+		Entry("sequential last index", SEQUENTIAL_STRATEGY, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, nil, 10),
+		Entry("sequential full", SEQUENTIAL_STRATEGY, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, nil, -1),
+		Entry("lowest free", LOWEST_FREE_STRATEGY, []int{1, 3}, nil, 2),
+		Entry("lowest free last index", LOWEST_FREE_STRATEGY, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, nil, 10),
+		Entry("full", LOWEST_FREE_STRATEGY, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}, nil, -1),
+		Entry("lru never released first", LRU_STRATEGY, []int{1, 3}, map[int]time.Duration{2: time.Hour}, 4),
+		Entry("lru least recently released", LRU_STRATEGY, []int{1, 3, 4, 5, 6, 7, 8, 9},
+			map[int]time.Duration{2: time.Minute, 10: time.Hour}, 10),
+		Entry("lru quarantine", LRU_STRATEGY, []int{1, 3, 4, 5, 6, 7, 8, 9},
+			map[int]time.Duration{2: time.Minute, 10: time.Second}, 2),
+		Entry("lru all in quarantine", LRU_STRATEGY, []int{1, 3, 4, 5, 6, 7, 8, 9},
+			map[int]time.Duration{2: time.Second, 10: time.Second}, -1),
+		Entry("unknown strategy", "unknown", []int{1, 3}, nil, 4),
+	)
+
+	DescribeTable("allocates unique addresses concurrently through cache", func(strategy string) {
+		store := newFakeIPPoolStore(time.Millisecond, "eth1")
+		c := NewIPPoolCache(store)
+		podCount := 100
+		addresses := make([]string, podCount)
+		var wg sync.WaitGroup
+		for i := 0; i < podCount; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				req := IPRequest{PodName: fmt.Sprintf("pod-%d", i), PodNamespace: "default", InterfaceNames: []string{"eth1"},
+					AllocationStrategy: strategy}
+				for _, newAllocation := range allocateRequestByCache(c, req) {
+					addresses[i] = newAllocation.Address
+				}
+			}(i)
+		}
+		wg.Wait()
+		Expect(addresses).NotTo(ContainElement(""))
+		seen := make(map[string]bool)
+		for _, address := range addresses {
+			Expect(seen).NotTo(HaveKey(address))
+			seen[address] = true
+		}
+	},
+		Entry("sequential", SEQUENTIAL_STRATEGY),
+		Entry("lowest free", LOWEST_FREE_STRATEGY),
+		Entry("random", RANDOM_STRATEGY),
+		Entry("least recently used", LRU_STRATEGY),
+	)
+
+	It("quarantines address released through cache", func() {
+		store := newFakeIPPoolStore(0, "eth1")
+		c := NewIPPoolCache(store)
+		ippoolName := cacheTestDefName + "-eth1"
+		newRequest := func(podName string) IPRequest {
+			return IPRequest{PodName: podName, PodNamespace: "default", InterfaceNames: []string{"eth1"},
+				AllocationStrategy: LOWEST_FREE_STRATEGY, QuarantineSeconds: 3600}
+		}
+		first := allocateRequestByCache(c, newRequest("pod-a"))
+		Expect(first[ippoolName].Address).To(Equal("192.168.0.1"))
+		removed, _ := c.Deallocate(cacheTestHostName, cacheTestDefName, "pod-a", "default", "", "")
+		Expect(removed).To(HaveKey(ippoolName))
+
+		// lowest free reuses the released address immediately
+		Expect(allocateRequestByCache(c, newRequest("pod-b"))[ippoolName].Address).To(Equal("192.168.0.1"))
+		c.Deallocate(cacheTestHostName, cacheTestDefName, "pod-b", "default", "", "")
+
+		lruRequest := newRequest("pod-c")
+		lruRequest.AllocationStrategy = LRU_STRATEGY
+		Expect(allocateRequestByCache(c, lruRequest)[ippoolName].Address).To(Equal("192.168.0.2"))
+	})
+})
@@ -59,6 +59,7 @@ var _ = BeforeSuite(func() {
 	K8sClientset, err = kubernetes.NewForConfig(cfg)
 	Expect(err).NotTo(HaveOccurred())
 	IppoolHandler = backend.NewIPPoolHandler(cfg)
+	IppoolCache = NewIPPoolCache(IppoolHandler)
 })
 
 var _ = AfterSuite(func() {
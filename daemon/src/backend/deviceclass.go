@@ -10,6 +10,10 @@ import (
 	"k8s.io/client-go/dynamic"
 
 	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/rest"
@@ -26,7 +30,60 @@ type DeviceID struct {
 }
 
 type DeviceClassSpec struct {
-	DeviceIDs []DeviceID `json:"ids"`
+	DeviceIDs     []DeviceID `json:"ids,omitempty"`
+	Drivers       []string   `json:"drivers,omitempty"`
+	MinSpeed      int        `json:"minSpeed,omitempty"`
+	NumaNodes     []int      `json:"numaNodes,omitempty"`
+	PciAddress    string     `json:"pciAddress,omitempty"`
+	InterfaceName string     `json:"interfaceName,omitempty"`
+	SRIOV         *bool      `json:"sriov,omitempty"`
+}
+
+// Match returns true if the interface satisfies all specified fields of the class
+func (spec DeviceClassSpec) Match(info InterfaceInfoType) (bool, error) {
+	if len(spec.DeviceIDs) > 0 {
+		found := false
+		for _, deviceID := range spec.DeviceIDs {
+			if deviceID.Vendor == info.Vendor && slices.Contains(deviceID.Products, info.Product) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false, nil
+		}
+	}
+	if len(spec.Drivers) > 0 && !slices.Contains(spec.Drivers, info.Driver) {
+		return false, nil
+	}
+	if spec.MinSpeed > 0 && info.Speed < spec.MinSpeed {
+		return false, nil
+	}
+	if len(spec.NumaNodes) > 0 && (info.NumaNode == nil || !slices.Contains(spec.NumaNodes, *info.NumaNode)) {
+		return false, nil
+	}
+	if spec.PciAddress != "" {
+		matched, err := path.Match(spec.PciAddress, info.PciAddress)
+		if err != nil {
+			return false, fmt.Errorf("invalid pciAddress pattern %s: %v", spec.PciAddress, err)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if spec.InterfaceName != "" {
+		matched, err := regexp.MatchString(spec.InterfaceName, info.InterfaceName)
+		if err != nil {
+			return false, fmt.Errorf("invalid interfaceName pattern %s: %v", spec.InterfaceName, err)
+		}
+		if !matched {
+			return false, nil
+		}
+	}
+	if spec.SRIOV != nil && *spec.SRIOV != info.SRIOV {
+		return false, nil
+	}
+	return true, nil
 }
 
 type DeviceClassHandler struct {
@@ -0,0 +1,93 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package backend
+
+import (
+	"context"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	POD_EVENT_COMPONENT = "multi-nic-cni-daemon"
+	POD_EVENT_TIMEOUT   = 10 * time.Second
+	// maximum length of event message accepted by API server
+	MAX_EVENT_MESSAGE_LENGTH = 1024
+
+	NIC_SELECTED_REASON          = "NICSelected"
+	NIC_SELECTION_WARNING_REASON = "NICSelectionWarning"
+	IP_ALLOCATION_FAILED_REASON  = "IPAllocationFailed"
+)
+
+// PodEventHandler reports selection and allocation results of the daemon as events on the pod
+type PodEventHandler struct {
+	clientset kubernetes.Interface
+	hostName  string
+}
+
+func NewPodEventHandler(clientset kubernetes.Interface, hostName string) *PodEventHandler {
+	return &PodEventHandler{
+		clientset: clientset,
+		hostName:  hostName,
+	}
+}
+
+// Emit creates event on the pod, the pod UID is looked up for the event to be listed in the pod description
+func (h *PodEventHandler) Emit(podName, podNamespace, eventType, reason, message string) error {
+	return h.EmitWithUID(podName, podNamespace, "", eventType, reason, message)
+}
+
+// EmitWithUID creates event on the pod instance of the given UID, the UID is looked up if empty
+func (h *PodEventHandler) EmitWithUID(podName, podNamespace, podUID, eventType, reason, message string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), POD_EVENT_TIMEOUT)
+	defer cancel()
+	involvedObject := corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Name:       podName,
+		Namespace:  podNamespace,
+		UID:        types.UID(podUID),
+	}
+	if podUID == "" {
+		if pod, err := h.clientset.CoreV1().Pods(podNamespace).Get(ctx, podName, metav1.GetOptions{}); err == nil {
+			involvedObject.UID = pod.UID
+		}
+	}
+	if len(message) > MAX_EVENT_MESSAGE_LENGTH {
+		message = message[0:MAX_EVENT_MESSAGE_LENGTH-3] + "..."
+	}
+	now := metav1.Now()
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: podName + ".",
+			Namespace:    podNamespace,
+		},
+		InvolvedObject: involvedObject,
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: POD_EVENT_COMPONENT, Host: h.hostName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	_, err := h.clientset.CoreV1().Events(podNamespace).Create(ctx, event, metav1.CreateOptions{})
+	return err
+}
+
+// EmitAsync creates event on the pod without blocking the CNI request
+func (h *PodEventHandler) EmitAsync(podName, podNamespace, eventType, reason, message string) {
+	go func() {
+		if err := h.Emit(podName, podNamespace, eventType, reason, message); err != nil {
+			log.Printf("Cannot create %s event on %s/%s: %v", reason, podNamespace, podName, err)
+		}
+	}()
+}
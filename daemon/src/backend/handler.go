@@ -138,3 +138,15 @@ func (h *DynamicHandler) Patch(name string, namespace string, pt types.PatchType
 	log.Println(fmt.Sprintf("Patch%s elapsed: %d us", h.Kind, int64(elapsed/time.Microsecond)))
 	return res, err
 }
+
+// PatchStatus patches status subresource
+func (h *DynamicHandler) PatchStatus(name string, namespace string, pt types.PatchType, data []byte, options metav1.PatchOptions) (*unstructured.Unstructured, error) {
+	gvr, _ := schema.ParseResourceArg(h.ResourceName)
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), APISERVER_TIMEOUT)
+	defer cancel()
+	res, err := h.DYN.Resource(*gvr).Namespace(namespace).Patch(ctx, name, pt, data, options, "status")
+	elapsed := time.Since(start)
+	log.Println(fmt.Sprintf("PatchStatus%s elapsed: %d us", h.Kind, int64(elapsed/time.Microsecond)))
+	return res, err
+}
@@ -9,10 +9,13 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 
+	"encoding/json"
 	"fmt"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/rest"
 )
 
@@ -20,9 +23,45 @@ type InterfaceInfoType struct {
 	InterfaceName string `json:"interfaceName"`
 	NetAddress    string `json:"netAddress"`
 	HostIP        string `json:"hostIP"`
-	Vendor        string `json:"vendor"`
-	Product       string `json:"product"`
-	PciAddress    string `json:"pciAddress"`
+	// HostIPs lists host addresses of all address families
+	HostIPs    []string `json:"hostIPs,omitempty"`
+	Vendor     string   `json:"vendor"`
+	Product    string   `json:"product"`
+	PciAddress string   `json:"pciAddress"`
+	Driver     string   `json:"driver,omitempty"`
+	// Speed is link speed in Mbps, 0 if unknown
+	Speed int `json:"speed,omitempty"`
+	// NumaNode is NUMA node of the device, nil if unknown
+	NumaNode *int `json:"numaNode,omitempty"`
+	// SRIOV is true if the device supports SR-IOV virtual functions
+	SRIOV bool `json:"sriov,omitempty"`
+}
+
+// InterfaceLinkStatus defines link state and statistics of an interface
+type InterfaceLinkStatus struct {
+	InterfaceName string       `json:"interfaceName"`
+	OperState     string       `json:"operState,omitempty"`
+	Carrier       bool         `json:"carrier"`
+	MTU           int          `json:"mtu,omitempty"`
+	Speed         int          `json:"speed,omitempty"`
+	Duplex        string       `json:"duplex,omitempty"`
+	Driver        string       `json:"driver,omitempty"`
+	NumaNode      *int         `json:"numaNode,omitempty"`
+	Counters      LinkCounters `json:"counters"`
+	// Rates is the change of counters per second since the previous report
+	Rates LinkCounters `json:"rates"`
+}
+
+// LinkCounters holds transmit and receive statistics of an interface
+type LinkCounters struct {
+	TxBytes   int64 `json:"txBytes"`
+	RxBytes   int64 `json:"rxBytes"`
+	TxPackets int64 `json:"txPackets"`
+	RxPackets int64 `json:"rxPackets"`
+	TxErrors  int64 `json:"txErrors"`
+	RxErrors  int64 `json:"rxErrors"`
+	TxDropped int64 `json:"txDropped"`
+	RxDropped int64 `json:"rxDropped"`
 }
 
 const (
@@ -96,3 +135,19 @@ func (h *HostInterfaceHandler) GetUnmanagedHostInterfaces() ([]InterfaceInfoType
 	}
 	return []InterfaceInfoType{}, err
 }
+
+// UpdateLinkStatus replaces interface link status list in HostInterface status of this host
+func (h *HostInterfaceHandler) UpdateLinkStatus(linkStatus []InterfaceLinkStatus, updateTime time.Time) error {
+	status := map[string]interface{}{
+		"status": map[string]interface{}{
+			"interfaces":     linkStatus,
+			"lastUpdateTime": metav1.NewTime(updateTime),
+		},
+	}
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	_, err = h.DynamicHandler.PatchStatus(h.hostName, metav1.NamespaceAll, types.MergePatchType, data, metav1.PatchOptions{})
+	return err
+}
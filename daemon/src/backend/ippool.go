@@ -33,15 +33,33 @@ type IPPoolType struct {
 	NetAttachDefName string       `json:"netAttachDef"`
 	HostName         string       `json:"hostName"`
 	InterfaceName    string       `json:"interfaceName"`
-	Excludes         []string     `json'"excludes"`
+	Excludes         []string     `json:"excludes"`
 	Allocations      []Allocation `json:"allocations"`
 }
 
 type Allocation struct {
-	Pod       string `json:"pod"`
-	Namespace string `json:"namespace"`
-	Index     int    `json:"index"`
-	Address   string `json:"address"`
+	Pod         string `json:"pod"`
+	Namespace   string `json:"namespace"`
+	PodUID      string `json:"podUID,omitempty"`
+	ContainerID string `json:"containerID,omitempty"`
+	Index       int    `json:"index"`
+	Address     string `json:"address"`
+}
+
+// IsOwnedBy checks whether the allocation belongs to the pod instance,
+// pod UID and container ID are compared only if both are known
+// (allocations made before the UID is recorded are matched by name)
+func (a Allocation) IsOwnedBy(podName, podNamespace, podUID, containerID string) bool {
+	if a.Pod != podName || a.Namespace != podNamespace {
+		return false
+	}
+	if a.PodUID != "" && podUID != "" && a.PodUID != podUID {
+		return false
+	}
+	if a.ContainerID != "" && containerID != "" && a.ContainerID != containerID {
+		return false
+	}
+	return true
 }
 
 type IPPoolHandler struct {
@@ -94,3 +112,68 @@ func (h *IPPoolHandler) PatchIPPool(poolname string, allocations []Allocation) (
 	dataStr := fmt.Sprintf(`[%s]`, allocationReplace)
 	return h.DynamicHandler.Patch(poolname, metav1.NamespaceAll, types.JSONPatchType, []byte(dataStr), metav1.PatchOptions{})
 }
+
+// IPPoolObject is IPPool spec with the resource version it was read at
+type IPPoolObject struct {
+	Name            string
+	UID             string
+	ResourceVersion string
+	Spec            IPPoolType
+}
+
+// ParseIPPoolObject parses IPPool spec and resource version
+func (h *IPPoolHandler) ParseIPPoolObject(uobj unstructured.Unstructured) IPPoolObject {
+	return IPPoolObject{
+		Name:            h.DynamicHandler.GetName(uobj),
+		UID:             string(uobj.GetUID()),
+		ResourceVersion: uobj.GetResourceVersion(),
+		Spec:            h.parse(uobj),
+	}
+}
+
+// ListIPPoolObject lists IPPools with their resource versions
+func (h *IPPoolHandler) ListIPPoolObject(listOptions metav1.ListOptions) ([]IPPoolObject, error) {
+	poolList, err := h.DynamicHandler.List(metav1.NamespaceAll, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	objects := []IPPoolObject{}
+	for _, pool := range poolList.Items {
+		objects = append(objects, h.ParseIPPoolObject(pool))
+	}
+	return objects, nil
+}
+
+// GetIPPoolObject gets the latest IPPool from API server
+func (h *IPPoolHandler) GetIPPoolObject(poolname string) (IPPoolObject, error) {
+	pool, err := h.DynamicHandler.Get(poolname, metav1.NamespaceAll, metav1.GetOptions{})
+	if err != nil {
+		return IPPoolObject{}, err
+	}
+	return h.ParseIPPoolObject(*pool), nil
+}
+
+// UpdateIPPoolAllocations replaces allocations only if IPPool is still at resourceVersion,
+// API server returns a conflict error otherwise
+func (h *IPPoolHandler) UpdateIPPoolAllocations(poolname string, resourceVersion string, allocations []Allocation) (IPPoolObject, error) {
+	if allocations == nil {
+		allocations = []Allocation{}
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": resourceVersion,
+		},
+		"spec": map[string]interface{}{
+			"allocations": allocations,
+		},
+	}
+	data, err := json.Marshal(patch)
+	if err != nil {
+		return IPPoolObject{}, err
+	}
+	pool, err := h.DynamicHandler.Patch(poolname, metav1.NamespaceAll, types.MergePatchType, data, metav1.PatchOptions{})
+	if err != nil {
+		return IPPoolObject{}, err
+	}
+	return h.ParseIPPoolObject(*pool), nil
+}
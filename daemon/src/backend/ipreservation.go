@@ -0,0 +1,220 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package backend
+
+import (
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	"fmt"
+	"log"
+	"net/netip"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	IPRESERVATION_RESOURCE = "ipreservations.v1.multinic.fms.io"
+	IPRESERVATION_KIND     = "IPReservation"
+)
+
+type IPReservationType struct {
+	Name      string
+	Namespace string
+	Spec      IPReservationSpec
+}
+
+type IPReservationSpec struct {
+	Network     string                  `json:"network"`
+	PodName     string                  `json:"podName,omitempty"`
+	StatefulSet *StatefulSetReservation `json:"statefulSet,omitempty"`
+	Addresses   []ReservedAddress       `json:"addresses"`
+}
+
+type StatefulSetReservation struct {
+	Name         string `json:"name"`
+	Replicas     int    `json:"replicas"`
+	StartOrdinal int    `json:"startOrdinal,omitempty"`
+}
+
+type ReservedAddress struct {
+	InterfaceName string `json:"interfaceName,omitempty"`
+	Address       string `json:"address"`
+}
+
+// IsOnInterface returns true if the address is reserved on the interface (any interface if not specified)
+func (reserved ReservedAddress) IsOnInterface(interfaceName string) bool {
+	return reserved.InterfaceName == "" || reserved.InterfaceName == interfaceName
+}
+
+// Validate checks owner and addresses of the reservation
+func (spec IPReservationSpec) Validate() error {
+	if (spec.PodName == "") == (spec.StatefulSet == nil) {
+		return fmt.Errorf("either podName or statefulSet must be set")
+	}
+	if spec.StatefulSet != nil {
+		if spec.StatefulSet.Name == "" || spec.StatefulSet.Replicas < 1 || spec.StatefulSet.StartOrdinal < 0 {
+			return fmt.Errorf("invalid statefulSet %v", *spec.StatefulSet)
+		}
+	}
+	for _, reserved := range spec.Addresses {
+		if _, err := netip.ParseAddr(reserved.Address); err != nil {
+			return fmt.Errorf("invalid address %s: %v", reserved.Address, err)
+		}
+	}
+	return nil
+}
+
+// getOrdinal returns ordinal of the pod if the pod belongs to the reserved StatefulSet
+func (s StatefulSetReservation) getOrdinal(podName string) (int, bool) {
+	prefix := s.Name + "-"
+	if !strings.HasPrefix(podName, prefix) {
+		return -1, false
+	}
+	ordinalStr := strings.TrimPrefix(podName, prefix)
+	ordinal, err := strconv.Atoi(ordinalStr)
+	if err != nil || strconv.Itoa(ordinal) != ordinalStr {
+		return -1, false
+	}
+	if ordinal < s.StartOrdinal || ordinal >= s.StartOrdinal+s.Replicas {
+		return -1, false
+	}
+	return ordinal, true
+}
+
+// ReservedAddressesForPod returns addresses reserved to the pod in the reservation namespace
+func (r IPReservationType) ReservedAddressesForPod(podName, podNamespace string) []ReservedAddress {
+	if r.Namespace != podNamespace || r.Spec.Validate() != nil {
+		return nil
+	}
+	if r.Spec.PodName != "" {
+		if r.Spec.PodName != podName {
+			return nil
+		}
+		return r.Spec.Addresses
+	}
+	ordinal, ok := r.Spec.StatefulSet.getOrdinal(podName)
+	if !ok {
+		return nil
+	}
+	return offsetAddresses(r.Spec.Addresses, ordinal-r.Spec.StatefulSet.StartOrdinal)
+}
+
+// AllReservedAddresses returns all addresses held by the reservation
+func (r IPReservationType) AllReservedAddresses() []ReservedAddress {
+	if r.Spec.Validate() != nil {
+		return nil
+	}
+	if r.Spec.PodName != "" {
+		return r.Spec.Addresses
+	}
+	reservedAddresses := []ReservedAddress{}
+	for offset := 0; offset < r.Spec.StatefulSet.Replicas; offset++ {
+		reservedAddresses = append(reservedAddresses, offsetAddresses(r.Spec.Addresses, offset)...)
+	}
+	return reservedAddresses
+}
+
+// IsReservedTo returns true if the address on the interface is reserved to the pod
+func (r IPReservationType) IsReservedTo(podName, podNamespace, interfaceName, address string) bool {
+	for _, reserved := range r.ReservedAddressesForPod(podName, podNamespace) {
+		if reserved.Address == address && reserved.IsOnInterface(interfaceName) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReservedAllocation returns true if the allocation holds an address reserved to its pod.
+// Such allocation is kept even if the pod is gone so that the address is given back to the pod instance.
+func IsReservedAllocation(reservations []IPReservationType, interfaceName string, allocation Allocation) bool {
+	for _, reservation := range reservations {
+		if reservation.IsReservedTo(allocation.Pod, allocation.Namespace, interfaceName, allocation.Address) {
+			return true
+		}
+	}
+	return false
+}
+
+// offsetAddresses shifts each reserved address by offset
+func offsetAddresses(addresses []ReservedAddress, offset int) []ReservedAddress {
+	shifted := []ReservedAddress{}
+	for _, reserved := range addresses {
+		addr, err := netip.ParseAddr(reserved.Address)
+		if err != nil {
+			continue
+		}
+		for i := 0; i < offset && addr.IsValid(); i++ {
+			addr = addr.Next()
+		}
+		if !addr.IsValid() {
+			continue
+		}
+		shifted = append(shifted, ReservedAddress{
+			InterfaceName: reserved.InterfaceName,
+			Address:       addr.String(),
+		})
+	}
+	return shifted
+}
+
+type IPReservationHandler struct {
+	*DynamicHandler
+}
+
+func NewIPReservationHandler(config *rest.Config) *IPReservationHandler {
+	dc, _ := discovery.NewDiscoveryClientForConfig(config)
+	dyn, _ := dynamic.NewForConfig(config)
+
+	handler := &IPReservationHandler{
+		DynamicHandler: &DynamicHandler{
+			DC:           dc,
+			DYN:          dyn,
+			ResourceName: IPRESERVATION_RESOURCE,
+			Kind:         IPRESERVATION_KIND,
+		},
+	}
+	return handler
+}
+
+// ParseIPReservation parses and validates the IPReservation object
+func (h *IPReservationHandler) ParseIPReservation(reservation unstructured.Unstructured) (IPReservationType, error) {
+	reservationType := IPReservationType{
+		Name:      reservation.GetName(),
+		Namespace: reservation.GetNamespace(),
+	}
+	spec, ok := reservation.Object["spec"].(map[string]interface{})
+	if !ok {
+		return reservationType, fmt.Errorf("no spec")
+	}
+	h.DynamicHandler.Parse(spec, &reservationType.Spec)
+	return reservationType, reservationType.Spec.Validate()
+}
+
+// ListIPReservation lists reservations of the network in all namespaces
+func (h *IPReservationHandler) ListIPReservation(network string) ([]IPReservationType, error) {
+	reservationList, err := h.DynamicHandler.List(metav1.NamespaceAll, metav1.ListOptions{})
+	reservations := []IPReservationType{}
+	if err != nil {
+		return reservations, err
+	}
+	for _, reservation := range reservationList.Items {
+		reservationType, err := h.ParseIPReservation(reservation)
+		if reservationType.Spec.Network != network {
+			continue
+		}
+		if err != nil {
+			log.Printf("Ignore IPReservation %s/%s: %v", reservation.GetNamespace(), reservation.GetName(), err)
+			continue
+		}
+		reservations = append(reservations, reservationType)
+	}
+	return reservations, nil
+}
@@ -16,18 +16,34 @@ import (
 )
 
 const (
-	MULTINICNET_RESOURCE = "multinicnetworks.v1.multinic.fms.io"
+	MULTINICNET_RESOURCE = "multinicnetworks.v2.multinic.fms.io"
 	MULTINICNET_KIND     = "MultiNicNetwork"
 )
 
 type MultiNicNetworkSpec struct {
 	Policy         AttachmentPolicy `json:"attachPolicy,omitempty"`
 	MasterNetAddrs []string         `json:"masterNets,omitempty"`
+	// RailOptimized is true if each master network is a rail
+	RailOptimized bool `json:"railOptimized,omitempty"`
 }
 
 type AttachmentPolicy struct {
 	Strategy string `json:"strategy"`
 	Target   string `json:"target,omitempty"`
+	// Placement is balance (default) or pack for costOpt strategy
+	Placement string `json:"placement,omitempty"`
+	// Pipeline is ordered filter and score stages evaluated instead of Strategy
+	Pipeline []SelectionStage `json:"pipeline,omitempty"`
+	// OnUnhealthy is degrade (default) to attach fewer NICs or fail to fail the pod when NICs are excluded for unhealthy links
+	OnUnhealthy string `json:"onUnhealthy,omitempty"`
+}
+
+// SelectionStage is a stage of NIC selection pipeline
+type SelectionStage struct {
+	Type      string   `json:"type"`
+	Class     string   `json:"class,omitempty"`
+	Names     []string `json:"names,omitempty"`
+	Placement string   `json:"placement,omitempty"`
 }
 
 type MultiNicNetworkHandler struct {
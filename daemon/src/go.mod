@@ -9,10 +9,12 @@ require (
 	github.com/onsi/ginkgo/v2 v2.21.0
 	github.com/onsi/gomega v1.36.1
 	github.com/vishvananda/netlink v1.1.1-0.20210330154013-f5de75959ad5
-	k8s.io/api v0.23.3
+	google.golang.org/grpc v1.56.3
+	k8s.io/api v0.27.16
 	k8s.io/apiextensions-apiserver v0.23.0
-	k8s.io/apimachinery v0.23.3
+	k8s.io/apimachinery v0.27.16
 	k8s.io/client-go v0.23.3
+	k8s.io/kubelet v0.27.16
 	sigs.k8s.io/controller-runtime v0.11.0
 )
 
@@ -25,7 +27,7 @@ require (
 	github.com/go-ole/go-ole v1.2.6 // indirect
 	github.com/go-task/slim-sprig/v3 v3.0.0 // indirect
 	github.com/gogo/protobuf v1.3.2 // indirect
-	github.com/golang/protobuf v1.5.2 // indirect
+	github.com/golang/protobuf v1.5.4 // indirect
 	github.com/google/go-cmp v0.6.0 // indirect
 	github.com/google/gofuzz v1.1.0 // indirect
 	github.com/google/pprof v0.0.0-20241029153458-d1b30febd7db // indirect
@@ -50,16 +52,17 @@ require (
 	golang.org/x/text v0.23.0 // indirect
 	golang.org/x/time v0.0.0-20210723032227-1f47c861a9ac // indirect
 	golang.org/x/tools v0.26.0 // indirect
+	google.golang.org/genproto v0.0.0-20230410155749-daa745c078e1 // indirect
 	google.golang.org/protobuf v1.35.1 // indirect
 	gopkg.in/inf.v0 v0.9.1 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 	howett.net/plist v1.0.0 // indirect
-	k8s.io/klog/v2 v2.30.0 // indirect
+	k8s.io/klog/v2 v2.90.1 // indirect
 	k8s.io/kube-openapi v0.0.0-20211115234752-e816edb12b65 // indirect
-	k8s.io/utils v0.0.0-20211116205334-6203023598ed // indirect
-	sigs.k8s.io/json v0.0.0-20211020170558-c049b76a60c6 // indirect
-	sigs.k8s.io/structured-merge-diff/v4 v4.2.1 // indirect
+	k8s.io/utils v0.0.0-20230209194617-a36077c30491 // indirect
+	sigs.k8s.io/json v0.0.0-20221116044647-bc3834ca7abd // indirect
+	sigs.k8s.io/structured-merge-diff/v4 v4.2.3 // indirect
 	sigs.k8s.io/yaml v1.3.0 // indirect
 )
 
@@ -83,7 +86,7 @@ replace (
 	k8s.io/kube-proxy => k8s.io/kube-proxy v0.23.3
 	k8s.io/kube-scheduler => k8s.io/kube-scheduler v0.23.3
 	k8s.io/kubectl => k8s.io/kubectl v0.23.3
-	k8s.io/kubelet => k8s.io/kubelet v0.23.3
+	k8s.io/kubelet => k8s.io/kubelet v0.27.16
 	k8s.io/legacy-cloud-providers => k8s.io/legacy-cloud-providers v0.23.3
 	k8s.io/metrics => k8s.io/metrics v0.23.3
 	k8s.io/mount-utils => k8s.io/mount-utils v0.23.3
@@ -38,13 +38,36 @@ func SetInterfaceInfoCache(name string, info backend.InterfaceInfoType) {
 	interfaceInfoCache.SetCache(name, info)
 }
 
+// getInterfaceAddrs returns the primary address (IPv4 preferred) and all global unicast host IPs from the address list
+func getInterfaceAddrs(addrs []netlink.Addr) (*net.IPNet, []string) {
+	var primary *net.IPNet
+	hostIPs := []string{}
+	for _, addr := range addrs {
+		if addr.IPNet == nil || !addr.IP.IsGlobalUnicast() {
+			continue
+		}
+		if addr.IP.To4() != nil {
+			hostIPs = append(hostIPs, addr.IP.To4().String())
+			if primary == nil || primary.IP.To4() == nil {
+				primary = addr.IPNet
+			}
+		} else {
+			hostIPs = append(hostIPs, addr.IP.String())
+			if primary == nil {
+				primary = addr.IPNet
+			}
+		}
+	}
+	return primary, hostIPs
+}
+
 func getNetAddressFromLink(devLink netlink.Link) (string, error) {
-	addrs, err := netlink.AddrList(devLink, netlink.FAMILY_V4)
+	addrs, err := netlink.AddrList(devLink, netlink.FAMILY_ALL)
 	devName := devLink.Attrs().Name
 	if err != nil || len(addrs) == 0 {
 		return "", fmt.Errorf("cannot list address on %s: %v", devName, err)
 	}
-	addr := addrs[0].IPNet
+	addr, _ := getInterfaceAddrs(addrs)
 	if addr == nil {
 		return "", fmt.Errorf("no address set on %s", devName)
 	}
@@ -130,12 +153,12 @@ func GetInterfaces() []backend.InterfaceInfoType {
 			log.Printf("cannot find link %s: %v", devName, err)
 			continue
 		}
-		addrs, err := netlink.AddrList(devLink, netlink.FAMILY_V4)
+		addrs, err := netlink.AddrList(devLink, netlink.FAMILY_ALL)
 		if err != nil || len(addrs) == 0 {
 			log.Printf("cannot list address on %s: %v", devName, err)
 			continue
 		}
-		addr := addrs[0].IPNet
+		addr, hostIPs := getInterfaceAddrs(addrs)
 		if addr == nil {
 			log.Printf("no address set on %s", devName)
 			continue
@@ -152,18 +175,25 @@ func GetInterfaces() []backend.InterfaceInfoType {
 			continue
 		}
 
+		hostIP := addr.IP.String()
 		if addr.IP.To4() != nil {
-			iface := backend.InterfaceInfoType{
-				InterfaceName: devName,
-				NetAddress:    netAddress,
-				HostIP:        addr.IP.To4().String(),
-				Vendor:        netDevice.Vendor,
-				Product:       netDevice.Product,
-				PciAddress:    netDevice.PciAddress,
-			}
-			interfaces = append(interfaces, iface)
-			interfaceInfoCache.SetCache(devName, iface)
+			hostIP = addr.IP.To4().String()
+		}
+		iface := backend.InterfaceInfoType{
+			InterfaceName: devName,
+			NetAddress:    netAddress,
+			HostIP:        hostIP,
+			HostIPs:       hostIPs,
+			Vendor:        netDevice.Vendor,
+			Product:       netDevice.Product,
+			PciAddress:    netDevice.PciAddress,
+			Driver:        netDevice.Driver,
+			Speed:         GetLinkSpeed(devName),
+			NumaNode:      netDevice.NumaNode,
+			SRIOV:         netDevice.SRIOV,
 		}
+		interfaces = append(interfaces, iface)
+		interfaceInfoCache.SetCache(devName, iface)
 	}
 	// add unmanaged info
 	hifIfaces, err := HostInterfaceHandler.GetUnmanagedHostInterfaces()
@@ -182,12 +212,12 @@ func getNetAddressFromDevice(devName string) (string, error) {
 		log.Printf("cannot find link %s: %v", devName, err)
 		return "", err
 	}
-	addrs, err := netlink.AddrList(devLink, netlink.FAMILY_V4)
+	addrs, err := netlink.AddrList(devLink, netlink.FAMILY_ALL)
 	if err != nil || len(addrs) == 0 {
 		log.Printf("cannot list address on %s: %v", devName, err)
 		return "", err
 	}
-	addr := addrs[0].IPNet
+	addr, _ := getInterfaceAddrs(addrs)
 	if addr == nil {
 		log.Printf("no address set on %s", devName)
 		return "", err
@@ -0,0 +1,138 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package iface
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	LINK_FLAP_WINDOW_ENV    = "LINK_FLAP_WINDOW"
+	LINK_FLAP_THRESHOLD_ENV = "LINK_FLAP_THRESHOLD"
+	// DEFAULT_LINK_FLAP_WINDOW is default window in seconds to count carrier changes
+	DEFAULT_LINK_FLAP_WINDOW = 300
+	// DEFAULT_LINK_FLAP_THRESHOLD is default number of carrier changes in the window to consider the link flapping,
+	// each down and up is a carrier change so that the default is two flaps
+	DEFAULT_LINK_FLAP_THRESHOLD = 4
+
+	LinkDownReason     = "link down"
+	LinkFlappingReason = "link flapping"
+)
+
+// GetLinkFlapWindow returns window from LINK_FLAP_WINDOW (seconds)
+func GetLinkFlapWindow() time.Duration {
+	return time.Duration(LookupIntEnv(LINK_FLAP_WINDOW_ENV, DEFAULT_LINK_FLAP_WINDOW)) * time.Second
+}
+
+// GetLinkFlapThreshold returns LINK_FLAP_THRESHOLD, 0 disables flap detection
+func GetLinkFlapThreshold() int64 {
+	return int64(LookupIntEnv(LINK_FLAP_THRESHOLD_ENV, DEFAULT_LINK_FLAP_THRESHOLD))
+}
+
+// IsLinkUp returns true if the link has carrier and is operationally up,
+// virtual interfaces may report unknown operstate with carrier
+func IsLinkUp(devName string) (bool, error) {
+	devDir := filepath.Join(SysClassNet, devName)
+	operState, err := readSysfsString(filepath.Join(devDir, "operstate"))
+	if err != nil {
+		return false, err
+	}
+	// carrier cannot be read when the interface is administratively down
+	carrier, err := readSysfsInt(filepath.Join(devDir, "carrier"))
+	if err != nil {
+		return false, nil
+	}
+	return carrier == 1 && (operState == "up" || operState == "unknown"), nil
+}
+
+// carrierChange is the number of carrier changes observed at the timestamp
+type carrierChange struct {
+	timestamp time.Time
+	count     int64
+}
+
+// LinkHealthTracker counts carrier changes of the interfaces in a sliding window
+// from /sys/class/net/<devName>/carrier_changes to detect flapping links
+type LinkHealthTracker struct {
+	sync.Mutex
+	Window    time.Duration
+	Threshold int64
+	// lastCarrierChanges is carrier_changes counter of the previous sample
+	lastCarrierChanges map[string]int64
+	changes            map[string][]carrierChange
+}
+
+func NewLinkHealthTracker(window time.Duration, threshold int64) *LinkHealthTracker {
+	return &LinkHealthTracker{
+		Window:             window,
+		Threshold:          threshold,
+		lastCarrierChanges: make(map[string]int64),
+		changes:            make(map[string][]carrierChange),
+	}
+}
+
+var LinkHealthMonitor = NewLinkHealthTracker(GetLinkFlapWindow(), GetLinkFlapThreshold())
+
+// recordLocked records carrier changes of the interface since the previous sample and drops changes out of the window
+func (t *LinkHealthTracker) recordLocked(devName string, carrierChanges int64, now time.Time) {
+	if last, found := t.lastCarrierChanges[devName]; found && carrierChanges > last {
+		t.changes[devName] = append(t.changes[devName], carrierChange{timestamp: now, count: carrierChanges - last})
+	}
+	t.lastCarrierChanges[devName] = carrierChanges
+	changes := []carrierChange{}
+	for _, change := range t.changes[devName] {
+		if now.Sub(change.timestamp) <= t.Window {
+			changes = append(changes, change)
+		}
+	}
+	t.changes[devName] = changes
+}
+
+// Sample records carrier changes of the link samples
+func (t *LinkHealthTracker) Sample(samples []LinkSample, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	for _, sample := range samples {
+		if sample.CarrierChanges >= 0 {
+			t.recordLocked(sample.InterfaceName, sample.CarrierChanges, now)
+		}
+	}
+}
+
+// GetCarrierChanges reads carrier changes of the interface and returns the number of changes in the window
+func (t *LinkHealthTracker) GetCarrierChanges(devName string, now time.Time) int64 {
+	t.Lock()
+	defer t.Unlock()
+	if carrierChanges, err := readCarrierChanges(devName); err == nil {
+		t.recordLocked(devName, carrierChanges, now)
+	}
+	var count int64
+	for _, change := range t.changes[devName] {
+		count += change.count
+	}
+	return count
+}
+
+// CheckLink returns reason if the link is down or flapping, empty if healthy,
+// the interface which cannot be read is left to the device existence check
+func (t *LinkHealthTracker) CheckLink(devName string, now time.Time) string {
+	up, err := IsLinkUp(devName)
+	if err != nil {
+		return ""
+	}
+	if !up {
+		return LinkDownReason
+	}
+	if t.Threshold > 0 {
+		if count := t.GetCarrierChanges(devName, now); count >= t.Threshold {
+			return fmt.Sprintf("%s (%d carrier changes in %v)", LinkFlappingReason, count, t.Window)
+		}
+	}
+	return ""
+}
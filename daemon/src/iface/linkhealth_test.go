@@ -0,0 +1,90 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package iface
+
+import (
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test Link Health", func() {
+	var originalSysClassNet string
+
+	setLink := func(devName, operState, carrier string, carrierChanges string) {
+		devDir := filepath.Join(SysClassNet, devName)
+		writeSysfsFile(devDir, "operstate", operState)
+		writeSysfsFile(devDir, "carrier", carrier)
+		writeSysfsFile(devDir, "carrier_changes", carrierChanges)
+	}
+
+	BeforeEach(func() {
+		originalSysClassNet = SysClassNet
+		SysClassNet = GinkgoT().TempDir()
+	})
+
+	AfterEach(func() {
+		SysClassNet = originalSysClassNet
+	})
+
+	DescribeTable("checks link state", func(operState, carrier string, expectedUp bool) {
+		setLink("eth1", operState, carrier, "1")
+		up, err := IsLinkUp("eth1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(up).To(Equal(expectedUp))
+	},
+		Entry("up", "up", "1", true),
+		Entry("virtual interface", "unknown", "1", true),
+		Entry("no carrier", "down", "0", false),
+		Entry("dormant", "dormant", "1", false),
+	)
+
+	It("reads no carrier of administratively down link as down", func() {
+		writeSysfsFile(filepath.Join(SysClassNet, "eth1"), "operstate", "down")
+		up, err := IsLinkUp("eth1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(up).To(BeFalse())
+		_, err = IsLinkUp("eth2")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("counts carrier changes in the window", func() {
+		tracker := NewLinkHealthTracker(time.Minute, 4)
+		now := time.Now()
+		setLink("eth1", "up", "1", "10")
+		// first sample is a baseline
+		tracker.Sample(ReadLinkSamples([]string{"eth1", "eth2"}, nil), now)
+		Expect(tracker.GetCarrierChanges("eth1", now)).To(BeEquivalentTo(0))
+		Expect(tracker.CheckLink("eth1", now)).To(BeEmpty())
+
+		setLink("eth1", "up", "1", "12")
+		tracker.Sample(ReadLinkSamples([]string{"eth1"}, nil), now.Add(10*time.Second))
+		setLink("eth1", "up", "1", "14")
+		Expect(tracker.GetCarrierChanges("eth1", now.Add(20*time.Second))).To(BeEquivalentTo(4))
+		Expect(tracker.CheckLink("eth1", now.Add(20*time.Second))).To(Equal("link flapping (4 carrier changes in 1m0s)"))
+
+		// changes out of the window are dropped
+		Expect(tracker.GetCarrierChanges("eth1", now.Add(75*time.Second))).To(BeEquivalentTo(2))
+		Expect(tracker.CheckLink("eth1", now.Add(75*time.Second))).To(BeEmpty())
+		Expect(tracker.GetCarrierChanges("eth1", now.Add(90*time.Second))).To(BeEquivalentTo(0))
+
+		// down takes precedence and unreadable interface is left to the existence check
+		setLink("eth1", "down", "0", "15")
+		Expect(tracker.CheckLink("eth1", now.Add(100*time.Second))).To(Equal(LinkDownReason))
+		Expect(tracker.CheckLink("eth2", now.Add(100*time.Second))).To(BeEmpty())
+	})
+
+	It("disables flap detection with zero threshold", func() {
+		tracker := NewLinkHealthTracker(time.Minute, 0)
+		now := time.Now()
+		setLink("eth1", "up", "1", "0")
+		tracker.Sample(ReadLinkSamples([]string{"eth1"}, nil), now)
+		setLink("eth1", "up", "1", "100")
+		Expect(tracker.CheckLink("eth1", now.Add(time.Second))).To(BeEmpty())
+	})
+})
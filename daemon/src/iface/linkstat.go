@@ -0,0 +1,274 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package iface
+
+import (
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+)
+
+const (
+	LINK_SAMPLE_INTERVAL_ENV = "LINK_SAMPLE_INTERVAL"
+	LINK_STAT_INTERVAL_ENV   = "LINK_STAT_INTERVAL"
+	// DEFAULT_LINK_SAMPLE_INTERVAL is default interval in seconds to sample link status for link health, perfOpt and the report
+	DEFAULT_LINK_SAMPLE_INTERVAL = 5
+	// DEFAULT_LINK_STAT_INTERVAL is default interval in seconds to report link status
+	DEFAULT_LINK_STAT_INTERVAL = 60
+)
+
+// LinkSample is link status of an interface read by the link sampler
+type LinkSample struct {
+	backend.InterfaceLinkStatus
+	// CarrierChanges is the carrier_changes counter, negative if unavailable
+	CarrierChanges int64
+}
+
+// LinkSampleHandler consumes link samples of the interfaces taken at the timestamp
+type LinkSampleHandler func(samples []LinkSample, now time.Time)
+
+// reportedLink is link status reported at the timestamp
+type reportedLink struct {
+	backend.InterfaceLinkStatus
+	timestamp time.Time
+}
+
+// LookupIntEnv returns non-negative integer value of the environment variable or the default value
+func LookupIntEnv(envName string, defaultValue int) int {
+	if val, found := os.LookupEnv(envName); found && val != "" {
+		if intVal, err := strconv.Atoi(val); err == nil && intVal >= 0 {
+			return intVal
+		}
+		log.Printf("invalid %s=%s, use default %d", envName, val, defaultValue)
+	}
+	return defaultValue
+}
+
+// GetLinkSampleInterval returns sample interval from LINK_SAMPLE_INTERVAL (seconds), 0 disables the sampler
+func GetLinkSampleInterval() time.Duration {
+	return time.Duration(LookupIntEnv(LINK_SAMPLE_INTERVAL_ENV, DEFAULT_LINK_SAMPLE_INTERVAL)) * time.Second
+}
+
+// GetLinkStatInterval returns report interval from LINK_STAT_INTERVAL (seconds), 0 disables the report
+func GetLinkStatInterval() time.Duration {
+	return time.Duration(LookupIntEnv(LINK_STAT_INTERVAL_ENV, DEFAULT_LINK_STAT_INTERVAL)) * time.Second
+}
+
+// readSysfsString reads trimmed string value from sysfs file
+func readSysfsString(filePath string) (string, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// readSysfsInt64 reads int64 value from sysfs file, 0 if unavailable
+func readSysfsInt64(filePath string) int64 {
+	content, err := readSysfsString(filePath)
+	if err != nil {
+		return 0
+	}
+	value, err := strconv.ParseInt(content, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return value
+}
+
+// readLinkCounters reads statistics of the interface from /sys/class/net/<devName>/statistics
+func readLinkCounters(devName string) backend.LinkCounters {
+	statDir := filepath.Join(SysClassNet, devName, "statistics")
+	read := func(name string) int64 {
+		return readSysfsInt64(filepath.Join(statDir, name))
+	}
+	return backend.LinkCounters{
+		TxBytes:   read("tx_bytes"),
+		RxBytes:   read("rx_bytes"),
+		TxPackets: read("tx_packets"),
+		RxPackets: read("rx_packets"),
+		TxErrors:  read("tx_errors"),
+		RxErrors:  read("rx_errors"),
+		TxDropped: read("tx_dropped"),
+		RxDropped: read("rx_dropped"),
+	}
+}
+
+// ReadLinkStatus reads link state and statistics of the interface from sysfs
+// driver and NUMA node are taken from discovered interface info
+func ReadLinkStatus(devName string, info backend.InterfaceInfoType) (backend.InterfaceLinkStatus, error) {
+	devDir := filepath.Join(SysClassNet, devName)
+	if _, err := os.Stat(devDir); err != nil {
+		return backend.InterfaceLinkStatus{}, err
+	}
+	status := backend.InterfaceLinkStatus{
+		InterfaceName: devName,
+		Driver:        info.Driver,
+		NumaNode:      info.NumaNode,
+		Speed:         GetLinkSpeed(devName),
+		Counters:      readLinkCounters(devName),
+	}
+	status.OperState, _ = readSysfsString(filepath.Join(devDir, "operstate"))
+	// carrier cannot be read when the interface is administratively down
+	if carrier, err := readSysfsInt(filepath.Join(devDir, "carrier")); err == nil {
+		status.Carrier = carrier == 1
+	}
+	if mtu, err := readSysfsInt(filepath.Join(devDir, "mtu")); err == nil {
+		status.MTU = mtu
+	}
+	status.Duplex, _ = readSysfsString(filepath.Join(devDir, "duplex"))
+	return status, nil
+}
+
+// ComputeLinkRates returns change of counters per second, reset counters give zero rate
+func ComputeLinkRates(prev, curr backend.LinkCounters, elapsed time.Duration) backend.LinkCounters {
+	if elapsed <= 0 {
+		return backend.LinkCounters{}
+	}
+	rate := func(prevValue, currValue int64) int64 {
+		if currValue < prevValue {
+			return 0
+		}
+		return int64(float64(currValue-prevValue) / elapsed.Seconds())
+	}
+	return backend.LinkCounters{
+		TxBytes:   rate(prev.TxBytes, curr.TxBytes),
+		RxBytes:   rate(prev.RxBytes, curr.RxBytes),
+		TxPackets: rate(prev.TxPackets, curr.TxPackets),
+		RxPackets: rate(prev.RxPackets, curr.RxPackets),
+		TxErrors:  rate(prev.TxErrors, curr.TxErrors),
+		RxErrors:  rate(prev.RxErrors, curr.RxErrors),
+		TxDropped: rate(prev.TxDropped, curr.TxDropped),
+		RxDropped: rate(prev.RxDropped, curr.RxDropped),
+	}
+}
+
+// readCarrierChanges reads number of carrier changes of the interface from sysfs
+func readCarrierChanges(devName string) (int64, error) {
+	content, err := readSysfsString(filepath.Join(SysClassNet, devName, "carrier_changes"))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(content, 10, 64)
+}
+
+// ReadLinkSamples reads link status and carrier changes of the interfaces,
+// driver and NUMA node are taken from the interface info if found, removed interfaces are skipped
+func ReadLinkSamples(devNames []string, interfaceMap map[string]backend.InterfaceInfoType) []LinkSample {
+	samples := []LinkSample{}
+	for _, devName := range devNames {
+		status, err := ReadLinkStatus(devName, interfaceMap[devName])
+		if err != nil {
+			continue
+		}
+		carrierChanges, err := readCarrierChanges(devName)
+		if err != nil {
+			carrierChanges = -1
+		}
+		samples = append(samples, LinkSample{InterfaceLinkStatus: status, CarrierChanges: carrierChanges})
+	}
+	return samples
+}
+
+// sampleDiscoveredLinks reads link samples of discovered interfaces in order of name
+func sampleDiscoveredLinks() []LinkSample {
+	if interfaceInfoCache.GetSize() == 0 {
+		GetInterfaces()
+	}
+	interfaceMap := GetInterfaceInfoCache()
+	devNames := []string{}
+	for devName := range interfaceMap {
+		devNames = append(devNames, devName)
+	}
+	sort.Strings(devNames)
+	return ReadLinkSamples(devNames, interfaceMap)
+}
+
+// RunLinkSampler periodically reads link status of discovered interfaces once and passes the samples to the handlers
+func RunLinkSampler(interval time.Duration, handlers ...LinkSampleHandler) {
+	if interval <= 0 {
+		log.Println("link sampler disabled")
+		return
+	}
+	log.Printf("sample link status every %v", interval)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for now := range ticker.C {
+		samples := sampleDiscoveredLinks()
+		for _, handler := range handlers {
+			handler(samples, now)
+		}
+	}
+}
+
+// LinkStatReporter writes link samples to HostInterface status once every report interval
+type LinkStatReporter struct {
+	// samplesPerReport is number of samples between two reports
+	samplesPerReport int
+	sampleCount      int
+	// lastSamples keeps the previously reported sample of each interface to compute rates
+	lastSamples map[string]reportedLink
+}
+
+// NewLinkStatReporter returns a reporter of the link sampler, nil if the report is disabled
+func NewLinkStatReporter(reportInterval, sampleInterval time.Duration) *LinkStatReporter {
+	if reportInterval <= 0 || sampleInterval <= 0 {
+		log.Println("link status report disabled")
+		return nil
+	}
+	samplesPerReport := int(math.Round(float64(reportInterval) / float64(sampleInterval)))
+	if samplesPerReport < 1 {
+		samplesPerReport = 1
+	}
+	log.Printf("report link status every %v", time.Duration(samplesPerReport)*sampleInterval)
+	return &LinkStatReporter{
+		samplesPerReport: samplesPerReport,
+		lastSamples:      make(map[string]reportedLink),
+	}
+}
+
+// collect returns link status of the samples with rates from the previous report,
+// false if the report is not due
+func (r *LinkStatReporter) collect(samples []LinkSample, now time.Time) ([]backend.InterfaceLinkStatus, bool) {
+	due := r.sampleCount%r.samplesPerReport == 0
+	r.sampleCount += 1
+	if !due {
+		return nil, false
+	}
+	linkStatus := []backend.InterfaceLinkStatus{}
+	reported := make(map[string]reportedLink)
+	for _, sample := range samples {
+		status := sample.InterfaceLinkStatus
+		if prev, found := r.lastSamples[status.InterfaceName]; found {
+			status.Rates = ComputeLinkRates(prev.Counters, status.Counters, now.Sub(prev.timestamp))
+		}
+		reported[status.InterfaceName] = reportedLink{InterfaceLinkStatus: status, timestamp: now}
+		linkStatus = append(linkStatus, status)
+	}
+	r.lastSamples = reported
+	sort.Slice(linkStatus, func(i, j int) bool {
+		return linkStatus[i].InterfaceName < linkStatus[j].InterfaceName
+	})
+	return linkStatus, true
+}
+
+// Report writes link status to HostInterface status if the report is due
+func (r *LinkStatReporter) Report(samples []LinkSample, now time.Time) {
+	linkStatus, due := r.collect(samples, now)
+	if !due {
+		return
+	}
+	if err := HostInterfaceHandler.UpdateLinkStatus(linkStatus, now); err != nil {
+		log.Printf("cannot update link status: %v", err)
+	}
+}
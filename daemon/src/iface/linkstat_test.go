@@ -0,0 +1,140 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package iface
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+)
+
+func TestIface(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Interface Test Suite")
+}
+
+func writeSysfsFile(dir, name, value string) {
+	Expect(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0644)).To(Succeed())
+}
+
+var _ = Describe("Test Link Status", func() {
+	var originalSysClassNet string
+
+	BeforeEach(func() {
+		originalSysClassNet = SysClassNet
+		SysClassNet = GinkgoT().TempDir()
+		devDir := filepath.Join(SysClassNet, "eth1")
+		writeSysfsFile(devDir, "operstate", "up")
+		writeSysfsFile(devDir, "carrier", "1")
+		writeSysfsFile(devDir, "mtu", "9000")
+		writeSysfsFile(devDir, "speed", "100000")
+		writeSysfsFile(devDir, "duplex", "full")
+		writeSysfsFile(devDir, "statistics/tx_bytes", "2000")
+		writeSysfsFile(devDir, "statistics/rx_bytes", "4000")
+		writeSysfsFile(devDir, "statistics/tx_packets", "20")
+		writeSysfsFile(devDir, "statistics/rx_dropped", "3")
+	})
+
+	AfterEach(func() {
+		SysClassNet = originalSysClassNet
+	})
+
+	It("reads link status from sysfs", func() {
+		numaNode := 1
+		status, err := ReadLinkStatus("eth1", backend.InterfaceInfoType{Driver: "mlx5_core", NumaNode: &numaNode})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.InterfaceName).To(Equal("eth1"))
+		Expect(status.OperState).To(Equal("up"))
+		Expect(status.Carrier).To(BeTrue())
+		Expect(status.MTU).To(Equal(9000))
+		Expect(status.Speed).To(Equal(100000))
+		Expect(status.Duplex).To(Equal("full"))
+		Expect(status.Driver).To(Equal("mlx5_core"))
+		Expect(*status.NumaNode).To(Equal(1))
+		Expect(status.Counters).To(Equal(backend.LinkCounters{TxBytes: 2000, RxBytes: 4000, TxPackets: 20, RxDropped: 3}))
+	})
+
+	It("reads link status without carrier", func() {
+		Expect(os.Remove(filepath.Join(SysClassNet, "eth1", "carrier"))).To(Succeed())
+		writeSysfsFile(filepath.Join(SysClassNet, "eth1"), "operstate", "down")
+		status, err := ReadLinkStatus("eth1", backend.InterfaceInfoType{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status.OperState).To(Equal("down"))
+		Expect(status.Carrier).To(BeFalse())
+	})
+
+	It("fails on missing interface", func() {
+		_, err := ReadLinkStatus("eth2", backend.InterfaceInfoType{})
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("compute rates", func(prev, curr backend.LinkCounters, elapsed time.Duration, expected backend.LinkCounters) {
+		Expect(ComputeLinkRates(prev, curr, elapsed)).To(Equal(expected))
+	},
+		Entry("per second", backend.LinkCounters{TxBytes: 1000, RxPackets: 10}, backend.LinkCounters{TxBytes: 7000, RxPackets: 70},
+			time.Minute, backend.LinkCounters{TxBytes: 100, RxPackets: 1}),
+		Entry("counter reset", backend.LinkCounters{TxBytes: 7000}, backend.LinkCounters{TxBytes: 1000},
+			time.Minute, backend.LinkCounters{}),
+		Entry("no elapsed time", backend.LinkCounters{}, backend.LinkCounters{TxBytes: 1000},
+			0*time.Second, backend.LinkCounters{}),
+	)
+
+	It("reads link samples of existing interfaces", func() {
+		writeSysfsFile(filepath.Join(SysClassNet, "eth1"), "carrier_changes", "3")
+		writeSysfsFile(filepath.Join(SysClassNet, "eth3"), "operstate", "up")
+		samples := ReadLinkSamples([]string{"eth1", "eth2", "eth3"}, map[string]backend.InterfaceInfoType{"eth1": {Driver: "mlx5_core"}})
+		Expect(samples).To(HaveLen(2))
+		Expect(samples[0].InterfaceName).To(Equal("eth1"))
+		Expect(samples[0].Driver).To(Equal("mlx5_core"))
+		Expect(samples[0].CarrierChanges).To(BeEquivalentTo(3))
+		Expect(samples[1].InterfaceName).To(Equal("eth3"))
+		Expect(samples[1].CarrierChanges).To(BeNumerically("<", 0))
+	})
+
+	It("reports every report interval of samples with rates since the previous report", func() {
+		Expect(NewLinkStatReporter(0, 5*time.Second)).To(BeNil())
+		Expect(NewLinkStatReporter(time.Minute, 0)).To(BeNil())
+		reporter := NewLinkStatReporter(15*time.Second, 5*time.Second)
+		now := time.Now()
+		sampleAt := func(txBytes int64, elapsed time.Duration) ([]backend.InterfaceLinkStatus, bool) {
+			sample := LinkSample{InterfaceLinkStatus: backend.InterfaceLinkStatus{
+				InterfaceName: "eth1",
+				Counters:      backend.LinkCounters{TxBytes: txBytes},
+			}}
+			return reporter.collect([]LinkSample{sample}, now.Add(elapsed))
+		}
+		// first sample is reported without rates
+		linkStatus, due := sampleAt(1000, 0)
+		Expect(due).To(BeTrue())
+		Expect(linkStatus).To(HaveLen(1))
+		Expect(linkStatus[0].Rates).To(Equal(backend.LinkCounters{}))
+		_, due = sampleAt(2000, 5*time.Second)
+		Expect(due).To(BeFalse())
+		_, due = sampleAt(3000, 10*time.Second)
+		Expect(due).To(BeFalse())
+		linkStatus, due = sampleAt(4000, 15*time.Second)
+		Expect(due).To(BeTrue())
+		Expect(linkStatus[0].Counters.TxBytes).To(BeEquivalentTo(4000))
+		Expect(linkStatus[0].Rates.TxBytes).To(BeEquivalentTo(200))
+	})
+
+	DescribeTable("link stat interval", func(value string, expected time.Duration) {
+		GinkgoT().Setenv(LINK_STAT_INTERVAL_ENV, value)
+		Expect(GetLinkStatInterval()).To(Equal(expected))
+	},
+		Entry("default", "", DEFAULT_LINK_STAT_INTERVAL*time.Second),
+		Entry("set", "10", 10*time.Second),
+		Entry("disabled", "0", time.Duration(0)),
+		Entry("invalid", "-1", DEFAULT_LINK_STAT_INTERVAL*time.Second),
+	)
+})
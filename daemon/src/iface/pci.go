@@ -24,6 +24,8 @@ const (
 	netClass  = 0x02
 )
 
+var SysClassNet = "/sys/class/net"
+
 var CheckPointfile string = "/var/lib/kubelet/device-plugins/kubelet_internal_checkpoint"
 
 var deviceMapCache = InitSafeCache()
@@ -53,6 +55,41 @@ type NetDeviceInfo struct {
 	Vendor     string
 	Product    string
 	PciAddress string
+	Driver     string
+	NumaNode   *int
+	SRIOV      bool
+}
+
+// readSysfsInt reads integer value from sysfs file
+func readSysfsInt(filePath string) (int, error) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(content)))
+}
+
+// setPciDeviceProperties sets driver, NUMA node and SR-IOV capability from PCI device folder
+func setPciDeviceProperties(netDevice *NetDeviceInfo, pciDir string) {
+	if driverPath, err := os.Readlink(filepath.Join(pciDir, "driver")); err == nil {
+		netDevice.Driver = filepath.Base(driverPath)
+	}
+	// numa_node is -1 if the platform has no NUMA information
+	if numaNode, err := readSysfsInt(filepath.Join(pciDir, "numa_node")); err == nil && numaNode >= 0 {
+		netDevice.NumaNode = &numaNode
+	}
+	if totalVfs, err := readSysfsInt(filepath.Join(pciDir, "sriov_totalvfs")); err == nil && totalVfs > 0 {
+		netDevice.SRIOV = true
+	}
+}
+
+// GetLinkSpeed returns link speed in Mbps, 0 if unknown (e.g., link down or virtual device)
+func GetLinkSpeed(devName string) int {
+	speed, err := readSysfsInt(filepath.Join(SysClassNet, devName, "speed"))
+	if err != nil || speed < 0 {
+		return 0
+	}
+	return speed
 }
 
 func SetDeviceMapCache(pciAddresss, name string) {
@@ -93,32 +130,45 @@ func convertDeviceIDs(deviceIDs interface{}) []string {
 	return deviceIDsArray
 }
 
-// GetPodResourceMap returns a map from resource name to device ID
-func GetPodResourceMap(pod *v1.Pod) (map[string][]string, error) {
-	resourceMap := make(map[string][]string)
-	podID := string(pod.UID)
-	log.Printf("GetPodDeviceIDs: %s (%s)\n", pod.GetName(), podID)
-	if podID == "" {
-		return resourceMap, fmt.Errorf("GetPodResourceMap: invalid Pod cannot be empty")
-	}
+// getCheckpointResources returns devices of the pod from the device manager checkpoint of kubelet
+func getCheckpointResources(pod *v1.Pod) (PodResources, error) {
+	podResources := PodResources{Name: pod.GetName(), Namespace: pod.GetNamespace(), Containers: []ContainerResources{}}
 	cpd, err := getCheckpointData()
 	if err != nil {
-		return resourceMap, err
+		return podResources, err
 	}
-	for _, pod := range cpd.PodDeviceEntries {
-		if pod.PodUID == podID {
-			entry, ok := resourceMap[pod.ResourceName]
-			deviceIDs := convertDeviceIDs(pod.DeviceIDs)
-			if ok {
-				// already exists; append to it
-				entry = append(entry, deviceIDs...)
-			} else {
-				// new entry
-				resourceMap[pod.ResourceName] = deviceIDs
-			}
+	for _, entry := range cpd.PodDeviceEntries {
+		if entry.PodUID == string(pod.UID) {
+			podResources.Containers = append(podResources.Containers, ContainerResources{
+				Name:    entry.ContainerName,
+				Devices: []ContainerDevices{{ResourceName: entry.ResourceName, DeviceIds: convertDeviceIDs(entry.DeviceIDs)}},
+			})
 		}
 	}
-	return resourceMap, nil
+	return podResources, nil
+}
+
+// GetPodAssignedResources returns devices and CPUs assigned to the pod by kubelet PodResources API,
+// or devices from the device manager checkpoint if the API socket does not exist
+func GetPodAssignedResources(pod *v1.Pod) (PodResources, error) {
+	log.Printf("GetPodAssignedResources: %s (%s)\n", pod.GetName(), pod.UID)
+	if pod.UID == "" {
+		return PodResources{}, fmt.Errorf("GetPodAssignedResources: invalid Pod cannot be empty")
+	}
+	if _, err := os.Stat(PodResourcesSocket); os.IsNotExist(err) {
+		log.Printf("%s not found, read checkpoint\n", PodResourcesSocket)
+		return getCheckpointResources(pod)
+	}
+	return GetPodResources(PodResourcesSocket, pod.GetName(), pod.GetNamespace())
+}
+
+// GetPodResourceMap returns a map from resource name to device ID
+func GetPodResourceMap(pod *v1.Pod) (map[string][]string, error) {
+	podResources, err := GetPodAssignedResources(pod)
+	if err != nil {
+		return make(map[string][]string), err
+	}
+	return podResources.GetResourceMap(), nil
 }
 
 func GetDeviceName(deviceID string) string {
@@ -202,6 +252,7 @@ func GetTargetNetworks() []NetDeviceInfo {
 				Product:    productID,
 				PciAddress: pciAddress,
 			}
+			setPciDeviceProperties(&netDevice, filepath.Join(SysBusPci, pciAddress))
 			netDevices = append(netDevices, netDevice)
 		}
 	}
@@ -0,0 +1,189 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package iface
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// client of kubelet PodResources API served by gRPC on the kubelet unix socket
+const (
+	POD_RESOURCES_SOCKET_ENV = "POD_RESOURCES_SOCKET"
+	// DEFAULT_POD_RESOURCES_SOCKET is default kubelet socket of PodResources API
+	DEFAULT_POD_RESOURCES_SOCKET = "/var/lib/kubelet/pod-resources/kubelet.sock"
+
+	podResourcesTimeout = 10 * time.Second
+	// podResourcesMaxMsgSize is the limit of response size used by kubelet clients
+	podResourcesMaxMsgSize = 1024 * 1024 * 16
+)
+
+var PodResourcesSocket = getPodResourcesSocket()
+
+// PodResources is devices and CPUs assigned to the containers of a pod by kubelet
+type PodResources struct {
+	Name       string
+	Namespace  string
+	Containers []ContainerResources
+}
+
+// ContainerResources is devices and exclusive CPUs assigned to a container
+type ContainerResources struct {
+	Name    string
+	Devices []ContainerDevices
+	CPUIds  []int64
+}
+
+// ContainerDevices is devices of a resource assigned to a container with their NUMA affinity
+type ContainerDevices struct {
+	ResourceName string
+	DeviceIds    []string
+	// NumaNodes is NUMA nodes of the devices reported by the device plugin
+	NumaNodes []int64
+}
+
+func getPodResourcesSocket() string {
+	if socket, found := os.LookupEnv(POD_RESOURCES_SOCKET_ENV); found && socket != "" {
+		return socket
+	}
+	return DEFAULT_POD_RESOURCES_SOCKET
+}
+
+// newPodResources converts pod resources of PodResources API
+func newPodResources(pod *podresourcesv1.PodResources) PodResources {
+	podResources := PodResources{Name: pod.GetName(), Namespace: pod.GetNamespace(), Containers: []ContainerResources{}}
+	for _, container := range pod.GetContainers() {
+		containerResources := ContainerResources{Name: container.GetName(), Devices: []ContainerDevices{}, CPUIds: []int64{}}
+		for _, devices := range container.GetDevices() {
+			containerDevices := ContainerDevices{ResourceName: devices.GetResourceName(), DeviceIds: []string{}, NumaNodes: []int64{}}
+			containerDevices.DeviceIds = append(containerDevices.DeviceIds, devices.GetDeviceIds()...)
+			for _, numaNode := range devices.GetTopology().GetNodes() {
+				containerDevices.NumaNodes = append(containerDevices.NumaNodes, numaNode.GetID())
+			}
+			containerResources.Devices = append(containerResources.Devices, containerDevices)
+		}
+		containerResources.CPUIds = append(containerResources.CPUIds, container.GetCpuIds()...)
+		podResources.Containers = append(podResources.Containers, containerResources)
+	}
+	return podResources
+}
+
+// dialPodResources connects to PodResources API on the kubelet socket
+func dialPodResources(ctx context.Context, socketPath string) (*grpc.ClientConn, error) {
+	conn, err := grpc.DialContext(ctx, socketPath,
+		grpc.WithInsecure(),
+		grpc.WithBlock(),
+		grpc.FailOnNonTempDialError(true),
+		grpc.WithContextDialer(func(ctx context.Context, addr string) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, "unix", addr)
+		}),
+		grpc.WithDefaultCallOptions(grpc.MaxCallRecvMsgSize(podResourcesMaxMsgSize)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to %s: %v", socketPath, err)
+	}
+	return conn, nil
+}
+
+// listPodResources returns resources of all pods on the node by PodResourcesLister/List
+func listPodResources(ctx context.Context, client podresourcesv1.PodResourcesListerClient) ([]PodResources, error) {
+	response, err := client.List(ctx, &podresourcesv1.ListPodResourcesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	podResourcesList := []PodResources{}
+	for _, pod := range response.GetPodResources() {
+		podResourcesList = append(podResourcesList, newPodResources(pod))
+	}
+	return podResourcesList, nil
+}
+
+// ListPodResources returns resources of all pods on the node by PodResourcesLister/List
+func ListPodResources(socketPath string) ([]PodResources, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), podResourcesTimeout)
+	defer cancel()
+	conn, err := dialPodResources(ctx, socketPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return listPodResources(ctx, podresourcesv1.NewPodResourcesListerClient(conn))
+}
+
+// GetPodResources returns resources of the pod by PodResourcesLister/Get,
+// or from the list of all pods if kubelet does not serve Get (KubeletPodResourcesGet feature gate disabled)
+func GetPodResources(socketPath, podName, podNamespace string) (PodResources, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), podResourcesTimeout)
+	defer cancel()
+	conn, err := dialPodResources(ctx, socketPath)
+	if err != nil {
+		return PodResources{}, err
+	}
+	defer conn.Close()
+	client := podresourcesv1.NewPodResourcesListerClient(conn)
+	response, err := client.Get(ctx, &podresourcesv1.GetPodResourcesRequest{PodName: podName, PodNamespace: podNamespace})
+	if err == nil {
+		return newPodResources(response.GetPodResources()), nil
+	}
+	if status.Code(err) != codes.Unimplemented {
+		return PodResources{}, err
+	}
+	podResourcesList, err := listPodResources(ctx, client)
+	if err != nil {
+		return PodResources{}, err
+	}
+	for _, podResources := range podResourcesList {
+		if podResources.Name == podName && podResources.Namespace == podNamespace {
+			return podResources, nil
+		}
+	}
+	return PodResources{}, fmt.Errorf("pod %s/%s not found in pod resources", podNamespace, podName)
+}
+
+// GetResourceMap returns device IDs of the pod by resource name
+func (r PodResources) GetResourceMap() map[string][]string {
+	resourceMap := make(map[string][]string)
+	for _, container := range r.Containers {
+		for _, devices := range container.Devices {
+			resourceMap[devices.ResourceName] = append(resourceMap[devices.ResourceName], devices.DeviceIds...)
+		}
+	}
+	return resourceMap
+}
+
+// GetDeviceNumaNodes returns NUMA nodes of each device ID reported by the device plugin
+func (r PodResources) GetDeviceNumaNodes() map[string][]int64 {
+	numaNodes := make(map[string][]int64)
+	for _, container := range r.Containers {
+		for _, devices := range container.Devices {
+			if len(devices.NumaNodes) == 0 {
+				continue
+			}
+			for _, deviceID := range devices.DeviceIds {
+				numaNodes[deviceID] = devices.NumaNodes
+			}
+		}
+	}
+	return numaNodes
+}
+
+// GetCPUIds returns exclusive CPUs of the pod
+func (r PodResources) GetCPUIds() []int64 {
+	cpuIds := []int64{}
+	for _, container := range r.Containers {
+		cpuIds = append(cpuIds, container.CPUIds...)
+	}
+	return cpuIds
+}
@@ -0,0 +1,184 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package iface
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	podresourcesv1 "k8s.io/kubelet/pkg/apis/podresources/v1"
+)
+
+// fakePodResourcesServer serves PodResourcesLister on a unix socket,
+// Get is unimplemented as kubelet without KubeletPodResourcesGet feature gate if getEnabled is false
+type fakePodResourcesServer struct {
+	podresourcesv1.UnimplementedPodResourcesListerServer
+	pods       []*podresourcesv1.PodResources
+	getEnabled bool
+	listCalls  int
+	server     *grpc.Server
+}
+
+func (s *fakePodResourcesServer) List(ctx context.Context, req *podresourcesv1.ListPodResourcesRequest) (*podresourcesv1.ListPodResourcesResponse, error) {
+	s.listCalls += 1
+	return &podresourcesv1.ListPodResourcesResponse{PodResources: s.pods}, nil
+}
+
+func (s *fakePodResourcesServer) Get(ctx context.Context, req *podresourcesv1.GetPodResourcesRequest) (*podresourcesv1.GetPodResourcesResponse, error) {
+	if !s.getEnabled {
+		return nil, status.Error(codes.Unimplemented, "PodResources API Get method disabled")
+	}
+	for _, pod := range s.pods {
+		if pod.GetName() == req.GetPodName() && pod.GetNamespace() == req.GetPodNamespace() {
+			return &podresourcesv1.GetPodResourcesResponse{PodResources: pod}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "pod %s not found in namespace %s", req.GetPodName(), req.GetPodNamespace())
+}
+
+func startFakePodResourcesServer(socketPath string, pods []*podresourcesv1.PodResources, getEnabled bool) *fakePodResourcesServer {
+	listener, err := net.Listen("unix", socketPath)
+	Expect(err).NotTo(HaveOccurred())
+	s := &fakePodResourcesServer{pods: pods, getEnabled: getEnabled, server: grpc.NewServer()}
+	podresourcesv1.RegisterPodResourcesListerServer(s.server, s)
+	go s.server.Serve(listener)
+	return s
+}
+
+var _ = Describe("Test PodResources API", func() {
+	pods := []PodResources{
+		{
+			Name:      "other",
+			Namespace: "default",
+			Containers: []ContainerResources{
+				{Name: "c", Devices: []ContainerDevices{}, CPUIds: []int64{}},
+			},
+		},
+		{
+			Name:      "pod",
+			Namespace: "default",
+			Containers: []ContainerResources{
+				{
+					Name: "main",
+					Devices: []ContainerDevices{
+						{ResourceName: "nvidia.com/gpu", DeviceIds: []string{"GPU-0", "GPU-1"}, NumaNodes: []int64{1}},
+						{ResourceName: "openshift.io/rdma", DeviceIds: []string{"0000:81:00.0"}, NumaNodes: []int64{}},
+					},
+					CPUIds: []int64{2, 3, 130},
+				},
+				{
+					Name:    "sidecar",
+					Devices: []ContainerDevices{},
+					CPUIds:  []int64{},
+				},
+			},
+		},
+	}
+
+	numaTopology := &podresourcesv1.TopologyInfo{Nodes: []*podresourcesv1.NUMANode{{ID: 1}}}
+	apiPods := []*podresourcesv1.PodResources{
+		{
+			Name:       "other",
+			Namespace:  "default",
+			Containers: []*podresourcesv1.ContainerResources{{Name: "c"}},
+		},
+		{
+			Name:      "pod",
+			Namespace: "default",
+			Containers: []*podresourcesv1.ContainerResources{
+				{
+					Name: "main",
+					Devices: []*podresourcesv1.ContainerDevices{
+						{ResourceName: "nvidia.com/gpu", DeviceIds: []string{"GPU-0", "GPU-1"}, Topology: numaTopology},
+						{ResourceName: "openshift.io/rdma", DeviceIds: []string{"0000:81:00.0"}},
+					},
+					CpuIds: []int64{2, 3, 130},
+				},
+				{Name: "sidecar"},
+			},
+		},
+	}
+
+	var socketPath string
+	var server *fakePodResourcesServer
+
+	startServer := func(getEnabled bool) {
+		// unix socket path is limited to 108 characters
+		socketDir, err := os.MkdirTemp("", "podres")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, socketDir)
+		socketPath = filepath.Join(socketDir, "kubelet.sock")
+		server = startFakePodResourcesServer(socketPath, apiPods, getEnabled)
+		DeferCleanup(server.server.Stop)
+	}
+
+	expectPodResources := func(podResources PodResources) {
+		Expect(podResources).To(Equal(pods[1]))
+		Expect(podResources.GetResourceMap()).To(Equal(map[string][]string{
+			"nvidia.com/gpu":    {"GPU-0", "GPU-1"},
+			"openshift.io/rdma": {"0000:81:00.0"},
+		}))
+		Expect(podResources.GetDeviceNumaNodes()).To(Equal(map[string][]int64{
+			"GPU-0": {1},
+			"GPU-1": {1},
+		}))
+		Expect(podResources.GetCPUIds()).To(Equal([]int64{2, 3, 130}))
+	}
+
+	Context("kubelet with Get", func() {
+		BeforeEach(func() {
+			startServer(true)
+		})
+
+		It("gets resources of the pod without listing", func() {
+			podResources, err := GetPodResources(socketPath, "pod", "default")
+			Expect(err).NotTo(HaveOccurred())
+			expectPodResources(podResources)
+			Expect(server.listCalls).To(Equal(0))
+		})
+
+		It("fails for pod without resources", func() {
+			_, err := GetPodResources(socketPath, "missing", "default")
+			Expect(status.Code(err)).To(Equal(codes.NotFound))
+			Expect(server.listCalls).To(Equal(0))
+		})
+	})
+
+	Context("kubelet without Get", func() {
+		BeforeEach(func() {
+			startServer(false)
+		})
+
+		It("gets resources of the pod from the list", func() {
+			podResources, err := GetPodResources(socketPath, "pod", "default")
+			Expect(err).NotTo(HaveOccurred())
+			expectPodResources(podResources)
+			Expect(server.listCalls).To(Equal(1))
+		})
+		It("lists resources of all pods", func() {
+			podResourcesList, err := ListPodResources(socketPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podResourcesList).To(Equal(pods))
+		})
+
+		It("fails for pod without resources", func() {
+			_, err := GetPodResources(socketPath, "missing", "default")
+			Expect(err).To(MatchError(ContainSubstring("not found")))
+		})
+
+		It("fails without kubelet socket", func() {
+			_, err := ListPodResources(filepath.Join(filepath.Dir(socketPath), "missing.sock"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
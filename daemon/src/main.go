@@ -13,6 +13,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
@@ -24,6 +25,7 @@ import (
 	di "github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
 	dr "github.com/foundation-model-stack/multi-nic-cni/daemon/router"
 	ds "github.com/foundation-model-stack/multi-nic-cni/daemon/selector"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -50,6 +52,8 @@ const (
 
 	NIC_SELECT_PATH = "/select"
 
+	METRICS_PATH = "/metrics"
+
 	NODENAME_ENV = "K8S_NODENAME"
 )
 
@@ -68,6 +72,7 @@ func handleRequests() *mux.Router {
 	router.HandleFunc(NIC_SELECT_PATH, SelectNic).Methods("POST")
 	router.HandleFunc(ALLOCATE_PATH, Allocate).Methods("POST")
 	router.HandleFunc(DEALLOCATE_PATH, Deallocate).Methods("POST")
+	router.HandleFunc(METRICS_PATH, Metrics)
 	return router
 }
 
@@ -207,6 +212,12 @@ func Allocate(w http.ResponseWriter, r *http.Request) {
 	if err == nil {
 		log.Println(fmt.Sprintf("request: %v", req))
 		ipResponses = da.AllocateIP(req)
+		allocatedMasters := []string{}
+		for _, ipResponse := range ipResponses {
+			allocatedMasters = append(allocatedMasters, ipResponse.InterfaceName)
+		}
+		// selection of the pod in the network is no longer pending once the addresses are allocated
+		ds.ConfirmSelection(req.PodNamespace, req.PodName, req.NetAttachDefName, allocatedMasters)
 		elapsed := time.Since(startAllocate)
 		log.Println(fmt.Sprintf("%s WaitAndAllocate elapsed: %d us", req.HostName, int64(elapsed/time.Microsecond)))
 		log.Println(fmt.Sprintf("return: %v", ipResponses))
@@ -232,6 +243,11 @@ func Deallocate(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(ipResponses)
 }
 
+func Metrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	da.WriteMetrics(w)
+}
+
 func InitClient() *rest.Config {
 	var config *rest.Config
 	var err error
@@ -252,14 +268,55 @@ func InitClient() *rest.Config {
 
 func initHandlers(config *rest.Config) {
 	da.IppoolHandler = backend.NewIPPoolHandler(config)
+	da.IppoolCache = da.NewIPPoolCache(da.IppoolHandler)
+	da.IpreservationHandler = backend.NewIPReservationHandler(config)
+	da.IpreservationCache = da.NewIPReservationCache(da.IpreservationHandler)
 	ds.MultinicnetHandler = backend.NewMultiNicNetworkHandler(config)
 	ds.NetAttachDefHandler = backend.NewNetAttachDefHandler(config)
 	ds.DeviceClassHandler = backend.NewDeviceClassHandler(config)
+	ds.GetAttachedPods = da.IppoolCache.GetAttachedPods
 	da.K8sClientset, _ = kubernetes.NewForConfig(config)
 	ds.K8sClientset, _ = kubernetes.NewForConfig(config)
+	if da.K8sClientset != nil {
+		podEventHandler := backend.NewPodEventHandler(da.K8sClientset, hostName)
+		da.PodEventHandler = podEventHandler
+		ds.PodEventHandler = podEventHandler
+	}
 	di.HostInterfaceHandler = backend.NewHostInterfaceHandler(config, hostName)
 }
 
+// startLinkSampler samples link status of host interfaces once for link health, perfOpt and the HostInterface report
+func startLinkSampler() {
+	interval := di.GetLinkSampleInterval()
+	handlers := []di.LinkSampleHandler{di.LinkHealthMonitor.Sample, ds.PerfMonitor.Sample}
+	if reporter := di.NewLinkStatReporter(di.GetLinkStatInterval(), interval); reporter != nil {
+		handlers = append(handlers, reporter.Report)
+	}
+	ds.PerfMonitor.SetInterval(interval)
+	go di.RunLinkSampler(interval, handlers...)
+}
+
+// initAllocationJournal opens the allocation journal and replays intents left by the previous daemon,
+// the remaining intents are replayed again before the next allocation
+func initAllocationJournal() {
+	journalPath := da.GetAllocationJournalPath()
+	journal, err := da.OpenAllocationJournal(journalPath)
+	if err != nil {
+		log.Printf("Cannot open allocation journal, run without journal: %v", err)
+		return
+	}
+	if onHost, err := da.IsOnHostMount(journalPath, da.MOUNT_INFO_PATH); err != nil {
+		log.Printf("Cannot check mount of allocation journal %s: %v", journalPath, err)
+	} else if !onHost {
+		log.Printf("WARNING: allocation journal %s is not on a host mount and is lost when the daemon pod is recreated, "+
+			"add hostPathMount of %s to daemon of the Config", journalPath, filepath.Dir(journalPath))
+	}
+	da.IppoolCache.SetJournal(journal)
+	if err := da.IppoolCache.ReplayJournal(); err != nil {
+		log.Printf("Cannot replay allocation journal: %v", err)
+	}
+}
+
 func initHostName() {
 	var err error
 	var found bool
@@ -285,7 +342,13 @@ func main() {
 	}
 	dr.SetRTTablePath()
 	ds.InitCache(cfg, hostName)
+	initAllocationJournal()
 	da.CleanHangingAllocation(hostName)
+	// allocations read through API server until IPPool cache is synced
+	go da.IppoolCache.Start(da.IppoolHandler, hostName, wait.NeverStop)
+	go da.IpreservationCache.Start(da.IpreservationHandler, wait.NeverStop)
+	go da.StartAllocationGC(da.K8sClientset, hostName, da.GetAllocationGCInterval(), da.GetAllocationGCGracePeriod(), wait.NeverStop)
+	startLinkSampler()
 	router := handleRequests()
 	daemonAddress := fmt.Sprintf("0.0.0.0:%d", DAEMON_PORT)
 	log.Printf("Serving at %s", daemonAddress)
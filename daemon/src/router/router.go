@@ -22,6 +22,18 @@ type L3ConfigRequest struct {
 	Subnet string      `json:"subnet"`
 	Routes []HostRoute `json:"routes"`
 	Force  bool        `json:"force"`
+	Rule   *PolicyRule `json:"rule,omitempty"`
+}
+
+// PolicyRule defines priority and selectors of the rules to the table in addition to the source subnet
+// so that the table can coexist with policy routing of the other users on the host
+type PolicyRule struct {
+	// Priority of the rules, assigned by the kernel if not set
+	Priority int `json:"priority,omitempty"`
+	// FwMark selects packets with the firewall mark in the form of mark or mark/mask (e.g., 0x10/0xff)
+	FwMark string `json:"fwmark,omitempty"`
+	// Iif selects packets from the input interface (e.g., lo for packets from the host)
+	Iif string `json:"iif,omitempty"`
 }
 
 type HostRoute struct {
@@ -160,13 +172,66 @@ func GetRoutes(tableID int) ([]netlink.Route, error) {
 	findTable := &netlink.Route{Table: tableID}
 	routeFilter := netlink.RT_FILTER_TABLE
 
-	family := netlink.FAMILY_V4
+	family := netlink.FAMILY_ALL
 
 	return netlink.RouteListFiltered(family, findTable, routeFilter)
 }
 
+// getFamily returns netlink address family of the IP
+func getFamily(ip net.IP) int {
+	if ip != nil && ip.To4() == nil {
+		return netlink.FAMILY_V6
+	}
+	return netlink.FAMILY_V4
+}
+
+// parseRouteDst parses route destination as CIDR or as a single address of either family (host route)
+func parseRouteDst(subnet string) (*net.IPNet, error) {
+	_, dst, err := net.ParseCIDR(subnet)
+	if err == nil {
+		return dst, nil
+	}
+	ip := net.ParseIP(subnet)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid route destination %s", subnet)
+	}
+	if getFamily(ip) == netlink.FAMILY_V4 {
+		return &net.IPNet{IP: ip.To4(), Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+// parseHostRoute returns destination and next hop of the route, both must be in the same family
+func parseHostRoute(hostRoute HostRoute) (*net.IPNet, net.IP, error) {
+	dst, err := parseRouteDst(hostRoute.Subnet)
+	if err != nil {
+		return nil, nil, err
+	}
+	if hostRoute.NextHop == "" {
+		return dst, nil, nil
+	}
+	nextHop := net.ParseIP(hostRoute.NextHop)
+	if nextHop == nil {
+		return nil, nil, fmt.Errorf("invalid next hop %s", hostRoute.NextHop)
+	}
+	if getFamily(nextHop) != getFamily(dst.IP) {
+		return nil, nil, fmt.Errorf("next hop %s is not in the family of %s", hostRoute.NextHop, hostRoute.Subnet)
+	}
+	return dst, nextHop, nil
+}
+
 func isRouteExist(cmpRoute netlink.Route, dev netlink.Link) (bool, error) {
-	routes, err := netlink.RouteList(dev, netlink.FAMILY_V4)
+	family := netlink.FAMILY_ALL
+	if cmpRoute.Dst != nil {
+		family = getFamily(cmpRoute.Dst.IP)
+	}
+	// routes in the other tables than main are only listed by table filter
+	filter := &netlink.Route{LinkIndex: dev.Attrs().Index, Table: cmpRoute.Table}
+	filterMask := netlink.RT_FILTER_OIF
+	if cmpRoute.Table != 0 {
+		filterMask |= netlink.RT_FILTER_TABLE
+	}
+	routes, err := netlink.RouteListFiltered(family, filter, filterMask)
 	if err != nil {
 		return false, err
 	}
@@ -198,7 +263,7 @@ func getRoutesFromL3Config(req L3ConfigRequest, addIfNotExists bool) (string, in
 	devRoutesMap := make(map[netlink.Link][]netlink.Route)
 	if req.Force {
 		tableID, err := GetTableID(req.Name, req.Subnet, false)
-		if err == nil {
+		if err == nil && tableID != -1 {
 			deleteL3Config(req.Name, tableID)
 			log.Printf("force delete %s (%d)", req.Name, tableID)
 		} else {
@@ -206,7 +271,7 @@ func getRoutesFromL3Config(req L3ConfigRequest, addIfNotExists bool) (string, in
 		}
 	}
 
-	tableID, err := GetTableID(req.Name, req.Subnet, addIfNotExists)
+	tableID, err := GetTableIDWithRule(req.Name, req.Subnet, req.Rule, addIfNotExists)
 	if tableID == -1 || err != nil {
 		return req.Name, tableID, devRoutesMap, err
 	}
@@ -216,11 +281,14 @@ func getRoutesFromL3Config(req L3ConfigRequest, addIfNotExists bool) (string, in
 		if err != nil {
 			continue
 		}
+		dst, nextHop, err := parseHostRoute(hostRoute)
+		if err != nil {
+			log.Printf("skip route of %s: %v", req.Name, err)
+			continue
+		}
 		if _, ok := devRoutesMap[dev]; !ok {
 			devRoutesMap[dev] = []netlink.Route{}
 		}
-		nextHop := net.ParseIP(hostRoute.NextHop)
-		_, dst, _ := net.ParseCIDR(hostRoute.Subnet)
 		route := netlink.Route{
 			LinkIndex: dev.Attrs().Index,
 			Scope:     netlink.SCOPE_UNIVERSE,
@@ -251,8 +319,10 @@ func getRouteFromRequest(r *http.Request) (netlink.Route, netlink.Link, error) {
 	if err != nil {
 		return route, dev, err
 	}
-	nextHop := net.ParseIP(req.NextHop)
-	_, dst, _ := net.ParseCIDR(req.Subnet)
+	dst, nextHop, err := parseHostRoute(req)
+	if err != nil {
+		return route, dev, err
+	}
 	route = netlink.Route{
 		LinkIndex: dev.Attrs().Index,
 		Scope:     netlink.SCOPE_UNIVERSE,
@@ -139,6 +139,133 @@ var _ = Describe("Test Route", Ordered, func() {
 		})
 	})
 
+	Context("Dual-stack table", Ordered, func() {
+		var testTableName = "dualtable"
+		var subnet = "192.168.0.0/16,fd00:1::/48"
+		var dst = "fd00:1:0:1::/64"
+
+		AfterAll(func() {
+			tableID, _ := GetTableID(testTableName, subnet, false)
+			if tableID != -1 {
+				DeleteTable(testTableName, tableID)
+			}
+		})
+
+		It("adds and deletes rules of both families", func() {
+			tableID, err := GetTableID(testTableName, subnet, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tableID).Should(BeNumerically(">", 0))
+			Expect(countRules(netlink.FAMILY_V4, tableID)).To(Equal(1))
+			Expect(countRules(netlink.FAMILY_V6, tableID)).To(Equal(1))
+
+			By("Getting IPv6 routes")
+			req := L3ConfigRequest{
+				Name:   testTableName,
+				Subnet: subnet,
+				Routes: []HostRoute{
+					{
+						Subnet:        dst,
+						NextHop:       "::",
+						InterfaceName: getValidIface(),
+					},
+				},
+			}
+			_, tid, devRoutesMap, err := getRoutesFromL3Config(req, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tid).To(Equal(tableID))
+			Expect(devRoutesMap).To(HaveLen(1))
+			for _, routes := range devRoutesMap {
+				Expect(routes).To(HaveLen(1))
+				Expect(routes[0].Dst.String()).To(BeEquivalentTo(dst))
+			}
+
+			By("Deleting table")
+			err = DeleteTable(testTableName, tableID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(countRules(netlink.FAMILY_V4, tableID)).To(Equal(0))
+			Expect(countRules(netlink.FAMILY_V6, tableID)).To(Equal(0))
+		})
+	})
+
+	Context("Policy rule", Ordered, func() {
+		var testTableName = "ruletable"
+		var subnet = "192.168.0.0/16,fd00:1::/48"
+
+		AfterAll(func() {
+			tableID, _ := GetTableID(testTableName, subnet, false)
+			if tableID != -1 {
+				DeleteTable(testTableName, tableID)
+			}
+		})
+
+		DescribeTable("builds rules of each subnet", func(subnet string, policyRule *PolicyRule, expectedRules []string, expectErr bool) {
+			rules, err := newRules(subnet, 100, policyRule)
+			if expectErr {
+				Expect(err).To(HaveOccurred())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+			descriptions := []string{}
+			for _, rule := range rules {
+				descriptions = append(descriptions, describeRule(*rule))
+			}
+			Expect(descriptions).To(Equal(expectedRules))
+		},
+			Entry("source only", "192.168.0.0/16", nil, []string{"ip rule -1: from 192.168.0.0/16 lookup 100"}, false),
+			Entry("dual-stack with selectors", subnet, &PolicyRule{Priority: 1000, FwMark: "0x10/0xff", Iif: "lo"}, []string{
+				"ip rule 1000: from 192.168.0.0/16 fwmark 0x10/0xff iif lo lookup 100",
+				"ip rule 1000: from fd00:1::/48 fwmark 0x10/0xff iif lo lookup 100",
+			}, false),
+			Entry("fwmark without mask", "fd00:1::/48", &PolicyRule{FwMark: "16"}, []string{"ip rule -1: from fd00:1::/48 fwmark 0x10 lookup 100"}, false),
+			Entry("invalid fwmark", subnet, &PolicyRule{FwMark: "0x10/mask"}, []string{}, true),
+			Entry("invalid subnet", "192.168.0.0/16,invalid", nil, []string{"ip rule -1: from 192.168.0.0/16 lookup 100"}, true),
+		)
+
+		DescribeTable("parses route of either family", func(hostRoute HostRoute, expectedDst, expectedNextHop string, expectErr bool) {
+			dst, nextHop, err := parseHostRoute(hostRoute)
+			if expectErr {
+				Expect(err).To(HaveOccurred())
+				return
+			}
+			Expect(err).NotTo(HaveOccurred())
+			Expect(dst.String()).To(Equal(expectedDst))
+			Expect(nextHop.String()).To(Equal(expectedNextHop))
+		},
+			Entry("IPv4 subnet", HostRoute{Subnet: "192.168.1.0/24", NextHop: "10.0.1.2"}, "192.168.1.0/24", "10.0.1.2", false),
+			Entry("IPv4 host", HostRoute{Subnet: "192.168.1.1", NextHop: "10.0.1.2"}, "192.168.1.1/32", "10.0.1.2", false),
+			Entry("IPv6 subnet", HostRoute{Subnet: "fd00:1:0:1::/64", NextHop: "fd00::2"}, "fd00:1:0:1::/64", "fd00::2", false),
+			Entry("IPv6 host", HostRoute{Subnet: "fd00:1:0:1::1", NextHop: "fd00::2"}, "fd00:1:0:1::1/128", "fd00::2", false),
+			Entry("without next hop", HostRoute{Subnet: "fd00:1:0:1::/64"}, "fd00:1:0:1::/64", "<nil>", false),
+			Entry("mixed family", HostRoute{Subnet: "fd00:1:0:1::/64", NextHop: "10.0.1.2"}, "", "", true),
+			Entry("invalid destination", HostRoute{Subnet: "invalid", NextHop: "10.0.1.2"}, "", "", true),
+		)
+
+		It("adds, syncs and deletes rules with selectors", func() {
+			policyRule := &PolicyRule{Priority: 1000, FwMark: "0x10/0xff", Iif: "lo"}
+			tableID, err := GetTableIDWithRule(testTableName, subnet, policyRule, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tableID).Should(BeNumerically(">", 0))
+			expectRules(tableID, 1000, 2)
+
+			By("Getting without adding")
+			_, err = GetTableIDWithRule(testTableName, subnet, nil, false)
+			Expect(err).NotTo(HaveOccurred())
+			expectRules(tableID, 1000, 2)
+
+			By("Changing priority and subnet")
+			policyRule.Priority = 1001
+			_, err = GetTableIDWithRule(testTableName, subnet+",172.16.0.0/16", policyRule, true)
+			Expect(err).NotTo(HaveOccurred())
+			expectRules(tableID, 1001, 3)
+
+			By("Deleting table")
+			err = DeleteTable(testTableName, tableID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(countRules(netlink.FAMILY_V4, tableID)).To(Equal(0))
+			Expect(countRules(netlink.FAMILY_V6, tableID)).To(Equal(0))
+		})
+	})
+
 	Context("API", func() {
 		DescribeTable("ApplyL3Config/DeleteL3Config", Ordered, func(applyReq, deleteReq *http.Request,
 			expectedAppliedSuccess, expectedDeleteSuccess bool) {
@@ -221,6 +348,30 @@ func httpRouteRequest(subnet, dst string) *http.Request {
 	return req
 }
 
+func countRules(family, tableID int) int {
+	rules, err := netlink.RuleList(family)
+	Expect(err).NotTo(HaveOccurred())
+	count := 0
+	for _, rule := range rules {
+		if rule.Table == tableID {
+			count += 1
+		}
+	}
+	return count
+}
+
+func expectRules(tableID, priority, expectedCount int) {
+	rules, err := listRules(tableID)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rules).To(HaveLen(expectedCount))
+	for _, rule := range rules {
+		Expect(rule.Priority).To(Equal(priority))
+		Expect(rule.Mark).To(Equal(0x10))
+		Expect(rule.Mask).To(Equal(0xff))
+		Expect(rule.IifName).To(Equal("lo"))
+	}
+}
+
 func getValidIface() string {
 	links, err := netlink.LinkList()
 	Expect(err).NotTo(HaveOccurred())
@@ -37,6 +37,12 @@ func SetRTTablePath() {
 }
 
 func GetTableID(tableName string, subnet string, addIfNotExists bool) (int, error) {
+	return GetTableIDWithRule(tableName, subnet, nil, addIfNotExists)
+}
+
+// GetTableIDWithRule returns ID of the table and makes sure that the policy routing rules from the subnet to the table exist,
+// rules of the table are reconciled to the rule selectors (priority, fwmark, iif) when adding
+func GetTableIDWithRule(tableName string, subnet string, policyRule *PolicyRule, addIfNotExists bool) (int, error) {
 	foundID, reservedIDs, err := getTableIDAndReservedIDs(tableName)
 	if err != nil {
 		log.Printf("failed to get table ID %s: %v (%d)", tableName, err, foundID)
@@ -47,11 +53,13 @@ func GetTableID(tableName string, subnet string, addIfNotExists bool) (int, erro
 		if err == nil {
 			// delete existing rule
 			deleteRule(foundID)
-			err = addRule(subnet, foundID)
+			err = addRule(subnet, foundID, policyRule)
 		}
+	} else if addIfNotExists && isRuleExist(foundID) {
+		err = syncRules(subnet, foundID, policyRule)
 	}
 	if foundID != -1 && !isRuleExist(foundID) {
-		err = addRule(subnet, foundID)
+		err = addRule(subnet, foundID, policyRule)
 	}
 	return foundID, err
 }
@@ -76,21 +84,164 @@ func DeleteTable(tableName string, tableID int) error {
 	return err
 }
 
-func addRule(subnet string, tableID int) error {
+// addRule adds source rule to the table for each subnet
+// subnet can be a comma-separated list (e.g., dual-stack pair "10.0.0.0/16,fd00::/64")
+func addRule(subnet string, tableID int, policyRule *PolicyRule) error {
 	if tableID == -1 {
 		return errors.New("add rule tableID = -1")
 	}
-	_, src, _ := net.ParseCIDR(subnet)
-	rule := netlink.NewRule()
-	rule.Src = src
-	rule.Table = tableID
-	err := netlink.RuleAdd(rule)
-	log.Printf("add rule %v:%v", rule, err)
+	rules, err := newRules(subnet, tableID, policyRule)
+	for _, rule := range rules {
+		addErr := netlink.RuleAdd(rule)
+		log.Printf("add rule %s:%v", describeRule(*rule), addErr)
+		if addErr != nil {
+			err = addErr
+		}
+	}
 	return err
 }
 
+// newRules returns rules from each subnet to the table with the selectors of the policy rule,
+// the family of each rule follows the subnet
+func newRules(subnet string, tableID int, policyRule *PolicyRule) ([]*netlink.Rule, error) {
+	rules := []*netlink.Rule{}
+	mark, mask := -1, -1
+	if policyRule != nil && policyRule.FwMark != "" {
+		var err error
+		mark, mask, err = parseFwMark(policyRule.FwMark)
+		if err != nil {
+			return rules, err
+		}
+	}
+	var err error
+	for _, item := range strings.Split(subnet, ",") {
+		_, src, parseErr := net.ParseCIDR(strings.TrimSpace(item))
+		if parseErr != nil {
+			err = parseErr
+			continue
+		}
+		rule := netlink.NewRule()
+		rule.Src = src
+		rule.Table = tableID
+		rule.Family = getFamily(src.IP)
+		rule.Mark = mark
+		rule.Mask = mask
+		if policyRule != nil {
+			if policyRule.Priority > 0 {
+				rule.Priority = policyRule.Priority
+			}
+			rule.IifName = policyRule.Iif
+		}
+		rules = append(rules, rule)
+	}
+	return rules, err
+}
+
+// parseFwMark parses fwmark selector in the form of mark or mark/mask (e.g., 0x10/0xff)
+func parseFwMark(fwmark string) (int, int, error) {
+	mask := -1
+	splited := strings.SplitN(fwmark, "/", 2)
+	mark, err := strconv.ParseUint(strings.TrimSpace(splited[0]), 0, 32)
+	if err != nil {
+		return -1, -1, fmt.Errorf("invalid fwmark %s: %v", fwmark, err)
+	}
+	if len(splited) == 2 {
+		parsedMask, err := strconv.ParseUint(strings.TrimSpace(splited[1]), 0, 32)
+		if err != nil {
+			return -1, -1, fmt.Errorf("invalid fwmark mask %s: %v", fwmark, err)
+		}
+		mask = int(parsedMask)
+	}
+	return int(mark), mask, nil
+}
+
+// listRules lists rules of the table in both families
+func listRules(tableID int) ([]netlink.Rule, error) {
+	tableRules := []netlink.Rule{}
+	for _, family := range []int{netlink.FAMILY_V4, netlink.FAMILY_V6} {
+		rules, err := netlink.RuleList(family)
+		if err != nil {
+			return tableRules, err
+		}
+		for _, rule := range rules {
+			if rule.Table == tableID {
+				// family is not set by listing
+				rule.Family = family
+				tableRules = append(tableRules, rule)
+			}
+		}
+	}
+	return tableRules, nil
+}
+
+// syncRules replaces source rules of the table if they differ from the subnet and the selectors,
+// rules without source are not added by the daemon and kept as they are
+func syncRules(subnet string, tableID int, policyRule *PolicyRule) error {
+	expectedRules, err := newRules(subnet, tableID, policyRule)
+	if err != nil {
+		return err
+	}
+	rules, err := listRules(tableID)
+	if err != nil {
+		return err
+	}
+	sourceRules := []netlink.Rule{}
+	for _, rule := range rules {
+		if rule.Src != nil {
+			sourceRules = append(sourceRules, rule)
+		}
+	}
+	if len(sourceRules) == 0 || isRuleMatched(sourceRules, expectedRules) {
+		return nil
+	}
+	log.Printf("rules of table %d changed", tableID)
+	for _, rule := range sourceRules {
+		delErr := netlink.RuleDel(&rule)
+		log.Printf("delete rule %s:%v", describeRule(rule), delErr)
+	}
+	return addRule(subnet, tableID, policyRule)
+}
+
+// isRuleMatched checks if the existing rules are exactly the expected rules,
+// priority and fwmask are compared only if specified as they are otherwise assigned by the kernel
+func isRuleMatched(rules []netlink.Rule, expectedRules []*netlink.Rule) bool {
+	if len(rules) != len(expectedRules) {
+		return false
+	}
+	for _, expected := range expectedRules {
+		found := false
+		for _, rule := range rules {
+			if rule.Src.String() == expected.Src.String() && rule.IifName == expected.IifName &&
+				rule.Mark == expected.Mark && (expected.Mark < 0 || expected.Mask < 0 || rule.Mask == expected.Mask) &&
+				(expected.Priority < 0 || rule.Priority == expected.Priority) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// describeRule returns the rule in ip-rule format
+func describeRule(rule netlink.Rule) string {
+	desc := fmt.Sprintf("ip rule %d: from %s", rule.Priority, rule.Src)
+	if rule.Mark >= 0 {
+		desc += fmt.Sprintf(" fwmark %#x", rule.Mark)
+		if rule.Mask >= 0 {
+			desc += fmt.Sprintf("/%#x", rule.Mask)
+		}
+	}
+	if rule.IifName != "" {
+		desc += " iif " + rule.IifName
+	}
+	return desc + fmt.Sprintf(" lookup %d", rule.Table)
+}
+
 func isRuleExist(tableID int) bool {
-	family := netlink.FAMILY_V4
+	family := netlink.FAMILY_ALL
 	rules, err := netlink.RuleList(family)
 	if err != nil {
 		return false
@@ -157,19 +308,33 @@ func addTable(tableName string, reservedIDs []int) (int, error) {
 			defer file.Close()
 			_, err = file.WriteString(getTableLine(foundID, tableName))
 		}
-		return foundID, fmt.Errorf("failed to add table: %v (%s)", err, RT_TABLE_PATH)
+		if err != nil {
+			return foundID, fmt.Errorf("failed to add table: %v (%s)", err, RT_TABLE_PATH)
+		}
+		return foundID, nil
 	}
 	return foundID, errors.New("No available ID")
 }
 
+// deleteRule deletes all rules to the table in both families
 func deleteRule(tableID int) error {
 	if tableID == -1 {
 		return errors.New("delete rule tableID = -1")
 	}
-	rule := netlink.NewRule()
-	rule.Table = tableID
-	err := netlink.RuleDel(rule)
-	log.Printf("delete rule %v:%v", rule, err)
+	rules, err := listRules(tableID)
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return fmt.Errorf("no rule to table %d", tableID)
+	}
+	for _, rule := range rules {
+		delErr := netlink.RuleDel(&rule)
+		log.Printf("delete rule %s:%v", describeRule(rule), delErr)
+		if delErr != nil {
+			err = delErr
+		}
+	}
 	return err
 }
 
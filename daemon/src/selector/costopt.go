@@ -5,9 +5,94 @@
 
 package selector
 
-type CostOptSelector struct{}
+import (
+	"log"
+	"sort"
+	"time"
+)
 
-func (CostOptSelector) Select(req NICSelectRequest, interfaceNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string) []string {
-	// TODO
-	return (DefaultSelector{}).Select(req, interfaceNameMap, nameNetMap, resourceMap)
+const (
+	// BalancePlacement attaches pods to the interfaces with the fewest attached pods (default)
+	BalancePlacement = "balance"
+	// PackPlacement attaches pods to the interfaces with the most attached pods to keep the others free
+	PackPlacement = "pack"
+)
+
+// GetAttachedPods returns namespace/name of pods attached to each interface of the host, set by the daemon
+var GetAttachedPods func(hostName string) (map[string][]string, error)
+
+type CostOptSelector struct {
+	// Pack selects the most used interfaces instead of the least used ones
+	Pack bool
+}
+
+// getPodCounts returns the number of pods attached to each interface including pods selected but not allocated yet,
+// the requesting pod itself is not counted
+func getPodCounts(req NICSelectRequest, now time.Time) map[string]int {
+	podKey := req.PodNamespace + "/" + req.PodName
+	podSets := make(map[string]map[string]bool)
+	addPod := func(master, pod string) {
+		if pod == podKey {
+			return
+		}
+		if _, found := podSets[master]; !found {
+			podSets[master] = make(map[string]bool)
+		}
+		podSets[master][pod] = true
+	}
+	if GetAttachedPods != nil {
+		attachedPods, err := GetAttachedPods(req.HostName)
+		if err != nil {
+			log.Printf("cannot get attached pods: %v", err)
+		}
+		for master, pods := range attachedPods {
+			for _, pod := range pods {
+				addPod(master, pod)
+			}
+		}
+	}
+	for _, selection := range pendingSelections.list(req, now) {
+		for _, master := range selection.masters {
+			addPod(master, selection.podKey)
+		}
+	}
+
+	podCounts := make(map[string]int)
+	for master, podSet := range podSets {
+		podCounts[master] = len(podSet)
+	}
+	return podCounts
+}
+
+// CostOptSelector balances the number of attached pods over the interfaces,
+// or packs pods onto as few interfaces as possible; ties are broken by network address
+func (s CostOptSelector) Select(req NICSelectRequest, interfaceNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string) []string {
+	candidates := getCandidates(req, interfaceNameMap, nameNetMap, resourceMap)
+
+	now := time.Now()
+	podCounts := getPodCounts(req, now)
+	sort.SliceStable(candidates, func(i, j int) bool {
+		countI, countJ := podCounts[interfaceNameMap[candidates[i]]], podCounts[interfaceNameMap[candidates[j]]]
+		if countI != countJ {
+			if s.Pack {
+				return countI > countJ
+			}
+			return countI < countJ
+		}
+		return candidates[i] < candidates[j]
+	})
+	maxSize := req.NicSet.NumOfInterfaces
+	if maxSize <= 0 || maxSize > len(candidates) {
+		maxSize = len(candidates)
+	}
+	selected := candidates[0:maxSize]
+
+	selectedMasters := []string{}
+	for _, netAddress := range selected {
+		master := interfaceNameMap[netAddress]
+		log.Printf("costOpt select %s (%s, %d attached pods, pack=%v)", netAddress, master, podCounts[master], s.Pack)
+		selectedMasters = append(selectedMasters, master)
+	}
+	pendingSelections.record(req, selectedMasters, now)
+	return selected
 }
@@ -0,0 +1,97 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Test CostOpt Selector", func() {
+	hostName := "host1"
+	interfaceNameMap := map[string]string{
+		"10.0.1.0/24": "eth1",
+		"10.0.2.0/24": "eth2",
+		"10.0.3.0/24": "eth3",
+	}
+	nameNetMap := map[string]string{
+		"eth1": "10.0.1.0/24",
+		"eth2": "10.0.2.0/24",
+		"eth3": "10.0.3.0/24",
+	}
+
+	var attachedPods map[string][]string
+
+	newRequest := func(podName string, nics int) NICSelectRequest {
+		return NICSelectRequest{PodName: podName, PodNamespace: "default", HostName: hostName, NicSet: NicArgs{NumOfInterfaces: nics}}
+	}
+
+	BeforeEach(func() {
+		attachedPods = make(map[string][]string)
+		GetAttachedPods = func(string) (map[string][]string, error) {
+			return attachedPods, nil
+		}
+		pendingSelections.selections = make(map[string]pendingSelection)
+	})
+
+	AfterEach(func() {
+		GetAttachedPods = nil
+	})
+
+	It("balances pods over interfaces", func() {
+		attachedPods["eth1"] = []string{"default/a", "default/b"}
+		attachedPods["eth2"] = []string{"default/a"}
+		selected := CostOptSelector{}.Select(newRequest("new", 1), interfaceNameMap, nameNetMap, nil)
+		Expect(selected).To(Equal([]string{"10.0.3.0/24"}))
+		selected = CostOptSelector{}.Select(newRequest("next", 2), interfaceNameMap, nameNetMap, nil)
+		Expect(selected).To(Equal([]string{"10.0.2.0/24", "10.0.3.0/24"}))
+	})
+
+	It("spreads consecutive pods before allocation", func() {
+		selected := []string{}
+		for i := 0; i < 6; i++ {
+			selected = append(selected, CostOptSelector{}.Select(newRequest(fmt.Sprintf("pod-%d", i), 1), interfaceNameMap, nameNetMap, nil)...)
+		}
+		Expect(selected).To(Equal([]string{
+			"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24",
+			"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24",
+		}))
+	})
+
+	It("does not count the same pod twice", func() {
+		attachedPods["eth1"] = []string{"default/a"}
+		attachedPods["eth2"] = []string{"default/b"}
+		Expect(CostOptSelector{}.Select(newRequest("c", 1), interfaceNameMap, nameNetMap, nil)).To(Equal([]string{"10.0.3.0/24"}))
+		// allocation of the selection shows up in the IPPool
+		attachedPods["eth3"] = []string{"default/c"}
+		Expect(getPodCounts(newRequest("d", 1), pendingSelections.selections[getSelectionKey("default", "c", "")].timestamp)).To(Equal(map[string]int{"eth1": 1, "eth2": 1, "eth3": 1}))
+		// retry of the same pod keeps its interface
+		Expect(CostOptSelector{}.Select(newRequest("c", 1), interfaceNameMap, nameNetMap, nil)).To(Equal([]string{"10.0.3.0/24"}))
+	})
+
+	It("packs pods onto the most used interfaces", func() {
+		attachedPods["eth2"] = []string{"default/a", "default/b"}
+		attachedPods["eth3"] = []string{"default/a"}
+		selected := CostOptSelector{Pack: true}.Select(newRequest("new", 2), interfaceNameMap, nameNetMap, nil)
+		Expect(selected).To(Equal([]string{"10.0.2.0/24", "10.0.3.0/24"}))
+		// empty host packs onto the first interface
+		attachedPods = make(map[string][]string)
+		pendingSelections.selections = make(map[string]pendingSelection)
+		for i := 0; i < 3; i++ {
+			selected = CostOptSelector{Pack: true}.Select(newRequest(fmt.Sprintf("pod-%d", i), 1), interfaceNameMap, nameNetMap, nil)
+			Expect(selected).To(Equal([]string{"10.0.1.0/24"}))
+		}
+	})
+
+	It("selects only from requested masters", func() {
+		attachedPods["eth1"] = []string{"default/a", "default/b"}
+		req := newRequest("new", 1)
+		req.NicSet.InterfaceNames = []string{"eth1", "eth2"}
+		Expect(CostOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)).To(Equal([]string{"10.0.2.0/24"}))
+	})
+})
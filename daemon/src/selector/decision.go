@@ -0,0 +1,180 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// reasons to reject a NIC from selection
+const (
+	NotRequestedMaster = "not in requested masters"
+	NotInMasterNets    = "not in masterNets of the request"
+	NotInDeviceClass   = "not in device class"
+	RankedOut          = "ranked below selected NICs"
+	DeviceNotExists    = "device not exists"
+)
+
+// PodEventHandler reports selection decision as an event on the pod, set by the daemon
+var PodEventHandler *backend.PodEventHandler
+
+// NICRejection is a candidate NIC which is not selected
+type NICRejection struct {
+	NetAddress string `json:"net,omitempty"`
+	Master     string `json:"master"`
+	Reason     string `json:"reason"`
+}
+
+// SelectionDecision records how NICs are selected for the pod
+type SelectionDecision struct {
+	Strategy string `json:"strategy"`
+	// Fallback is why default policy is applied instead of the network policy
+	Fallback string `json:"fallback,omitempty"`
+	// Failure is why no NIC is selected on purpose to fail the pod
+	Failure string `json:"failure,omitempty"`
+	// Override is the attachment policy fields overridden by the pod annotation
+	Override   string         `json:"override,omitempty"`
+	Candidates []string       `json:"candidates"`
+	Filters    []string       `json:"filters,omitempty"`
+	Rejected   []NICRejection `json:"rejected,omitempty"`
+	Selected   []string       `json:"selected"`
+}
+
+// newSelectionDecision lists candidate NICs and filters of the request
+func newSelectionDecision(strategy string, req NICSelectRequest, interfaceNameMap map[string]string) *SelectionDecision {
+	decision := &SelectionDecision{
+		Strategy:   strategy,
+		Candidates: []string{},
+		Filters:    []string{},
+		Rejected:   []NICRejection{},
+		Selected:   []string{},
+	}
+	for _, netAddress := range getSortedNetAddresses(interfaceNameMap) {
+		decision.Candidates = append(decision.Candidates, interfaceNameMap[netAddress])
+	}
+	if len(req.NicSet.InterfaceNames) > 0 {
+		decision.Filters = append(decision.Filters, "masters="+strings.Join(req.NicSet.InterfaceNames, ","))
+	} else if len(req.MasterNetAddrs) > 0 {
+		decision.Filters = append(decision.Filters, "masterNets="+strings.Join(req.MasterNetAddrs, ","))
+	}
+	if req.NicSet.DevClass != "" {
+		decision.Filters = append(decision.Filters, "class="+req.NicSet.DevClass)
+	}
+	if req.NicSet.NumOfInterfaces > 0 {
+		decision.Filters = append(decision.Filters, fmt.Sprintf("nics=%d", req.NicSet.NumOfInterfaces))
+	}
+	return decision
+}
+
+func getSortedNetAddresses(interfaceNameMap map[string]string) []string {
+	netAddresses := []string{}
+	for netAddress := range interfaceNameMap {
+		netAddresses = append(netAddresses, netAddress)
+	}
+	sort.Strings(netAddresses)
+	return netAddresses
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *SelectionDecision) reject(netAddress, master, reason string) {
+	d.Rejected = append(d.Rejected, NICRejection{NetAddress: netAddress, Master: master, Reason: reason})
+}
+
+// filterReasoner is a selector which reports why it filters out candidates
+type filterReasoner interface {
+	FilterReasons() map[string]string
+}
+
+// rejectUnselected records why each candidate NIC is not selected,
+// remainingNameMap is the candidates left after filtering by the selector
+// and filterReasons is reasons reported by the selector keyed by network address
+func (d *SelectionDecision) rejectUnselected(req NICSelectRequest, interfaceNameMap, remainingNameMap map[string]string, filterReasons map[string]string, selectedNetAddrs []string) {
+	for _, netAddress := range getSortedNetAddresses(interfaceNameMap) {
+		if containsString(selectedNetAddrs, netAddress) {
+			continue
+		}
+		master := interfaceNameMap[netAddress]
+		_, remaining := remainingNameMap[netAddress]
+		filterReason, filtered := filterReasons[netAddress]
+		switch {
+		case len(req.NicSet.InterfaceNames) > 0 && !containsString(req.NicSet.InterfaceNames, master):
+			d.reject(netAddress, master, NotRequestedMaster)
+		case len(req.NicSet.InterfaceNames) == 0 && len(req.MasterNetAddrs) > 0 && !containsString(req.MasterNetAddrs, netAddress):
+			d.reject(netAddress, master, NotInMasterNets)
+		case filtered:
+			d.reject(netAddress, master, filterReason)
+		case !remaining:
+			d.reject(netAddress, master, fmt.Sprintf("%s %s", NotInDeviceClass, req.NicSet.DevClass))
+		default:
+			d.reject(netAddress, master, RankedOut)
+		}
+	}
+}
+
+// EventType returns warning if the network policy is not applied, a selected NIC is missing,
+// or a NIC is excluded for its link state
+func (d *SelectionDecision) EventType() string {
+	if d.Fallback != "" || d.Failure != "" || len(d.Selected) == 0 {
+		return corev1.EventTypeWarning
+	}
+	for _, rejection := range d.Rejected {
+		if rejection.Reason == DeviceNotExists || isUnhealthyReason(rejection.Reason) {
+			return corev1.EventTypeWarning
+		}
+	}
+	return corev1.EventTypeNormal
+}
+
+func (d *SelectionDecision) String() string {
+	message := fmt.Sprintf("selected %v by %s from %v", d.Selected, d.Strategy, d.Candidates)
+	if d.Fallback != "" {
+		message += fmt.Sprintf(" (default policy: %s)", d.Fallback)
+	}
+	if d.Override != "" {
+		message += fmt.Sprintf(" (override: %s)", d.Override)
+	}
+	if d.Failure != "" {
+		message += fmt.Sprintf(" (failed: %s)", d.Failure)
+	}
+	if len(d.Filters) > 0 {
+		message += fmt.Sprintf("; filters: %s", strings.Join(d.Filters, " "))
+	}
+	if len(d.Rejected) > 0 {
+		rejections := []string{}
+		for _, rejection := range d.Rejected {
+			rejections = append(rejections, fmt.Sprintf("%s (%s)", rejection.Master, rejection.Reason))
+		}
+		message += fmt.Sprintf("; rejected: %s", strings.Join(rejections, ", "))
+	}
+	return message
+}
+
+// emitSelectionEvent reports the decision on the pod if the event handler is set
+func emitSelectionEvent(req NICSelectRequest, decision *SelectionDecision) {
+	if PodEventHandler == nil {
+		return
+	}
+	reason := backend.NIC_SELECTED_REASON
+	eventType := decision.EventType()
+	if eventType == corev1.EventTypeWarning {
+		reason = backend.NIC_SELECTION_WARNING_REASON
+	}
+	PodEventHandler.EmitAsync(req.PodName, req.PodNamespace, eventType, reason,
+		fmt.Sprintf("%s: %s", req.NetAttachDefName, decision.String()))
+}
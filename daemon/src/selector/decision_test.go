@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"context"
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+)
+
+var _ = Describe("Test Selection Decision", func() {
+	interfaceNameMap := map[string]string{
+		"10.0.1.0/24": "eth1",
+		"10.0.2.0/24": "eth2",
+		"10.0.3.0/24": "eth3",
+	}
+
+	DescribeTable("rejects unselected NICs", func(nicSet NicArgs, masterNets []string, remaining []string, expectedSelected []string, expectedRejected []NICRejection) {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", MasterNetAddrs: masterNets, NicSet: nicSet}
+		remainingNameMap := make(map[string]string)
+		for _, netAddress := range remaining {
+			remainingNameMap[netAddress] = interfaceNameMap[netAddress]
+		}
+		decision := newSelectionDecision(string(None), req, interfaceNameMap)
+		Expect(decision.Candidates).To(Equal([]string{"eth1", "eth2", "eth3"}))
+		selected := DefaultSelector{}.Select(req, remainingNameMap, nameNetMapOf(remainingNameMap), nil)
+		Expect(selected).To(Equal(expectedSelected))
+		decision.rejectUnselected(req, interfaceNameMap, remainingNameMap, nil, selected)
+		Expect(decision.Rejected).To(Equal(expectedRejected))
+	},
+		Entry("limited number", NicArgs{NumOfInterfaces: 1}, nil, []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"},
+			[]string{"10.0.1.0/24"}, []NICRejection{
+				{NetAddress: "10.0.2.0/24", Master: "eth2", Reason: RankedOut},
+				{NetAddress: "10.0.3.0/24", Master: "eth3", Reason: RankedOut},
+			}),
+		Entry("requested masters", NicArgs{InterfaceNames: []string{"eth2"}}, nil, []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"},
+			[]string{"10.0.2.0/24"}, []NICRejection{
+				{NetAddress: "10.0.1.0/24", Master: "eth1", Reason: NotRequestedMaster},
+				{NetAddress: "10.0.3.0/24", Master: "eth3", Reason: NotRequestedMaster},
+			}),
+		Entry("master nets", NicArgs{}, []string{"10.0.3.0/24"}, []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"},
+			[]string{"10.0.3.0/24"}, []NICRejection{
+				{NetAddress: "10.0.1.0/24", Master: "eth1", Reason: NotInMasterNets},
+				{NetAddress: "10.0.2.0/24", Master: "eth2", Reason: NotInMasterNets},
+			}),
+		Entry("device class", NicArgs{DevClass: "highspeed"}, nil, []string{"10.0.1.0/24", "10.0.3.0/24"},
+			[]string{"10.0.1.0/24", "10.0.3.0/24"}, []NICRejection{
+				{NetAddress: "10.0.2.0/24", Master: "eth2", Reason: NotInDeviceClass + " highspeed"},
+			}),
+	)
+
+	It("describes decision", func() {
+		req := NICSelectRequest{NicSet: NicArgs{NumOfInterfaces: 1}}
+		decision := newSelectionDecision(string(None), req, interfaceNameMap)
+		decision.rejectUnselected(req, interfaceNameMap, interfaceNameMap, nil, []string{"10.0.1.0/24"})
+		decision.Selected = []string{"eth1"}
+		Expect(decision.EventType()).To(Equal(corev1.EventTypeNormal))
+		Expect(decision.String()).To(Equal("selected [eth1] by none from [eth1 eth2 eth3]; filters: nics=1; " +
+			"rejected: eth2 (ranked below selected NICs), eth3 (ranked below selected NICs)"))
+		// decision is carried in the response
+		respBytes, err := json.Marshal(NICSelectResponse{DeviceIDs: []string{}, Masters: []string{"eth1"}, Decision: decision})
+		Expect(err).NotTo(HaveOccurred())
+		var resp NICSelectResponse
+		Expect(json.Unmarshal(respBytes, &resp)).To(Succeed())
+		Expect(resp.Decision).To(Equal(decision))
+
+		decision.Fallback = "failed to get network spec"
+		Expect(decision.EventType()).To(Equal(corev1.EventTypeWarning))
+		decision.Fallback = ""
+		decision.reject("10.0.1.0/24", "eth1", DeviceNotExists)
+		Expect(decision.EventType()).To(Equal(corev1.EventTypeWarning))
+	})
+
+	It("emits decision as event on the pod", func() {
+		clientset := fake.NewSimpleClientset(&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default", UID: "pod-uid"},
+		})
+		PodEventHandler = backend.NewPodEventHandler(clientset, "host1")
+		DeferCleanup(func() { PodEventHandler = nil })
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", NetAttachDefName: "multi-nic-sample"}
+		decision := newSelectionDecision(string(None), req, interfaceNameMap)
+		decision.Fallback = "failed to get network spec"
+		emitSelectionEvent(req, decision)
+		var events *corev1.EventList
+		Eventually(func() []corev1.Event {
+			events, _ = clientset.CoreV1().Events("default").List(context.TODO(), metav1.ListOptions{})
+			return events.Items
+		}).Should(HaveLen(1))
+		event := events.Items[0]
+		Expect(event.InvolvedObject.UID).To(BeEquivalentTo("pod-uid"))
+		Expect(event.Type).To(Equal(corev1.EventTypeWarning))
+		Expect(event.Reason).To(Equal(backend.NIC_SELECTION_WARNING_REASON))
+		Expect(event.Message).To(HavePrefix("multi-nic-sample: selected [] by none"))
+		Expect(event.Source.Host).To(Equal("host1"))
+	})
+})
+
+func nameNetMapOf(interfaceNameMap map[string]string) map[string]string {
+	nameNetMap := make(map[string]string)
+	for netAddress, master := range interfaceNameMap {
+		nameNetMap[master] = netAddress
+	}
+	return nameNetMap
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+// actions of attachment policy when NICs are excluded for unhealthy links
+const (
+	// DegradeOnUnhealthy attaches the healthy NICs only even if fewer than requested (default)
+	DegradeOnUnhealthy = "degrade"
+	// FailOnUnhealthy fails the pod if fewer healthy NICs than requested remain
+	FailOnUnhealthy = "fail"
+)
+
+// excludeUnhealthyLinks removes NICs with link down or flapping from interfaceNameMap
+// and returns why they are excluded keyed by network address
+func excludeUnhealthyLinks(interfaceNameMap map[string]string, now time.Time) map[string]string {
+	unhealthyReasons := make(map[string]string)
+	for netAddress, master := range interfaceNameMap {
+		if reason := iface.LinkHealthMonitor.CheckLink(master, now); reason != "" {
+			log.Printf("exclude %s from selection: %s", master, reason)
+			unhealthyReasons[netAddress] = reason
+			delete(interfaceNameMap, netAddress)
+		}
+	}
+	return unhealthyReasons
+}
+
+// isUnhealthyReason returns true if the NIC is rejected for its link state
+func isUnhealthyReason(reason string) bool {
+	return strings.HasPrefix(reason, iface.LinkDownReason) || strings.HasPrefix(reason, iface.LinkFlappingReason)
+}
+
+// getUnhealthyFailure returns why the pod fails when unhealthy NICs matching the request are excluded
+// and fewer healthy NICs than the requested number remain (all matching NICs if the number is not set),
+// empty if the healthy NICs are enough
+func getUnhealthyFailure(req NICSelectRequest, interfaceNameMap map[string]string, nameNetMap map[string]string, unhealthyReasons map[string]string) string {
+	// candidates of requested masters or masterNets
+	candidateNameMap := make(map[string]string)
+	for _, netAddress := range getCandidates(req, interfaceNameMap, nameNetMap, nil) {
+		candidateNameMap[netAddress] = interfaceNameMap[netAddress]
+	}
+	if req.NicSet.DevClass != "" && DeviceClassHandler != nil {
+		filterByDeviceClass(req.NicSet.DevClass, candidateNameMap, nameNetMap)
+	}
+	unhealthy := []string{}
+	for _, netAddress := range getSortedNetAddresses(candidateNameMap) {
+		if reason, found := unhealthyReasons[netAddress]; found {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s (%s)", candidateNameMap[netAddress], reason))
+		}
+	}
+	if len(unhealthy) == 0 {
+		return ""
+	}
+	healthyCount := len(candidateNameMap) - len(unhealthy)
+	if req.NicSet.NumOfInterfaces > 0 && healthyCount >= req.NicSet.NumOfInterfaces {
+		return ""
+	}
+	return fmt.Sprintf("%d healthy NICs left by excluding %s", healthyCount, strings.Join(unhealthy, ", "))
+}
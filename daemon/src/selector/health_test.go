@@ -0,0 +1,103 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+var _ = Describe("Test Unhealthy Link Exclusion", func() {
+	interfaceNameMap := map[string]string{
+		"10.0.1.0/24": "hl1",
+		"10.0.2.0/24": "hl2",
+		"10.0.3.0/24": "hl3",
+	}
+	nameNetMap := nameNetMapOf(interfaceNameMap)
+	unhealthyReasons := map[string]string{"10.0.2.0/24": iface.LinkDownReason}
+
+	var (
+		originalSysClassNet string
+		originalMonitor     *iface.LinkHealthTracker
+	)
+
+	BeforeEach(func() {
+		originalSysClassNet = iface.SysClassNet
+		originalMonitor = iface.LinkHealthMonitor
+		iface.SysClassNet = GinkgoT().TempDir()
+		iface.LinkHealthMonitor = iface.NewLinkHealthTracker(time.Minute, 4)
+		for _, devName := range []string{"hl1", "hl2", "hl3"} {
+			writeSysfsFile(filepath.Join(iface.SysClassNet, devName), "operstate", "up")
+			writeSysfsFile(filepath.Join(iface.SysClassNet, devName), "carrier", "1")
+			writeSysfsFile(filepath.Join(iface.SysClassNet, devName), "carrier_changes", "0")
+		}
+	})
+
+	AfterEach(func() {
+		iface.SysClassNet = originalSysClassNet
+		iface.LinkHealthMonitor = originalMonitor
+	})
+
+	It("excludes NICs with link down or flapping", func() {
+		now := time.Now()
+		iface.LinkHealthMonitor.Sample(iface.ReadLinkSamples([]string{"hl1", "hl2", "hl3"}, nil), now)
+		writeSysfsFile(filepath.Join(iface.SysClassNet, "hl2"), "carrier", "0")
+		writeSysfsFile(filepath.Join(iface.SysClassNet, "hl3"), "carrier_changes", "6")
+
+		remainingNameMap := map[string]string{}
+		for netAddress, master := range interfaceNameMap {
+			remainingNameMap[netAddress] = master
+		}
+		reasons := excludeUnhealthyLinks(remainingNameMap, now.Add(time.Second))
+		Expect(remainingNameMap).To(Equal(map[string]string{"10.0.1.0/24": "hl1"}))
+		Expect(reasons).To(Equal(map[string]string{
+			"10.0.2.0/24": iface.LinkDownReason,
+			"10.0.3.0/24": "link flapping (6 carrier changes in 1m0s)",
+		}))
+
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default"}
+		decision := newSelectionDecision(string(None), req, interfaceNameMap)
+		selected := DefaultSelector{}.Select(req, remainingNameMap, nameNetMap, nil)
+		decision.rejectUnselected(req, interfaceNameMap, remainingNameMap, reasons, selected)
+		decision.Selected = []string{"hl1"}
+		Expect(decision.Rejected).To(Equal([]NICRejection{
+			{NetAddress: "10.0.2.0/24", Master: "hl2", Reason: iface.LinkDownReason},
+			{NetAddress: "10.0.3.0/24", Master: "hl3", Reason: "link flapping (6 carrier changes in 1m0s)"},
+		}))
+		// attached with fewer NICs
+		Expect(decision.EventType()).To(Equal(corev1.EventTypeWarning))
+	})
+
+	DescribeTable("does not select excluded NICs requested by the pod or the network", func(nicSet NicArgs, masterNets []string, expected []string) {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", MasterNetAddrs: masterNets, NicSet: nicSet}
+		healthyNameMap := map[string]string{"10.0.1.0/24": "hl1", "10.0.3.0/24": "hl3"}
+		Expect(DefaultSelector{}.Select(req, healthyNameMap, nameNetMap, nil)).To(Equal(expected))
+	},
+		Entry("master nets", NicArgs{}, []string{"10.0.1.0/24", "10.0.2.0/24"}, []string{"10.0.1.0/24"}),
+		Entry("only excluded master nets", NicArgs{}, []string{"10.0.2.0/24"}, []string{}),
+		Entry("masters", NicArgs{InterfaceNames: []string{"hl2", "hl3"}}, nil, []string{"10.0.3.0/24"}),
+		Entry("only excluded masters", NicArgs{InterfaceNames: []string{"hl2"}}, nil, []string{}),
+		Entry("unknown masters", NicArgs{InterfaceNames: []string{"unknown"}}, nil, []string{"10.0.1.0/24", "10.0.3.0/24"}),
+	)
+
+	DescribeTable("fails the pod if healthy NICs are not enough", func(nicSet NicArgs, masterNets []string, expectedFailure string) {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", MasterNetAddrs: masterNets, NicSet: nicSet}
+		Expect(getUnhealthyFailure(req, interfaceNameMap, nameNetMap, unhealthyReasons)).To(Equal(expectedFailure))
+	},
+		Entry("all NICs", NicArgs{}, nil, "2 healthy NICs left by excluding hl2 (link down)"),
+		Entry("enough NICs", NicArgs{NumOfInterfaces: 2}, nil, ""),
+		Entry("not enough NICs", NicArgs{NumOfInterfaces: 3}, nil, "2 healthy NICs left by excluding hl2 (link down)"),
+		Entry("requested master", NicArgs{InterfaceNames: []string{"hl2"}}, nil, "0 healthy NICs left by excluding hl2 (link down)"),
+		Entry("other requested masters", NicArgs{InterfaceNames: []string{"hl1", "hl3"}}, nil, ""),
+		Entry("other master nets", NicArgs{}, []string{"10.0.1.0/24"}, ""),
+	)
+})
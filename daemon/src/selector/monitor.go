@@ -5,20 +5,257 @@
 
 package selector
 
+import (
+	"log"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+const (
+	// DEFAULT_LINK_SPEED_MBPS is assumed link speed when the interface does not report its speed
+	DEFAULT_LINK_SPEED_MBPS = 10000
+	// SMOOTHING_FACTOR is weight of the latest sample in the moving average of utilization and drop rate
+	SMOOTHING_FACTOR = 0.5
+	// DROP_RATE_WEIGHT is weight of drop rate relative to utilization in the load score
+	DROP_RATE_WEIGHT = 10.0
+	// ASSIGNMENT_LOAD is load added to the score for each selection not yet reflected by the samples
+	ASSIGNMENT_LOAD = 0.1
+	// SCORE_MARGIN is difference of measured load by which another interface must beat the previously preferred interface
+	SCORE_MARGIN = 0.05
+	// ASSIGNMENT_HOLD_SAMPLES is number of sampling intervals a selection is kept in the load score
+	ASSIGNMENT_HOLD_SAMPLES = 3
+)
+
+// Metric is recent load of an interface
 type Metric struct {
-	name   string
-	values []float64
+	// Utilization is the larger of tx and rx throughput over link speed, smoothed over samples
+	Utilization float64
+	// DropRate is dropped packets over transmitted and received packets, smoothed over samples
+	DropRate float64
+	// Assigned is number of recent selections of the interface whose addresses are allocated
+	Assigned int
+	// Pending is number of selections of the interface whose addresses are not allocated yet
+	Pending int
+	// Preferred is true if the interface was ranked first by the previous selection
+	Preferred bool
+}
+
+// measuredLoad returns load of the interface from the samples
+func (m Metric) measuredLoad() float64 {
+	return m.Utilization + DROP_RATE_WEIGHT*m.DropRate
+}
+
+// selections returns number of selections of the interface not yet reflected by the samples
+func (m Metric) selections() int {
+	return m.Assigned + m.Pending
+}
+
+// Score returns load score of the interface, lower is less loaded
+func (m Metric) Score() float64 {
+	return m.measuredLoad() + ASSIGNMENT_LOAD*float64(m.selections())
+}
+
+// getRankScores returns score to rank each candidate, lower is preferred.
+// The previously preferred interface takes the lowest score of the candidates
+// if no other candidate has fewer selections or measured load lower by more than SCORE_MARGIN,
+// so that a small change of load does not move pods while a burst of selections is still spread.
+func getRankScores(candidates []string, metricMap map[string]Metric) map[string]float64 {
+	rankScores := make(map[string]float64)
+	preferred := ""
+	lowest := math.Inf(1)
+	for _, netAddress := range candidates {
+		metric := metricMap[netAddress]
+		rankScores[netAddress] = metric.Score()
+		lowest = math.Min(lowest, metric.Score())
+		if metric.Preferred {
+			preferred = netAddress
+		}
+	}
+	if preferred == "" {
+		return rankScores
+	}
+	preferredMetric := metricMap[preferred]
+	for _, netAddress := range candidates {
+		metric := metricMap[netAddress]
+		if metric.selections() < preferredMetric.selections() || metric.measuredLoad() < preferredMetric.measuredLoad()-SCORE_MARGIN {
+			return rankScores
+		}
+	}
+	rankScores[preferred] = lowest
+	return rankScores
+}
+
+// ifaceSample is statistics of an interface read at the timestamp
+type ifaceSample struct {
+	counters  backend.LinkCounters
+	timestamp time.Time
+}
+
+// Monitor keeps recent load of host interfaces from the link samples
+type Monitor struct {
+	sync.Mutex
+	holdPeriod  time.Duration
+	samples     map[string]ifaceSample
+	ifaceStat   map[string]Metric
+	assignments map[string][]time.Time
+	// preferred is the interface ranked first by the previous selection
+	preferred string
+	now       func() time.Time
+}
+
+// PerfMonitor is the interface monitor used by PerfOptSelector
+var PerfMonitor = NewMonitor(iface.DEFAULT_LINK_SAMPLE_INTERVAL * time.Second)
+
+// NewMonitor returns a monitor which keeps selections for ASSIGNMENT_HOLD_SAMPLES of the sampling interval
+func NewMonitor(interval time.Duration) *Monitor {
+	return &Monitor{
+		holdPeriod:  ASSIGNMENT_HOLD_SAMPLES * interval,
+		samples:     make(map[string]ifaceSample),
+		ifaceStat:   make(map[string]Metric),
+		assignments: make(map[string][]time.Time),
+		now:         time.Now,
+	}
 }
 
-func GetInterfaceStat(interfaceNameMap map[string]string) map[string]Metric {
-	//TODO
+// SetInterval keeps selections for ASSIGNMENT_HOLD_SAMPLES of the sampling interval of the link sampler,
+// zero interval leaves the interfaces without samples so that perfOpt selects them in order
+func (m *Monitor) SetInterval(interval time.Duration) {
+	if interval <= 0 {
+		log.Println("link sampler disabled, perfOpt selects interfaces in order")
+		return
+	}
+	m.Lock()
+	defer m.Unlock()
+	m.holdPeriod = ASSIGNMENT_HOLD_SAMPLES * interval
+}
+
+// Sample updates load of the interfaces from the link samples and the previous samples
+func (m *Monitor) Sample(linkSamples []iface.LinkSample, now time.Time) {
+	m.Lock()
+	defer m.Unlock()
+	samples := make(map[string]ifaceSample)
+	for _, status := range linkSamples {
+		devName := status.InterfaceName
+		samples[devName] = ifaceSample{counters: status.Counters, timestamp: now}
+		prev, found := m.samples[devName]
+		if !found {
+			continue
+		}
+		rates := iface.ComputeLinkRates(prev.counters, status.Counters, now.Sub(prev.timestamp))
+		utilization, dropRate := computeLoad(rates, status.Speed)
+		metric, found := m.ifaceStat[devName]
+		if found {
+			utilization = SMOOTHING_FACTOR*utilization + (1-SMOOTHING_FACTOR)*metric.Utilization
+			dropRate = SMOOTHING_FACTOR*dropRate + (1-SMOOTHING_FACTOR)*metric.DropRate
+		}
+		m.ifaceStat[devName] = Metric{Utilization: utilization, DropRate: dropRate}
+	}
+	for devName := range m.ifaceStat {
+		if _, found := samples[devName]; !found {
+			delete(m.ifaceStat, devName)
+		}
+	}
+	m.samples = samples
+}
+
+// computeLoad returns utilization of the link and ratio of dropped packets from the rates
+func computeLoad(rates backend.LinkCounters, speedMbps int) (float64, float64) {
+	if speedMbps <= 0 {
+		speedMbps = DEFAULT_LINK_SPEED_MBPS
+	}
+	bitsPerSecond := float64(speedMbps) * 1e6
+	utilization := 8 * math.Max(float64(rates.TxBytes), float64(rates.RxBytes)) / bitsPerSecond
+	dropRate := 0.0
+	dropped := float64(rates.TxDropped + rates.RxDropped)
+	if packets := float64(rates.TxPackets+rates.RxPackets) + dropped; packets > 0 {
+		dropRate = dropped / packets
+	}
+	return utilization, dropRate
+}
+
+// expireAssignmentsLocked removes selections older than the hold period
+func (m *Monitor) expireAssignmentsLocked(now time.Time) {
+	for devName, assignedTimes := range m.assignments {
+		recent := []time.Time{}
+		for _, assignedTime := range assignedTimes {
+			if now.Sub(assignedTime) < m.holdPeriod {
+				recent = append(recent, assignedTime)
+			}
+		}
+		if len(recent) == 0 {
+			delete(m.assignments, devName)
+		} else {
+			m.assignments[devName] = recent
+		}
+	}
+}
+
+// GetInterfaceStat returns load of the interfaces keyed by network address,
+// interfaces not yet sampled have no utilization and drop rate
+func (m *Monitor) GetInterfaceStat(interfaceNameMap map[string]string) map[string]Metric {
+	m.Lock()
+	defer m.Unlock()
+	m.expireAssignmentsLocked(m.now())
 	metricMap := make(map[string]Metric)
-	for _, master := range interfaceNameMap {
-		metricMap[master] = Metric{}
+	for netAddress, master := range interfaceNameMap {
+		metric := m.ifaceStat[master]
+		metric.Assigned = len(m.assignments[master])
+		metric.Preferred = master == m.preferred
+		metricMap[netAddress] = metric
 	}
 	return metricMap
 }
 
-type Monitor struct {
-	ifaceStat map[string]Metric
+// Prefer keeps the interface ranked first by the selection for the next selection
+func (m *Monitor) Prefer(devName string) {
+	m.Lock()
+	defer m.Unlock()
+	m.preferred = devName
+}
+
+// Assign records the allocated interfaces so that the next selection takes the expected load into account
+// until the traffic shows up in the samples
+func (m *Monitor) Assign(devNames []string) {
+	m.Lock()
+	defer m.Unlock()
+	now := m.now()
+	for _, devName := range devNames {
+		m.assignments[devName] = append(m.assignments[devName], now)
+	}
+}
+
+// getInterfaceLoad returns load of the interfaces keyed by network address
+// including selections of the host not allocated yet
+func getInterfaceLoad(req NICSelectRequest, interfaceNameMap map[string]string, now time.Time) map[string]Metric {
+	metricMap := PerfMonitor.GetInterfaceStat(interfaceNameMap)
+	pendingCounts := pendingSelections.countMasters(req, now)
+	for netAddress, metric := range metricMap {
+		metric.Pending = pendingCounts[interfaceNameMap[netAddress]]
+		metricMap[netAddress] = metric
+	}
+	return metricMap
+}
+
+// SortByLoad sorts network addresses from the least loaded interface by the rank scores,
+// equally ranked interfaces are ordered by preference, the number of selections, and then network address
+func SortByLoad(netAddresses []string, metricMap map[string]Metric) {
+	rankScores := getRankScores(netAddresses, metricMap)
+	sort.SliceStable(netAddresses, func(i, j int) bool {
+		metricI, metricJ := metricMap[netAddresses[i]], metricMap[netAddresses[j]]
+		if scoreI, scoreJ := rankScores[netAddresses[i]], rankScores[netAddresses[j]]; scoreI != scoreJ {
+			return scoreI < scoreJ
+		}
+		if metricI.Preferred != metricJ.Preferred {
+			return metricI.Preferred
+		}
+		if metricI.selections() != metricJ.selections() {
+			return metricI.selections() < metricJ.selections()
+		}
+		return netAddresses[i] < netAddresses[j]
+	})
 }
@@ -0,0 +1,218 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+func TestSelector(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Selector Test Suite")
+}
+
+func writeSysfsFile(dir, name, value string) {
+	Expect(os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(dir, name), []byte(value+"\n"), 0644)).To(Succeed())
+}
+
+var _ = Describe("Test PerfOpt Selector", func() {
+	interval := 5 * time.Second
+	interfaceNameMap := map[string]string{
+		"10.0.1.0/24": "eth1",
+		"10.0.2.0/24": "eth2",
+		"10.0.3.0/24": "eth3",
+	}
+	nameNetMap := map[string]string{
+		"eth1": "10.0.1.0/24",
+		"eth2": "10.0.2.0/24",
+		"eth3": "10.0.3.0/24",
+	}
+	devNames := []string{"eth1", "eth2", "eth3"}
+
+	var (
+		originalSysClassNet string
+		originalMonitor     *Monitor
+		now                 time.Time
+	)
+
+	// writeCounters sets speed (Mbps), tx bytes and dropped packets of the interface
+	writeCounters := func(devName string, speed int, txBytes, txPackets, txDropped int64) {
+		devDir := filepath.Join(iface.SysClassNet, devName)
+		writeSysfsFile(devDir, "speed", strconv.Itoa(speed))
+		writeSysfsFile(devDir, "statistics/tx_bytes", strconv.FormatInt(txBytes, 10))
+		writeSysfsFile(devDir, "statistics/tx_packets", strconv.FormatInt(txPackets, 10))
+		writeSysfsFile(devDir, "statistics/tx_dropped", strconv.FormatInt(txDropped, 10))
+	}
+
+	// sample writes counters grown by the given tx rates (bytes/s) and samples all interfaces after interval
+	sample := func(txRates map[string]int64) {
+		for _, devName := range devNames {
+			devDir := filepath.Join(iface.SysClassNet, devName, "statistics")
+			txBytes, _ := strconv.ParseInt(readFile(filepath.Join(devDir, "tx_bytes")), 10, 64)
+			writeSysfsFile(devDir, "tx_bytes", strconv.FormatInt(txBytes+txRates[devName]*int64(interval.Seconds()), 10))
+		}
+		now = now.Add(interval)
+		PerfMonitor.Sample(iface.ReadLinkSamples(devNames, nil), now)
+	}
+
+	BeforeEach(func() {
+		originalSysClassNet = iface.SysClassNet
+		iface.SysClassNet = GinkgoT().TempDir()
+		originalMonitor = PerfMonitor
+		PerfMonitor = NewMonitor(interval)
+		pendingSelections.selections = make(map[string]pendingSelection)
+		now = time.Now()
+		PerfMonitor.now = func() time.Time { return now }
+		for _, devName := range devNames {
+			writeCounters(devName, 10000, 0, 0, 0)
+		}
+		PerfMonitor.Sample(iface.ReadLinkSamples(devNames, nil), now)
+	})
+
+	AfterEach(func() {
+		iface.SysClassNet = originalSysClassNet
+		PerfMonitor = originalMonitor
+	})
+
+	It("computes utilization and drop rate from sysfs statistics", func() {
+		writeCounters("eth2", 1000, 0, 900, 100)
+		now = now.Add(interval)
+		PerfMonitor.Sample(iface.ReadLinkSamples(devNames, nil), now)
+		// 1000 Mbps = 125 MB/s
+		sample(map[string]int64{"eth1": 625000000, "eth2": 62500000})
+		metricMap := PerfMonitor.GetInterfaceStat(interfaceNameMap)
+		Expect(metricMap["10.0.1.0/24"].Utilization).To(BeNumerically("~", 0.25, 0.001))
+		// smoothed from 0 to 0.5
+		Expect(metricMap["10.0.2.0/24"].Utilization).To(BeNumerically("~", 0.25, 0.001))
+		Expect(metricMap["10.0.2.0/24"].DropRate).To(BeNumerically("~", 0.05, 0.001))
+		Expect(metricMap["10.0.3.0/24"].Utilization).To(BeZero())
+	})
+
+	It("selects the least loaded interfaces", func() {
+		sample(map[string]int64{"eth1": 500000000, "eth2": 10000000, "eth3": 300000000})
+		req := NICSelectRequest{NicSet: NicArgs{NumOfInterfaces: 2}}
+		selected := PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)
+		Expect(selected).To(Equal([]string{"10.0.2.0/24", "10.0.3.0/24"}))
+	})
+
+	It("selects only from requested masters", func() {
+		sample(map[string]int64{"eth1": 500000000, "eth2": 10000000, "eth3": 300000000})
+		req := NICSelectRequest{NicSet: NicArgs{NumOfInterfaces: 1, InterfaceNames: []string{"eth1", "eth3"}}}
+		selected := PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)
+		Expect(selected).To(Equal([]string{"10.0.3.0/24"}))
+	})
+
+	// allocate confirms the selection as the daemon does after the addresses are allocated
+	allocate := func(req NICSelectRequest, selected []string) {
+		masters := []string{}
+		for _, netAddress := range selected {
+			masters = append(masters, interfaceNameMap[netAddress])
+		}
+		ConfirmSelection(req.PodNamespace, req.PodName, req.NetAttachDefName, masters)
+	}
+
+	It("spreads concurrent pods over idle interfaces before allocation", func() {
+		selected := []string{}
+		for i := 0; i < 6; i++ {
+			req := NICSelectRequest{PodName: fmt.Sprintf("pod-%d", i), PodNamespace: "default", NicSet: NicArgs{NumOfInterfaces: 1}}
+			selected = append(selected, PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)...)
+		}
+		Expect(selected[0:3]).To(Equal([]string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}))
+		counts := make(map[string]int)
+		for _, netAddress := range selected {
+			counts[netAddress] += 1
+		}
+		Expect(counts).To(Equal(map[string]int{"10.0.1.0/24": 2, "10.0.2.0/24": 2, "10.0.3.0/24": 2}))
+	})
+
+	It("counts pending selections of each network until the allocation is confirmed", func() {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", NetAttachDefName: "net1", NicSet: NicArgs{NumOfInterfaces: 1}}
+		otherReq := NICSelectRequest{PodName: "other", PodNamespace: "default", NetAttachDefName: "net1", NicSet: NicArgs{NumOfInterfaces: 1}}
+		selected := PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)
+		Expect(selected).To(Equal([]string{"10.0.1.0/24"}))
+		metricMap := getInterfaceLoad(otherReq, interfaceNameMap, time.Now())
+		Expect(metricMap["10.0.1.0/24"].Pending).To(Equal(1))
+		Expect(metricMap["10.0.1.0/24"].Assigned).To(BeZero())
+		// retry of the pod does not count its own selection
+		Expect(PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)).To(Equal(selected))
+
+		// selection of the same pod in another network is kept separately
+		secondReq := req
+		secondReq.NetAttachDefName = "net2"
+		secondSelected := PerfOptSelector{}.Select(secondReq, interfaceNameMap, nameNetMap, nil)
+		Expect(secondSelected).To(Equal([]string{"10.0.2.0/24"}))
+		metricMap = getInterfaceLoad(otherReq, interfaceNameMap, time.Now())
+		Expect(metricMap["10.0.1.0/24"].Pending).To(Equal(1))
+		Expect(metricMap["10.0.2.0/24"].Pending).To(Equal(1))
+
+		allocate(req, selected)
+		allocate(secondReq, secondSelected)
+		// allocation is counted once
+		allocate(req, selected)
+		metricMap = getInterfaceLoad(otherReq, interfaceNameMap, time.Now())
+		Expect(metricMap["10.0.1.0/24"].Pending).To(BeZero())
+		Expect(metricMap["10.0.1.0/24"].Assigned).To(Equal(1))
+		Expect(metricMap["10.0.2.0/24"].Assigned).To(Equal(1))
+
+		// selection never allocated expires
+		PerfOptSelector{}.Select(otherReq, interfaceNameMap, nameNetMap, nil)
+		Expect(getInterfaceLoad(req, interfaceNameMap, time.Now())["10.0.3.0/24"].Pending).To(Equal(1))
+		Expect(getInterfaceLoad(req, interfaceNameMap, time.Now().Add(SELECTION_TIMEOUT))["10.0.3.0/24"].Pending).To(BeZero())
+		_, found := pendingSelections.confirm(otherReq.PodNamespace, otherReq.PodName, otherReq.NetAttachDefName, time.Now())
+		Expect(found).To(BeFalse())
+	})
+
+	It("keeps the preferred interface unless another is less loaded by the score margin", func() {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", NicSet: NicArgs{NumOfInterfaces: 1}}
+		Expect(PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)).To(Equal([]string{"10.0.1.0/24"}))
+		// eth1 becomes slightly more loaded than eth2 but within the score margin
+		sample(map[string]int64{"eth1": 30000000, "eth2": 10000000, "eth3": 600000000})
+		Expect(PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)).To(Equal([]string{"10.0.1.0/24"}))
+		// eth2 is less loaded by more than the score margin
+		sample(map[string]int64{"eth1": 200000000, "eth2": 10000000, "eth3": 600000000})
+		Expect(PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)).To(Equal([]string{"10.0.2.0/24"}))
+		// eth2 is kept on the same load
+		sample(map[string]int64{"eth1": 200000000, "eth2": 10000000, "eth3": 600000000})
+		Expect(PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)).To(Equal([]string{"10.0.2.0/24"}))
+	})
+
+	It("forgets selections after hold period", func() {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", NicSet: NicArgs{NumOfInterfaces: 1}}
+		selected := PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)
+		Expect(selected).To(Equal([]string{"10.0.1.0/24"}))
+		allocate(req, selected)
+		Expect(PerfMonitor.GetInterfaceStat(interfaceNameMap)["10.0.1.0/24"].Assigned).To(Equal(1))
+		now = now.Add(ASSIGNMENT_HOLD_SAMPLES * interval)
+		Expect(PerfMonitor.GetInterfaceStat(interfaceNameMap)["10.0.1.0/24"].Assigned).To(BeZero())
+		Expect(PerfOptSelector{}.Select(req, interfaceNameMap, nameNetMap, nil)).To(Equal([]string{"10.0.1.0/24"}))
+	})
+
+	It("drops removed interface", func() {
+		sample(map[string]int64{"eth1": 500000000})
+		Expect(os.RemoveAll(filepath.Join(iface.SysClassNet, "eth1"))).To(Succeed())
+		now = now.Add(interval)
+		PerfMonitor.Sample(iface.ReadLinkSamples(devNames, nil), now)
+		Expect(PerfMonitor.ifaceStat).NotTo(HaveKey("eth1"))
+		Expect(PerfMonitor.ifaceStat).To(HaveKey("eth2"))
+	})
+})
+
+func readFile(filePath string) string {
+	content, err := os.ReadFile(filePath)
+	Expect(err).NotTo(HaveOccurred())
+	return string(content[:len(content)-1])
+}
@@ -45,26 +45,42 @@ func getDeviceIDs(deviceMap map[string]*gnet.NIC, req NICSelectRequest) []string
 	return deviceIDs
 }
 
-// DefaultSelector simply selects interface in order
+// getCandidates returns network addresses selectable for the request without limiting the number of interfaces
+func getCandidates(req NICSelectRequest, interfaceNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string) []string {
+	candidateReq := req
+	candidateReq.NicSet.NumOfInterfaces = 0
+	return (DefaultSelector{}).Select(candidateReq, interfaceNameMap, nameNetMap, resourceMap)
+}
+
+// DefaultSelector simply selects interface in order,
+// interfaces filtered out from interfaceNameMap are not selected even if requested
 func (DefaultSelector) Select(req NICSelectRequest, interfaceNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string) []string {
 	selectedMaster := []string{}
 	maxSize := req.NicSet.NumOfInterfaces
 	fixedSet := req.NicSet.InterfaceNames
+	// requested is true if the fixset or master network addresses are defined for the host
+	requested := len(req.MasterNetAddrs) > 0
 	if len(fixedSet) > 0 {
+		requested = false
 		// use defined fixset
 		for _, devName := range fixedSet {
 			if netAddress, exists := nameNetMap[devName]; exists {
-				selectedMaster = append(selectedMaster, netAddress)
+				requested = true
+				if _, candidate := interfaceNameMap[netAddress]; candidate {
+					selectedMaster = append(selectedMaster, netAddress)
+				}
 			}
 		}
 	} else {
 		// use defined master network addresses
 		for _, netAddress := range req.MasterNetAddrs {
-			log.Printf("select by net %s", netAddress)
-			selectedMaster = append(selectedMaster, netAddress)
+			if _, candidate := interfaceNameMap[netAddress]; candidate {
+				log.Printf("select by net %s", netAddress)
+				selectedMaster = append(selectedMaster, netAddress)
+			}
 		}
 	}
-	if len(selectedMaster) == 0 {
+	if len(selectedMaster) == 0 && !requested {
 		// apply all network addresses
 		for netAddress := range interfaceNameMap {
 			log.Printf("select %s", netAddress)
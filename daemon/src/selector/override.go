@@ -0,0 +1,99 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+)
+
+var (
+	supportedStrategies       = []string{string(None), CostOpt, PerfOpt, DevClass, Topology}
+	supportedPlacements       = []string{BalancePlacement, PackPlacement}
+	supportedUnhealthyActions = []string{DegradeOnUnhealthy, FailOnUnhealthy}
+	supportedSelectionStages  = []string{DevClass, LinkUpStage, NamesStage, Topology, PerfOpt, CostOpt}
+)
+
+// validatePolicyOverride checks the attachment policy in the pod annotation as the MultiNicNetwork webhook does
+func validatePolicyOverride(override backend.AttachmentPolicy) error {
+	errs := []string{}
+	notSupported := func(field, value string, supported []string) {
+		errs = append(errs, fmt.Sprintf("%s %q not supported (%s)", field, value, strings.Join(supported, ", ")))
+	}
+	if override.Strategy != "" && !containsString(supportedStrategies, override.Strategy) {
+		notSupported("strategy", override.Strategy, supportedStrategies)
+	}
+	if override.Placement != "" && !containsString(supportedPlacements, override.Placement) {
+		notSupported("placement", override.Placement, supportedPlacements)
+	}
+	if override.OnUnhealthy != "" && !containsString(supportedUnhealthyActions, override.OnUnhealthy) {
+		notSupported("onUnhealthy", override.OnUnhealthy, supportedUnhealthyActions)
+	}
+	if len(override.Pipeline) > 0 && override.Strategy != "" && override.Strategy != string(None) {
+		errs = append(errs, "strategy must be none when pipeline is set")
+	}
+	for i, stage := range override.Pipeline {
+		if !containsString(supportedSelectionStages, stage.Type) {
+			notSupported(fmt.Sprintf("pipeline[%d].type", i), stage.Type, supportedSelectionStages)
+		}
+		if stage.Type == NamesStage && len(stage.Names) == 0 {
+			errs = append(errs, fmt.Sprintf("pipeline[%d].names required for names stage", i))
+		}
+		if stage.Placement != "" && !containsString(supportedPlacements, stage.Placement) {
+			notSupported(fmt.Sprintf("pipeline[%d].placement", i), stage.Placement, supportedPlacements)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// applyPolicyOverride returns the network policy overridden by non-empty fields of the pod annotation,
+// strategy and pipeline are replaced together as the strategy is a shorthand of single-stage pipeline
+func applyPolicyOverride(policy backend.AttachmentPolicy, override backend.AttachmentPolicy) backend.AttachmentPolicy {
+	if override.Strategy != "" || len(override.Pipeline) > 0 {
+		policy.Strategy = override.Strategy
+		policy.Pipeline = override.Pipeline
+	}
+	if override.Target != "" {
+		policy.Target = override.Target
+	}
+	if override.Placement != "" {
+		policy.Placement = override.Placement
+	}
+	if override.OnUnhealthy != "" {
+		policy.OnUnhealthy = override.OnUnhealthy
+	}
+	return policy
+}
+
+// describePolicy lists non-empty fields of the policy
+func describePolicy(policy backend.AttachmentPolicy) string {
+	fields := []string{}
+	if policy.Strategy != "" {
+		fields = append(fields, "strategy="+policy.Strategy)
+	}
+	if len(policy.Pipeline) > 0 {
+		stageTypes := []string{}
+		for _, stage := range policy.Pipeline {
+			stageTypes = append(stageTypes, stage.Type)
+		}
+		fields = append(fields, "pipeline="+strings.Join(stageTypes, ">"))
+	}
+	if policy.Target != "" {
+		fields = append(fields, "target="+policy.Target)
+	}
+	if policy.Placement != "" {
+		fields = append(fields, "placement="+policy.Placement)
+	}
+	if policy.OnUnhealthy != "" {
+		fields = append(fields, "onUnhealthy="+policy.OnUnhealthy)
+	}
+	return strings.Join(fields, ",")
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+)
+
+var _ = Describe("Test Policy Override", func() {
+	networkPolicy := backend.AttachmentPolicy{
+		Strategy:  "none",
+		Placement: BalancePlacement,
+		Pipeline:  []backend.SelectionStage{{Type: LinkUpStage}, {Type: Topology}},
+	}
+
+	It("reads policy from pod annotation", func() {
+		var req NICSelectRequest
+		Expect(json.Unmarshal([]byte(`{"pod": "pod", "namespace": "default", "args": {"nics": 1, "policy": {"strategy": "costOpt", "placement": "pack"}}}`), &req)).To(Succeed())
+		Expect(req.NicSet.NumOfInterfaces).To(Equal(1))
+		Expect(req.NicSet.Policy).To(Equal(&backend.AttachmentPolicy{Strategy: CostOpt, Placement: PackPlacement}))
+	})
+
+	DescribeTable("validates override", func(override backend.AttachmentPolicy, expectedErr string) {
+		err := validatePolicyOverride(override)
+		if expectedErr == "" {
+			Expect(err).NotTo(HaveOccurred())
+			return
+		}
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(expectedErr))
+	},
+		Entry("strategy", backend.AttachmentPolicy{Strategy: Topology}, ""),
+		Entry("placement only", backend.AttachmentPolicy{Placement: PackPlacement}, ""),
+		Entry("pipeline", backend.AttachmentPolicy{Pipeline: []backend.SelectionStage{{Type: NamesStage, Names: []string{"eth1"}}, {Type: CostOpt}}}, ""),
+		Entry("unknown strategy", backend.AttachmentPolicy{Strategy: "fastest"}, `strategy "fastest" not supported`),
+		Entry("unknown placement", backend.AttachmentPolicy{Placement: "spread"}, `placement "spread" not supported`),
+		Entry("unknown onUnhealthy", backend.AttachmentPolicy{OnUnhealthy: "ignore"}, `onUnhealthy "ignore" not supported`),
+		Entry("pipeline with strategy", backend.AttachmentPolicy{Strategy: PerfOpt, Pipeline: []backend.SelectionStage{{Type: Topology}}}, "strategy must be none"),
+		Entry("unknown stage", backend.AttachmentPolicy{Pipeline: []backend.SelectionStage{{Type: "fastest"}}}, `pipeline[0].type "fastest" not supported`),
+		Entry("names without names", backend.AttachmentPolicy{Pipeline: []backend.SelectionStage{{Type: Topology}, {Type: NamesStage}}}, "pipeline[1].names required"),
+	)
+
+	DescribeTable("applies override to network policy", func(override backend.AttachmentPolicy, expected backend.AttachmentPolicy) {
+		Expect(applyPolicyOverride(networkPolicy, override)).To(Equal(expected))
+	},
+		Entry("strategy replaces pipeline", backend.AttachmentPolicy{Strategy: CostOpt},
+			backend.AttachmentPolicy{Strategy: CostOpt, Placement: BalancePlacement}),
+		Entry("pipeline replaces pipeline", backend.AttachmentPolicy{Pipeline: []backend.SelectionStage{{Type: PerfOpt}}},
+			backend.AttachmentPolicy{Placement: BalancePlacement, Pipeline: []backend.SelectionStage{{Type: PerfOpt}}}),
+		Entry("parameters only", backend.AttachmentPolicy{Placement: PackPlacement, OnUnhealthy: FailOnUnhealthy},
+			backend.AttachmentPolicy{Strategy: "none", Placement: PackPlacement, OnUnhealthy: FailOnUnhealthy, Pipeline: networkPolicy.Pipeline}),
+	)
+
+	It("records override in decision", func() {
+		override := backend.AttachmentPolicy{Strategy: CostOpt, Placement: PackPlacement}
+		req := NICSelectRequest{NicSet: NicArgs{Policy: &override}}
+		decision := newSelectionDecision(CostOpt, req, map[string]string{"10.0.1.0/24": "eth1"})
+		decision.Override = describePolicy(override)
+		decision.Selected = []string{"eth1"}
+		Expect(decision.String()).To(Equal("selected [eth1] by costOpt from [eth1] (override: strategy=costOpt,placement=pack)"))
+		Expect(describePolicy(networkPolicy)).To(Equal("strategy=none,pipeline=linkUp>topology,placement=balance"))
+	})
+})
@@ -0,0 +1,217 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+var (
+	pciDevicesDir    = iface.SysBusPci
+	nvidiaGPUInfoDir = "/proc/driver/nvidia/gpus"
+	cpuNodeDir       = "/sys/devices/system/node"
+
+	busIDPattern = regexp.MustCompile(`^[0-9a-fA-F]{4,8}:[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-7]$`)
+)
+
+// affinity levels between two PCI devices from the farthest to the closest
+const (
+	noAffinity = iota
+	numaAffinity
+	hostBridgeAffinity
+	switchAffinity
+)
+
+// PciDevice is a PCI device placed in the PCIe hierarchy
+type PciDevice struct {
+	BusID    string
+	Class    string
+	NumaNode string
+	// Bridges is bus IDs of the upstream bridges (root port and PCIe switch ports) of the device
+	Bridges []string
+	// HostBridge is the PCI root of the device (e.g., pci0000:00), empty if unknown
+	HostBridge string
+}
+
+// PciTree is PCI devices of the host keyed by normalized bus ID
+type PciTree struct {
+	Devices map[string]PciDevice
+}
+
+// normalizeBusID converts bus ID to the sysfs format, e.g., 00000000:0C:05.0 to 0000:0c:05.0
+func normalizeBusID(busID string) string {
+	busID = strings.ToLower(strings.TrimSpace(busID))
+	parts := strings.SplitN(busID, ":", 2)
+	if len(parts) == 2 && len(parts[0]) > 4 {
+		return parts[0][len(parts[0])-4:] + ":" + parts[1]
+	}
+	return busID
+}
+
+// BuildPciTreeFromSysfs reads PCI devices and their upstream bridges from /sys/bus/pci/devices
+// where each entry links to /sys/devices/pci<domain>:<bus>/<root port>/.../<device>
+func BuildPciTreeFromSysfs(devicesDir string) *PciTree {
+	tree := &PciTree{Devices: make(map[string]PciDevice)}
+	entries, err := os.ReadDir(devicesDir)
+	if err != nil {
+		log.Printf("cannot read PCI devices from %s: %v", devicesDir, err)
+		return tree
+	}
+	for _, entry := range entries {
+		devicePath, err := filepath.EvalSymlinks(filepath.Join(devicesDir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		device := PciDevice{BusID: normalizeBusID(entry.Name()), Bridges: []string{}}
+		for _, component := range strings.Split(filepath.Dir(devicePath), string(filepath.Separator)) {
+			if strings.HasPrefix(component, "pci") {
+				device.HostBridge = component
+				device.Bridges = []string{}
+			} else if busIDPattern.MatchString(component) {
+				device.Bridges = append(device.Bridges, normalizeBusID(component))
+			}
+		}
+		if class, err := os.ReadFile(filepath.Join(devicePath, "class")); err == nil {
+			device.Class = strings.TrimSpace(string(class))
+		}
+		if numaNode, err := os.ReadFile(filepath.Join(devicePath, "numa_node")); err == nil {
+			device.NumaNode = strings.TrimSpace(string(numaNode))
+		}
+		tree.Devices[device.BusID] = device
+	}
+	return tree
+}
+
+// BuildPciTreeFromTopology places PCI devices of the topology file under their parent bridges and NUMA nodes
+func BuildPciTreeFromTopology(topology NcclTopolgy) *PciTree {
+	tree := &PciTree{Devices: make(map[string]PciDevice)}
+	var addPCIs func(pcis []PCITag, numaId string, bridges []string)
+	addPCIs = func(pcis []PCITag, numaId string, bridges []string) {
+		for _, pci := range pcis {
+			busID := normalizeBusID(pci.BusId)
+			tree.Devices[busID] = PciDevice{
+				BusID:    busID,
+				Class:    pci.Class,
+				NumaNode: numaId,
+				Bridges:  bridges,
+			}
+			addPCIs(pci.PCIs, numaId, append(append([]string{}, bridges...), busID))
+		}
+	}
+	for _, cpu := range topology.CPUs {
+		addPCIs(cpu.PCIs, cpu.NumaId, []string{})
+	}
+	return tree
+}
+
+// Affinity returns how close two PCI devices are: under the same PCIe switch (or root port),
+// under the same host bridge, on the same NUMA node, or none
+func (t *PciTree) Affinity(busID1, busID2 string) int {
+	device1, found1 := t.Devices[normalizeBusID(busID1)]
+	device2, found2 := t.Devices[normalizeBusID(busID2)]
+	if !found1 || !found2 {
+		return noAffinity
+	}
+	for _, bridge := range device1.Bridges {
+		for _, otherBridge := range device2.Bridges {
+			if bridge == otherBridge {
+				return switchAffinity
+			}
+		}
+	}
+	if device1.HostBridge != "" && device1.HostBridge == device2.HostBridge {
+		return hostBridgeAffinity
+	}
+	if device1.NumaNode != "" && device1.NumaNode != "-1" && device1.NumaNode == device2.NumaNode {
+		return numaAffinity
+	}
+	return noAffinity
+}
+
+// getNvidiaGPUBusMap maps GPU UUID to bus ID from /proc/driver/nvidia/gpus/<bus ID>/information
+// for nodes where NVML is not available to the daemon
+func getNvidiaGPUBusMap(infoDir string) map[string]string {
+	gpuIdBusIdMap := make(map[string]string)
+	entries, err := os.ReadDir(infoDir)
+	if err != nil {
+		return gpuIdBusIdMap
+	}
+	for _, entry := range entries {
+		infoFile, err := os.Open(filepath.Join(infoDir, entry.Name(), "information"))
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(infoFile)
+		for scanner.Scan() {
+			key, value, found := strings.Cut(scanner.Text(), ":")
+			if found && strings.TrimSpace(key) == "GPU UUID" {
+				gpuIdBusIdMap[strings.TrimSpace(value)] = normalizeBusID(entry.Name())
+			}
+		}
+		infoFile.Close()
+	}
+	return gpuIdBusIdMap
+}
+
+// getNicBusID returns bus ID of the interface from discovered interface info or /sys/class/net/<devName>/device
+func getNicBusID(devName string) string {
+	if info, found := iface.GetInterfaceInfoCache()[devName]; found && info.PciAddress != "" {
+		return normalizeBusID(info.PciAddress)
+	}
+	devicePath, err := filepath.EvalSymlinks(filepath.Join(iface.SysClassNet, devName, "device"))
+	if err != nil {
+		return ""
+	}
+	return normalizeBusID(filepath.Base(devicePath))
+}
+
+// parseCPUList parses CPU list such as 0-3,8,10-11
+func parseCPUList(cpuList string) []int64 {
+	cpuIds := []int64{}
+	for _, cpuRange := range strings.Split(strings.TrimSpace(cpuList), ",") {
+		first, last, isRange := strings.Cut(cpuRange, "-")
+		if !isRange {
+			last = first
+		}
+		firstId, err1 := strconv.ParseInt(first, 10, 64)
+		lastId, err2 := strconv.ParseInt(last, 10, 64)
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		for cpuId := firstId; cpuId <= lastId; cpuId++ {
+			cpuIds = append(cpuIds, cpuId)
+		}
+	}
+	return cpuIds
+}
+
+// getCPUNumaMap maps CPU ID to NUMA node from /sys/devices/system/node/node<N>/cpulist
+func getCPUNumaMap(nodeDir string) map[int64]string {
+	cpuNumaMap := make(map[int64]string)
+	cpuListFiles, err := filepath.Glob(filepath.Join(nodeDir, "node*", "cpulist"))
+	if err != nil {
+		return cpuNumaMap
+	}
+	for _, cpuListFile := range cpuListFiles {
+		cpuList, err := os.ReadFile(cpuListFile)
+		if err != nil {
+			continue
+		}
+		numaNode := strings.TrimPrefix(filepath.Base(filepath.Dir(cpuListFile)), "node")
+		for _, cpuId := range parseCPUList(string(cpuList)) {
+			cpuNumaMap[cpuId] = numaNode
+		}
+	}
+	return cpuNumaMap
+}
@@ -0,0 +1,110 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"sync"
+	"time"
+)
+
+// SELECTION_TIMEOUT is how long a selection is counted before its allocation is confirmed
+const SELECTION_TIMEOUT = 30 * time.Second
+
+// pendingSelection is interfaces selected for a pod in a network whose addresses are not allocated yet
+type pendingSelection struct {
+	hostName string
+	// podKey is namespace/name of the pod
+	podKey    string
+	masters   []string
+	timestamp time.Time
+}
+
+// pendingSelectionTracker keeps selections of costOpt, perfOpt, and pipeline keyed by pod and network
+// so that the next selections count them as tentative load until the allocation is confirmed or they expire
+type pendingSelectionTracker struct {
+	sync.Mutex
+	selections map[string]pendingSelection
+}
+
+var pendingSelections = &pendingSelectionTracker{selections: make(map[string]pendingSelection)}
+
+// getSelectionKey returns key of the selection of the pod in the network
+func getSelectionKey(podNamespace, podName, netAttachDefName string) string {
+	return podNamespace + "/" + podName + "/" + netAttachDefName
+}
+
+// record keeps the selected interfaces of the request, replacing the previous selection of the pod in the network
+func (t *pendingSelectionTracker) record(req NICSelectRequest, masters []string, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+	t.selections[getSelectionKey(req.PodNamespace, req.PodName, req.NetAttachDefName)] = pendingSelection{
+		hostName:  req.HostName,
+		podKey:    req.PodNamespace + "/" + req.PodName,
+		masters:   masters,
+		timestamp: now,
+	}
+}
+
+// confirm removes the selection of the pod in the network once its allocation succeeds
+func (t *pendingSelectionTracker) confirm(podNamespace, podName, netAttachDefName string, now time.Time) (pendingSelection, bool) {
+	t.Lock()
+	defer t.Unlock()
+	t.expireLocked(now)
+	key := getSelectionKey(podNamespace, podName, netAttachDefName)
+	selection, found := t.selections[key]
+	delete(t.selections, key)
+	return selection, found
+}
+
+// list returns selections of the host not yet expired, except the selection of the request itself
+func (t *pendingSelectionTracker) list(req NICSelectRequest, now time.Time) []pendingSelection {
+	t.Lock()
+	defer t.Unlock()
+	t.expireLocked(now)
+	requestKey := getSelectionKey(req.PodNamespace, req.PodName, req.NetAttachDefName)
+	selections := []pendingSelection{}
+	for key, selection := range t.selections {
+		if key != requestKey && selection.hostName == req.HostName {
+			selections = append(selections, selection)
+		}
+	}
+	return selections
+}
+
+// countMasters returns number of pending selections of each interface of the host except the selection of the request
+func (t *pendingSelectionTracker) countMasters(req NICSelectRequest, now time.Time) map[string]int {
+	counts := make(map[string]int)
+	for _, selection := range t.list(req, now) {
+		for _, master := range selection.masters {
+			counts[master] += 1
+		}
+	}
+	return counts
+}
+
+func (t *pendingSelectionTracker) expireLocked(now time.Time) {
+	for key, selection := range t.selections {
+		if now.Sub(selection.timestamp) >= SELECTION_TIMEOUT {
+			delete(t.selections, key)
+		}
+	}
+}
+
+// ConfirmSelection ends the pending selection of the pod in the network after its addresses are allocated,
+// the allocated interfaces are counted in the perfOpt load until the traffic shows up in the samples
+func ConfirmSelection(podNamespace, podName, netAttachDefName string, allocatedMasters []string) {
+	selection, found := pendingSelections.confirm(podNamespace, podName, netAttachDefName, time.Now())
+	if !found {
+		return
+	}
+	assigned := []string{}
+	for _, master := range selection.masters {
+		if containsString(allocatedMasters, master) {
+			assigned = append(assigned, master)
+		}
+	}
+	PerfMonitor.Assign(assigned)
+}
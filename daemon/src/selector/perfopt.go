@@ -5,10 +5,38 @@
 
 package selector
 
+import (
+	"log"
+	"time"
+)
+
 type PerfOptSelector struct{}
 
-// PerfOptSelector
+// PerfOptSelector selects the requested number of interfaces with the lowest recent load
 func (PerfOptSelector) Select(req NICSelectRequest, interfaceNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string) []string {
-	// TODO
-	return (DefaultSelector{}).Select(req, interfaceNameMap, nameNetMap, resourceMap)
+	candidates := getCandidates(req, interfaceNameMap, nameNetMap, resourceMap)
+
+	now := time.Now()
+	metricMap := getInterfaceLoad(req, interfaceNameMap, now)
+	SortByLoad(candidates, metricMap)
+	maxSize := req.NicSet.NumOfInterfaces
+	if maxSize <= 0 || maxSize > len(candidates) {
+		maxSize = len(candidates)
+	}
+	selected := candidates[0:maxSize]
+
+	selectedMasters := []string{}
+	for _, netAddress := range selected {
+		metric := metricMap[netAddress]
+		log.Printf("perfOpt select %s (utilization=%.3f, drop=%.4f, assigned=%d, pending=%d)", netAddress, metric.Utilization, metric.DropRate, metric.Assigned, metric.Pending)
+		if master, found := interfaceNameMap[netAddress]; found {
+			selectedMasters = append(selectedMasters, master)
+		}
+	}
+	if len(selectedMasters) > 0 {
+		PerfMonitor.Prefer(selectedMasters[0])
+	}
+	// counted as pending load until the allocation is confirmed
+	pendingSelections.record(req, selectedMasters, now)
+	return selected
 }
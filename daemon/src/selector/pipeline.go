@@ -0,0 +1,211 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+// stage types of selection pipeline in addition to the strategies devClass, topology, perfOpt, and costOpt
+const (
+	LinkUpStage = "linkUp"
+	NamesStage  = "names"
+)
+
+// PipelineSelector evaluates filter and score stages of the attachment policy in order,
+// filter stages remove NICs from the candidates and score stages rank the remaining candidates
+// where a later score stage breaks ties of the earlier ones; ties of all stages are broken by network address
+type PipelineSelector struct {
+	Stages []backend.SelectionStage
+	// Placement is placement of costOpt stage without its own placement
+	Placement string
+	// Topology scores topology stage with the pod resources
+	Topology *NumaAwareSelector
+	// filterReasons is why candidates are filtered out keyed by network address
+	filterReasons map[string]string
+}
+
+func NewPipelineSelector(policy backend.AttachmentPolicy, topology *NumaAwareSelector) *PipelineSelector {
+	return &PipelineSelector{
+		Stages:        policy.Pipeline,
+		Placement:     policy.Placement,
+		Topology:      topology,
+		filterReasons: make(map[string]string),
+	}
+}
+
+// String returns stage types joined in order
+func (s *PipelineSelector) String() string {
+	stageTypes := []string{}
+	for _, stage := range s.Stages {
+		stageTypes = append(stageTypes, stage.Type)
+	}
+	return strings.Join(stageTypes, ">")
+}
+
+// FilterReasons returns why candidates are filtered out by the last selection
+func (s *PipelineSelector) FilterReasons() map[string]string {
+	return s.filterReasons
+}
+
+// filter keeps candidates accepted by the filter stage
+func (s *PipelineSelector) filter(stage backend.SelectionStage, req NICSelectRequest, candidates []string, interfaceNameMap map[string]string, nameNetMap map[string]string) []string {
+	accepted := make(map[string]bool)
+	reason := fmt.Sprintf("filtered by %s", stage.Type)
+	switch stage.Type {
+	case DevClass:
+		className := stage.Class
+		if className == "" {
+			className = req.NicSet.DevClass
+		}
+		if className == "" {
+			log.Printf("no device class for %s stage, skip", stage.Type)
+			return candidates
+		}
+		reason = fmt.Sprintf("%s %s", NotInDeviceClass, className)
+		classNameMap := make(map[string]string)
+		for _, netAddress := range candidates {
+			classNameMap[netAddress] = interfaceNameMap[netAddress]
+		}
+		filterByDeviceClass(className, classNameMap, nameNetMap)
+		for netAddress := range classNameMap {
+			accepted[netAddress] = true
+		}
+	case LinkUpStage:
+		reason = iface.LinkDownReason
+		for _, netAddress := range candidates {
+			master := interfaceNameMap[netAddress]
+			up, err := iface.IsLinkUp(master)
+			if err != nil {
+				log.Printf("cannot read link status of %s: %v", master, err)
+				continue
+			}
+			accepted[netAddress] = up
+		}
+	case NamesStage:
+		reason = "not in names of pipeline"
+		for _, netAddress := range candidates {
+			accepted[netAddress] = containsString(stage.Names, interfaceNameMap[netAddress])
+		}
+	}
+	remaining := []string{}
+	for _, netAddress := range candidates {
+		if accepted[netAddress] {
+			remaining = append(remaining, netAddress)
+		} else {
+			s.filterReasons[netAddress] = reason
+		}
+	}
+	return remaining
+}
+
+// score returns scores of the candidates by the score stage, higher is preferred
+func (s *PipelineSelector) score(stage backend.SelectionStage, req NICSelectRequest, candidates []string, interfaceNameMap map[string]string, resourceMap map[string][]string, now time.Time) map[string][]float64 {
+	scores := make(map[string][]float64)
+	switch stage.Type {
+	case Topology:
+		if s.Topology == nil {
+			log.Printf("no topology for %s stage, skip", stage.Type)
+			return scores
+		}
+		for netAddress, counts := range s.Topology.getAffinityCounts(candidates, interfaceNameMap, resourceMap) {
+			for _, count := range counts {
+				scores[netAddress] = append(scores[netAddress], float64(count))
+			}
+		}
+	case PerfOpt:
+		metricMap := getInterfaceLoad(req, interfaceNameMap, now)
+		rankScores := getRankScores(candidates, metricMap)
+		for _, netAddress := range candidates {
+			metric := metricMap[netAddress]
+			preferred := 0.0
+			if metric.Preferred {
+				preferred = 1
+			}
+			scores[netAddress] = []float64{-rankScores[netAddress], preferred, -float64(metric.selections())}
+		}
+	case CostOpt:
+		placement := stage.Placement
+		if placement == "" {
+			placement = s.Placement
+		}
+		podCounts := getPodCounts(req, now)
+		for _, netAddress := range candidates {
+			count := float64(podCounts[interfaceNameMap[netAddress]])
+			if placement == PackPlacement {
+				scores[netAddress] = []float64{count}
+			} else {
+				scores[netAddress] = []float64{-count}
+			}
+		}
+	}
+	return scores
+}
+
+func (s *PipelineSelector) Select(req NICSelectRequest, interfaceNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string) []string {
+	candidates := getCandidates(req, interfaceNameMap, nameNetMap, resourceMap)
+
+	now := time.Now()
+	scores := make(map[string][]float64)
+	for _, stage := range s.Stages {
+		switch Strategy(stage.Type) {
+		case DevClass, LinkUpStage, NamesStage:
+			candidates = s.filter(stage, req, candidates, interfaceNameMap, nameNetMap)
+		case Topology, PerfOpt, CostOpt:
+			stageScores := s.score(stage, req, candidates, interfaceNameMap, resourceMap, now)
+			for _, netAddress := range candidates {
+				scores[netAddress] = append(scores[netAddress], stageScores[netAddress]...)
+			}
+		default:
+			log.Printf("unknown selection stage %s, skip", stage.Type)
+		}
+		log.Printf("pipeline %s: %s stage candidates %v", s.String(), stage.Type, candidates)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		scoresI, scoresJ := scores[candidates[i]], scores[candidates[j]]
+		for k := 0; k < len(scoresI) && k < len(scoresJ); k++ {
+			if scoresI[k] != scoresJ[k] {
+				return scoresI[k] > scoresJ[k]
+			}
+		}
+		return candidates[i] < candidates[j]
+	})
+	maxSize := req.NicSet.NumOfInterfaces
+	if maxSize <= 0 || maxSize > len(candidates) {
+		maxSize = len(candidates)
+	}
+	selected := candidates[0:maxSize]
+
+	selectedMasters := []string{}
+	for _, netAddress := range selected {
+		log.Printf("pipeline %s select %s (scores %v)", s.String(), netAddress, scores[netAddress])
+		selectedMasters = append(selectedMasters, interfaceNameMap[netAddress])
+	}
+	// keep track of the selection for the next pods as the single-stage selectors
+	loadStage := false
+	for _, stage := range s.Stages {
+		switch Strategy(stage.Type) {
+		case PerfOpt:
+			if len(selectedMasters) > 0 {
+				PerfMonitor.Prefer(selectedMasters[0])
+			}
+			loadStage = true
+		case CostOpt:
+			loadStage = true
+		}
+	}
+	if loadStage {
+		pendingSelections.record(req, selectedMasters, now)
+	}
+	return selected
+}
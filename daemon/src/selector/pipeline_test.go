@@ -0,0 +1,124 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+var _ = Describe("Test Pipeline Selector", func() {
+	hostName := "host1"
+	interfaceNameMap := map[string]string{
+		"10.0.1.0/24": "pl1",
+		"10.0.2.0/24": "pl2",
+		"10.0.3.0/24": "pl3",
+	}
+	nameNetMap := map[string]string{
+		"pl1": "10.0.1.0/24",
+		"pl2": "10.0.2.0/24",
+		"pl3": "10.0.3.0/24",
+	}
+	gpuBusID := "0000:03:00.0"
+	resourceMap := map[string][]string{GPUResourceName: {gpuBusID}}
+
+	var (
+		topology            *NumaAwareSelector
+		attachedPods        map[string][]string
+		originalSysClassNet string
+	)
+
+	newRequest := func(podName string, nics int) NICSelectRequest {
+		return NICSelectRequest{PodName: podName, PodNamespace: "default", HostName: hostName, NicSet: NicArgs{NumOfInterfaces: nics}}
+	}
+
+	newPipeline := func(stageTypes ...string) *PipelineSelector {
+		policy := backend.AttachmentPolicy{Strategy: "none"}
+		for _, stageType := range stageTypes {
+			policy.Pipeline = append(policy.Pipeline, backend.SelectionStage{Type: stageType})
+		}
+		return NewPipelineSelector(policy, topology)
+	}
+
+	BeforeEach(func() {
+		// pl1 and pl2 on the same NUMA node of GPU, pl3 on the other node
+		topology = &NumaAwareSelector{
+			gpuIDBusMap: map[string]string{},
+			NumaMap:     map[string]string{},
+			cpuNumaMap:  map[int64]string{},
+			Tree: &PciTree{Devices: map[string]PciDevice{
+				gpuBusID:       {BusID: gpuBusID, NumaNode: "0", Bridges: []string{"0000:00:01.0"}},
+				"0000:04:00.0": {BusID: "0000:04:00.0", NumaNode: "0", Bridges: []string{"0000:00:02.0"}},
+				"0000:05:00.0": {BusID: "0000:05:00.0", NumaNode: "0", Bridges: []string{"0000:00:03.0"}},
+				"0000:81:00.0": {BusID: "0000:81:00.0", NumaNode: "1", Bridges: []string{"0000:80:01.0"}},
+			}},
+		}
+		iface.SetInterfaceInfoCache("pl1", backend.InterfaceInfoType{InterfaceName: "pl1", PciAddress: "0000:04:00.0"})
+		iface.SetInterfaceInfoCache("pl2", backend.InterfaceInfoType{InterfaceName: "pl2", PciAddress: "0000:05:00.0"})
+		iface.SetInterfaceInfoCache("pl3", backend.InterfaceInfoType{InterfaceName: "pl3", PciAddress: "0000:81:00.0"})
+
+		originalSysClassNet = iface.SysClassNet
+		iface.SysClassNet = GinkgoT().TempDir()
+		for _, devName := range []string{"pl1", "pl2", "pl3"} {
+			writeSysfsFile(filepath.Join(iface.SysClassNet, devName), "operstate", "up")
+			writeSysfsFile(filepath.Join(iface.SysClassNet, devName), "carrier", "1")
+		}
+
+		attachedPods = map[string][]string{"pl1": {"default/a", "default/b"}}
+		GetAttachedPods = func(string) (map[string][]string, error) {
+			return attachedPods, nil
+		}
+		pendingSelections.selections = make(map[string]pendingSelection)
+	})
+
+	AfterEach(func() {
+		iface.SysClassNet = originalSysClassNet
+		GetAttachedPods = nil
+	})
+
+	DescribeTable("ranks by score stages in order", func(stageTypes []string, nics int, expected []string) {
+		selected := newPipeline(stageTypes...).Select(newRequest("new", nics), interfaceNameMap, nameNetMap, resourceMap)
+		Expect(selected).To(Equal(expected))
+	},
+		Entry("topology then costOpt", []string{Topology, CostOpt}, 1, []string{"10.0.2.0/24"}),
+		Entry("topology then costOpt for two NICs", []string{Topology, CostOpt}, 2, []string{"10.0.2.0/24", "10.0.1.0/24"}),
+		Entry("costOpt then topology", []string{CostOpt, Topology}, 2, []string{"10.0.2.0/24", "10.0.3.0/24"}),
+		Entry("topology only", []string{Topology}, 3, []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}),
+		Entry("no stage", []string{}, 2, []string{"10.0.1.0/24", "10.0.2.0/24"}),
+	)
+
+	It("filters by link state and names before scoring", func() {
+		writeSysfsFile(filepath.Join(iface.SysClassNet, "pl2"), "carrier", "0")
+		policy := backend.AttachmentPolicy{Strategy: "none", Pipeline: []backend.SelectionStage{
+			{Type: LinkUpStage},
+			{Type: NamesStage, Names: []string{"pl1", "pl2"}},
+			{Type: Topology},
+		}}
+		pipeline := NewPipelineSelector(policy, topology)
+		Expect(pipeline.String()).To(Equal("linkUp>names>topology"))
+		selected := pipeline.Select(newRequest("new", 0), interfaceNameMap, nameNetMap, resourceMap)
+		Expect(selected).To(Equal([]string{"10.0.1.0/24"}))
+		Expect(pipeline.FilterReasons()).To(Equal(map[string]string{
+			"10.0.2.0/24": "link down",
+			"10.0.3.0/24": "not in names of pipeline",
+		}))
+	})
+
+	It("packs with costOpt stage placement and records the selection", func() {
+		policy := backend.AttachmentPolicy{Strategy: "none", Pipeline: []backend.SelectionStage{
+			{Type: CostOpt, Placement: PackPlacement},
+		}}
+		selected := NewPipelineSelector(policy, topology).Select(newRequest("new", 1), interfaceNameMap, nameNetMap, resourceMap)
+		Expect(selected).To(Equal([]string{"10.0.1.0/24"}))
+		Expect(pendingSelections.selections).To(HaveKey(getSelectionKey("default", "new", "")))
+		Expect(pendingSelections.selections[getSelectionKey("default", "new", "")].masters).To(Equal([]string{"pl1"}))
+	})
+})
@@ -0,0 +1,144 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"log"
+	"sort"
+)
+
+const (
+	// Rail is strategy of rail-optimized network reported in the selection decision
+	Rail = "rail"
+	// NotPairedRail is reason to reject a rail which is not paired with the allocated GPUs
+	NotPairedRail = "not paired with allocated GPUs"
+)
+
+// RailSelector attaches the rail NIC paired with each GPU allocated to the pod in rail-optimized network,
+// a GPU is paired with the rail NIC under the same PCIe switch or host bridge,
+// or with the rail of the GPU index (order of GPU bus IDs on the host) if no such NIC is found.
+// Rails not paired with the GPUs are filtered out before the strategy or pipeline of the policy selects from the paired rails.
+type RailSelector struct {
+	// Rails is network addresses of master networks in rail order
+	Rails    []string
+	Topology *NumaAwareSelector
+	// Selector selects from the paired rails by the policy, nil to select the paired rails in order of the allocated GPUs
+	Selector Selector
+	// filterReasons is why rail candidates are not selected keyed by network address
+	filterReasons map[string]string
+}
+
+func NewRailSelector(rails []string, topology *NumaAwareSelector, selector Selector) *RailSelector {
+	return &RailSelector{
+		Rails:         rails,
+		Topology:      topology,
+		Selector:      selector,
+		filterReasons: make(map[string]string),
+	}
+}
+
+// FilterReasons returns why rail candidates are not selected by the last selection,
+// including the candidates filtered out by the policy selector
+func (s *RailSelector) FilterReasons() map[string]string {
+	filterReasons := make(map[string]string)
+	if reasoner, ok := s.Selector.(filterReasoner); ok {
+		for netAddress, reason := range reasoner.FilterReasons() {
+			filterReasons[netAddress] = reason
+		}
+	}
+	for netAddress, reason := range s.filterReasons {
+		filterReasons[netAddress] = reason
+	}
+	return filterReasons
+}
+
+// getGPUIndex returns index of the GPU among GPUs of the host, or index in the allocation if unknown
+func (s *RailSelector) getGPUIndex(gpuId string, allocatedIndex int) int {
+	busId := s.Topology.getGPUBusID(gpuId)
+	if busId == "" {
+		return allocatedIndex
+	}
+	busIds := []string{}
+	for _, gpuBusId := range s.Topology.gpuIDBusMap {
+		busIds = append(busIds, normalizeBusID(gpuBusId))
+	}
+	sort.Strings(busIds)
+	for index, gpuBusId := range busIds {
+		if gpuBusId == busId {
+			return index
+		}
+	}
+	return allocatedIndex
+}
+
+// getPairedRail returns network address of the rail paired with the GPU from the rail candidates, empty if not available
+func (s *RailSelector) getPairedRail(gpuId string, allocatedIndex int, railCandidates []string, interfaceNameMap map[string]string) string {
+	bestAffinity := noAffinity
+	paired := ""
+	for _, netAddress := range railCandidates {
+		nicBusId := getNicBusID(interfaceNameMap[netAddress])
+		affinity := s.Topology.getGPUAffinity(gpuId, nicBusId, s.Topology.getNumaNode(nicBusId))
+		if affinity > bestAffinity {
+			bestAffinity = affinity
+			paired = netAddress
+		}
+	}
+	if bestAffinity >= hostBridgeAffinity {
+		return paired
+	}
+	if len(s.Rails) == 0 {
+		return ""
+	}
+	rail := s.Rails[s.getGPUIndex(gpuId, allocatedIndex)%len(s.Rails)]
+	if containsString(railCandidates, rail) {
+		return rail
+	}
+	log.Printf("rail %s of GPU %s is not available", rail, gpuId)
+	return ""
+}
+
+func (s *RailSelector) Select(req NICSelectRequest, interfaceNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string) []string {
+	candidates := getCandidates(req, interfaceNameMap, nameNetMap, resourceMap)
+	railCandidates := []string{}
+	for _, rail := range s.Rails {
+		if containsString(candidates, rail) {
+			railCandidates = append(railCandidates, rail)
+		}
+	}
+
+	paired := []string{}
+	for allocatedIndex, gpuId := range resourceMap[GPUResourceName] {
+		rail := s.getPairedRail(gpuId, allocatedIndex, railCandidates, interfaceNameMap)
+		if rail == "" {
+			continue
+		}
+		log.Printf("GPU %s paired with rail %s (%s)", gpuId, rail, interfaceNameMap[rail])
+		// GPUs paired with the same rail share the NIC
+		if !containsString(paired, rail) {
+			paired = append(paired, rail)
+		}
+	}
+	for _, rail := range railCandidates {
+		if !containsString(paired, rail) {
+			s.filterReasons[rail] = NotPairedRail
+		}
+	}
+	if s.Selector != nil {
+		pairedNameMap := make(map[string]string)
+		for _, rail := range paired {
+			pairedNameMap[rail] = interfaceNameMap[rail]
+		}
+		return s.Selector.Select(req, pairedNameMap, nameNetMap, resourceMap)
+	}
+	selected := paired
+	if req.NicSet.NumOfInterfaces > 0 && len(selected) > req.NicSet.NumOfInterfaces {
+		selected = selected[0:req.NicSet.NumOfInterfaces]
+		for _, rail := range paired[req.NicSet.NumOfInterfaces:] {
+			s.filterReasons[rail] = NotPairedRail
+		}
+	}
+	return selected
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/backend"
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+var _ = Describe("Test Rail Selector", func() {
+	rails := []string{"10.0.0.0/24", "10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}
+	interfaceNameMap := map[string]string{
+		"10.0.0.0/24": "rail0",
+		"10.0.1.0/24": "rail1",
+		"10.0.2.0/24": "rail2",
+		"10.0.3.0/24": "rail3",
+	}
+	nameNetMap := nameNetMapOf(interfaceNameMap)
+
+	var topology *NumaAwareSelector
+
+	BeforeEach(func() {
+		// GPU i and rail NIC i under the same PCIe switch, except rail3 which has no PCI information
+		topology = &NumaAwareSelector{
+			gpuIDBusMap: map[string]string{
+				"GPU-0": "0000:03:00.0",
+				"GPU-1": "0000:13:00.0",
+				"GPU-2": "0000:83:00.0",
+				"GPU-3": "0000:93:00.0",
+			},
+			NumaMap:    map[string]string{},
+			cpuNumaMap: map[int64]string{},
+			Tree: &PciTree{Devices: map[string]PciDevice{
+				"0000:03:00.0": {BusID: "0000:03:00.0", NumaNode: "0", Bridges: []string{"0000:00:01.0", "0000:01:00.0"}},
+				"0000:04:00.0": {BusID: "0000:04:00.0", NumaNode: "0", Bridges: []string{"0000:00:01.0", "0000:01:00.0"}},
+				"0000:13:00.0": {BusID: "0000:13:00.0", NumaNode: "0", Bridges: []string{"0000:00:02.0", "0000:11:00.0"}},
+				"0000:14:00.0": {BusID: "0000:14:00.0", NumaNode: "0", Bridges: []string{"0000:00:02.0", "0000:11:00.0"}},
+				"0000:83:00.0": {BusID: "0000:83:00.0", NumaNode: "1", Bridges: []string{"0000:80:01.0", "0000:81:00.0"}},
+				"0000:84:00.0": {BusID: "0000:84:00.0", NumaNode: "1", Bridges: []string{"0000:80:01.0", "0000:81:00.0"}},
+				"0000:93:00.0": {BusID: "0000:93:00.0", NumaNode: "1", Bridges: []string{"0000:80:02.0", "0000:91:00.0"}},
+			}},
+		}
+		iface.SetInterfaceInfoCache("rail0", backend.InterfaceInfoType{InterfaceName: "rail0", PciAddress: "0000:04:00.0"})
+		iface.SetInterfaceInfoCache("rail1", backend.InterfaceInfoType{InterfaceName: "rail1", PciAddress: "0000:14:00.0"})
+		iface.SetInterfaceInfoCache("rail2", backend.InterfaceInfoType{InterfaceName: "rail2", PciAddress: "0000:84:00.0"})
+		iface.SetInterfaceInfoCache("rail3", backend.InterfaceInfoType{InterfaceName: "rail3"})
+	})
+
+	DescribeTable("attaches the rail NIC paired with each GPU", func(gpuIds []string, nics int, expected []string) {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", MasterNetAddrs: rails, NicSet: NicArgs{NumOfInterfaces: nics}}
+		selector := NewRailSelector(rails, topology, nil)
+		selected := selector.Select(req, interfaceNameMap, nameNetMap, map[string][]string{GPUResourceName: gpuIds})
+		Expect(selected).To(Equal(expected))
+		for _, rail := range rails {
+			if containsString(expected, rail) {
+				Expect(selector.FilterReasons()).NotTo(HaveKey(rail))
+			} else {
+				Expect(selector.FilterReasons()).To(HaveKeyWithValue(rail, NotPairedRail))
+			}
+		}
+	},
+		Entry("paired by PCIe switch", []string{"GPU-2", "GPU-0"}, 0, []string{"10.0.2.0/24", "10.0.0.0/24"}),
+		Entry("paired by GPU index without NIC affinity", []string{"GPU-3"}, 0, []string{"10.0.3.0/24"}),
+		Entry("device ID as bus ID", []string{"0000:13:00.0"}, 0, []string{"10.0.1.0/24"}),
+		Entry("limited number", []string{"GPU-0", "GPU-1", "GPU-2"}, 2, []string{"10.0.0.0/24", "10.0.1.0/24"}),
+		Entry("no GPU", []string{}, 0, []string{}),
+	)
+
+	It("skips the paired rail if not in the candidates", func() {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", MasterNetAddrs: rails}
+		// rail1 is excluded, e.g., for its link state
+		candidateNameMap := map[string]string{"10.0.0.0/24": "rail0", "10.0.2.0/24": "rail2", "10.0.3.0/24": "rail3"}
+		selected := NewRailSelector(rails, topology, nil).Select(req, candidateNameMap, nameNetMap, map[string][]string{GPUResourceName: {"GPU-0", "GPU-1"}})
+		Expect(selected).To(Equal([]string{"10.0.0.0/24"}))
+	})
+
+	It("selects from the paired rails by the pipeline of the policy", func() {
+		req := NICSelectRequest{PodName: "pod", PodNamespace: "default", MasterNetAddrs: rails}
+		pipeline := NewPipelineSelector(backend.AttachmentPolicy{
+			Pipeline: []backend.SelectionStage{{Type: NamesStage, Names: []string{"rail1", "rail2", "rail3"}}},
+		}, nil)
+		selector := NewRailSelector(rails, topology, pipeline)
+		selected := selector.Select(req, interfaceNameMap, nameNetMap, map[string][]string{GPUResourceName: {"GPU-0", "GPU-1", "GPU-2"}})
+		Expect(selected).To(Equal([]string{"10.0.1.0/24", "10.0.2.0/24"}))
+		Expect(selector.FilterReasons()).To(Equal(map[string]string{
+			"10.0.0.0/24": "not in names of pipeline",
+			"10.0.3.0/24": NotPairedRail,
+		}))
+	})
+})
@@ -10,7 +10,9 @@ import (
 	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
 
 	"context"
+	"fmt"
 	"log"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -33,11 +35,14 @@ type NicArgs struct {
 	InterfaceNames  []string `json:"masters,omitempty"`
 	Target          string   `json:"target,omitempty"`
 	DevClass        string   `json:"class,omitempty"`
+	// Policy overrides attachment policy of the network for the pod
+	Policy *backend.AttachmentPolicy `json:"policy,omitempty"`
 }
 
 type NICSelectResponse struct {
-	DeviceIDs []string `json:"deviceIDs"`
-	Masters   []string `json:"masters"`
+	DeviceIDs []string           `json:"deviceIDs"`
+	Masters   []string           `json:"masters"`
+	Decision  *SelectionDecision `json:"decision,omitempty"`
 }
 
 type Selector interface {
@@ -62,23 +67,41 @@ func isEmptyDeviceIDs(selectedDeviceIDs []string) bool {
 	return true
 }
 
-func getDefaultResponse(req NICSelectRequest, masterNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string) NICSelectResponse {
-	selector := DefaultSelector{}
-	selectedMasterNetAddrs := selector.Select(req, masterNameMap, nameNetMap, resourceMap)
-	log.Printf("selected master networks: %v\n", selectedMasterNetAddrs)
+// getSelectedMasters returns master names of the selected network addresses which exist on the host
+func getSelectedMasters(selectedMasterNetAddrs []string, masterNameMap map[string]string, decision *SelectionDecision) []string {
 	selectedMasters := []string{}
 	for _, netAddress := range selectedMasterNetAddrs {
-		master := masterNameMap[netAddress]
-		log.Printf("select device %s\n", master)
-		if iface.DeviceExists(master) {
-			selectedMasters = append(selectedMasters, master)
-		} else {
-			log.Printf("device %s not exists, skip", master)
+		if master, ok := masterNameMap[netAddress]; ok && master != "" {
+			log.Printf("select device %s\n", master)
+			if iface.DeviceExists(master) {
+				selectedMasters = append(selectedMasters, master)
+			} else {
+				log.Printf("device %s not exists, skip", master)
+				decision.reject(netAddress, master, DeviceNotExists)
+			}
 		}
 	}
+	decision.Selected = selectedMasters
+	return selectedMasters
+}
+
+func getDefaultResponse(req NICSelectRequest, masterNameMap map[string]string, nameNetMap map[string]string, resourceMap map[string][]string, fallback string) NICSelectResponse {
+	decision := newSelectionDecision(string(None), req, masterNameMap)
+	decision.Fallback = fallback
+	healthyNameMap := make(map[string]string)
+	for netAddress, master := range masterNameMap {
+		healthyNameMap[netAddress] = master
+	}
+	unhealthyReasons := excludeUnhealthyLinks(healthyNameMap, time.Now())
+	selector := DefaultSelector{}
+	selectedMasterNetAddrs := selector.Select(req, healthyNameMap, nameNetMap, resourceMap)
+	log.Printf("selected master networks: %v\n", selectedMasterNetAddrs)
+	decision.rejectUnselected(req, masterNameMap, healthyNameMap, unhealthyReasons, selectedMasterNetAddrs)
+	selectedMasters := getSelectedMasters(selectedMasterNetAddrs, masterNameMap, decision)
 	return NICSelectResponse{
 		DeviceIDs: []string{},
 		Masters:   selectedMasters,
+		Decision:  decision,
 	}
 }
 
@@ -97,15 +120,27 @@ func InitCache(cfg *rest.Config, hostName string) {
 	}
 }
 
+// Select selects NICs for the pod and reports the decision as an event on the pod
 func Select(req NICSelectRequest) NICSelectResponse {
+	resp := selectNICs(req)
+	if resp.Decision != nil {
+		log.Printf("decision of %s/%s: %s", req.PodNamespace, req.PodName, resp.Decision.String())
+		emitSelectionEvent(req, resp.Decision)
+	}
+	return resp
+}
+
+func selectNICs(req NICSelectRequest) NICSelectResponse {
 	resourceMap := make(map[string][]string)
 	podDeviceIDs := []string{}
 	podMasters := []string{}
+	var podResources iface.PodResources
 
 	pod, err := K8sClientset.CoreV1().Pods(req.PodNamespace).Get(context.TODO(), req.PodName, metav1.GetOptions{})
 	if err == nil {
-		resourceMap, err = iface.GetPodResourceMap(pod)
+		podResources, err = iface.GetPodAssignedResources(pod)
 		if err == nil {
+			resourceMap = podResources.GetResourceMap()
 			log.Printf("resourceMap of %s: %v\n", pod.UID, resourceMap)
 			resourceNames := NetAttachDefHandler.GetResourceNames(req.NetAttachDefName, req.PodNamespace)
 			if len(resourceNames) > 0 {
@@ -128,9 +163,13 @@ func Select(req NICSelectRequest) NICSelectResponse {
 	}
 	if len(podMasters) > 0 {
 		// no need of selection returns device IDs with corresponding names
+		decision := newSelectionDecision("deviceplugin", req, map[string]string{})
+		decision.Candidates = podMasters
+		decision.Selected = podMasters
 		return NICSelectResponse{
 			DeviceIDs: podDeviceIDs,
 			Masters:   podMasters,
+			Decision:  decision,
 		}
 	}
 
@@ -148,7 +187,7 @@ func Select(req NICSelectRequest) NICSelectResponse {
 			}
 		}
 		log.Printf("default master name map: %v\n", defaultMasterNameMap)
-		return getDefaultResponse(req, defaultMasterNameMap, nameNetMap, resourceMap)
+		return getDefaultResponse(req, defaultMasterNameMap, nameNetMap, resourceMap, fmt.Sprintf("failed to get network spec: %v", err))
 	}
 	policy := netSpec.Policy
 
@@ -159,38 +198,100 @@ func Select(req NICSelectRequest) NICSelectResponse {
 		}
 	}
 
+	if req.NicSet.Policy != nil {
+		if err := validatePolicyOverride(*req.NicSet.Policy); err != nil {
+			// no NIC is selected to fail the pod instead of silently applying the network policy
+			networkStrategy := policy.Strategy
+			if networkStrategy == "" {
+				networkStrategy = string(None)
+			}
+			decision := newSelectionDecision(networkStrategy, req, filteredMasterNameMap)
+			decision.Override = describePolicy(*req.NicSet.Policy)
+			decision.Failure = fmt.Sprintf("invalid policy override: %v", err)
+			return NICSelectResponse{
+				DeviceIDs: []string{},
+				Masters:   []string{},
+				Decision:  decision,
+			}
+		}
+		policy = applyPolicyOverride(policy, *req.NicSet.Policy)
+		log.Printf("policy of %s/%s overridden by annotation: %s", req.PodNamespace, req.PodName, describePolicy(policy))
+	}
+
 	var selector Selector
 	strategy := Strategy(policy.Strategy)
 	switch strategy {
 	case None:
 		selector = DefaultSelector{}
 	case CostOpt:
-		selector = CostOptSelector{}
+		selector = CostOptSelector{Pack: policy.Placement == PackPlacement}
 	case PerfOpt:
 		selector = PerfOptSelector{}
 	case DevClass:
 		selector = DevClassSelector{}
 	case Topology:
-		selector = NumaAwareSelectorInstance.GetCopy()
+		selector = NumaAwareSelectorInstance.GetCopy().WithPodResources(podResources)
 	default:
 		selector = DefaultSelector{}
 	}
-	selectedMasterNetAddrs := selector.Select(req, filteredMasterNameMap, nameNetMap, resourceMap)
-	selectedMasters := []string{}
-	log.Printf("masterNets %v, %v, %v\n", selectedMasterNetAddrs, filteredMasterNameMap, nameNetMap)
-	for _, netAddress := range selectedMasterNetAddrs {
-		if master, ok := filteredMasterNameMap[netAddress]; ok && master != "" {
-			log.Printf("select device %s\n", master)
-			if iface.DeviceExists(master) {
-				selectedMasters = append(selectedMasters, master)
-			} else {
-				log.Printf("device %s not exists, skip", master)
+	if strategy == "" {
+		strategy = None
+	}
+	if len(policy.Pipeline) > 0 {
+		// strategy is a shorthand of single-stage pipeline
+		pipelineSelector := NewPipelineSelector(policy, NumaAwareSelectorInstance.GetCopy().WithPodResources(podResources))
+		selector = pipelineSelector
+		strategy = Strategy("pipeline " + pipelineSelector.String())
+	}
+	if netSpec.RailOptimized && len(resourceMap[GPUResourceName]) > 0 && len(req.NicSet.InterfaceNames) == 0 {
+		// pods with GPUs in rail-optimized network are attached to the rails paired with the GPUs,
+		// selected by the strategy or pipeline of the policy if set
+		var railPolicySelector Selector
+		railStrategy := Strategy(Rail)
+		if strategy != None {
+			railPolicySelector = selector
+			railStrategy = Strategy(fmt.Sprintf("%s>%s", Rail, strategy))
+		}
+		selector = NewRailSelector(netSpec.MasterNetAddrs, NumaAwareSelectorInstance.GetCopy().WithPodResources(podResources), railPolicySelector)
+		strategy = railStrategy
+	}
+	decision := newSelectionDecision(string(strategy), req, filteredMasterNameMap)
+	if req.NicSet.Policy != nil {
+		decision.Override = describePolicy(*req.NicSet.Policy)
+	}
+	// selector may filter out candidates from the given map
+	remainingNameMap := make(map[string]string)
+	for netAddress, master := range filteredMasterNameMap {
+		remainingNameMap[netAddress] = master
+	}
+	// NICs with link down or flapping are excluded from every strategy
+	filterReasons := excludeUnhealthyLinks(remainingNameMap, time.Now())
+	if policy.OnUnhealthy == FailOnUnhealthy {
+		if failure := getUnhealthyFailure(req, filteredMasterNameMap, nameNetMap, filterReasons); failure != "" {
+			decision.Failure = failure
+			for _, netAddress := range getSortedNetAddresses(filterReasons) {
+				decision.reject(netAddress, filteredMasterNameMap[netAddress], filterReasons[netAddress])
+			}
+			return NICSelectResponse{
+				DeviceIDs: []string{},
+				Masters:   []string{},
+				Decision:  decision,
 			}
 		}
 	}
+	selectedMasterNetAddrs := selector.Select(req, remainingNameMap, nameNetMap, resourceMap)
+	log.Printf("masterNets %v, %v, %v\n", selectedMasterNetAddrs, filteredMasterNameMap, nameNetMap)
+	if reasoner, ok := selector.(filterReasoner); ok {
+		for netAddress, reason := range reasoner.FilterReasons() {
+			filterReasons[netAddress] = reason
+		}
+	}
+	decision.rejectUnselected(req, filteredMasterNameMap, remainingNameMap, filterReasons, selectedMasterNetAddrs)
+	selectedMasters := getSelectedMasters(selectedMasterNetAddrs, filteredMasterNameMap, decision)
 
 	return NICSelectResponse{
 		DeviceIDs: []string{},
 		Masters:   selectedMasters,
+		Decision:  decision,
 	}
 }
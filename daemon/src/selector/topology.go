@@ -12,6 +12,8 @@ import (
 	"math"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 
 	gonvml "github.com/NVIDIA/gpu-monitoring-tools/bindings/go/nvml"
@@ -52,6 +54,11 @@ type NumaAwareSelector struct {
 	NcclTopolgy
 	gpuIDBusMap map[string]string
 	NumaMap     map[string]string
+	// Tree is PCIe hierarchy from the topology file or from sysfs
+	Tree       *PciTree
+	cpuNumaMap map[int64]string
+	// podResources is devices and CPUs of the pod to select for, set by WithPodResources
+	podResources iface.PodResources
 }
 
 func InitNumaAwareSelector(topologyFilePath string, gpuIdBusIdMap map[string]string) *NumaAwareSelector {
@@ -66,11 +73,25 @@ func InitNumaAwareSelector(topologyFilePath string, gpuIdBusIdMap map[string]str
 		}
 	}
 	numaMap = getNumaMap(topology)
-	log.Printf("InitNumaAwareSelector with %d numa nodes\n", len(numaMap))
+	var tree *PciTree
+	if len(topology.CPUs) > 0 {
+		tree = BuildPciTreeFromTopology(topology)
+	} else {
+		tree = BuildPciTreeFromSysfs(pciDevicesDir)
+	}
+	// GPUs not found by NVML are looked up from the driver
+	gpuIDBusMap := getNvidiaGPUBusMap(nvidiaGPUInfoDir)
+	for gpuId, busId := range gpuIdBusIdMap {
+		gpuIDBusMap[gpuId] = busId
+	}
+	cpuNumaMap := getCPUNumaMap(cpuNodeDir)
+	log.Printf("InitNumaAwareSelector with %d numa nodes, %d PCI devices, %d GPUs, %d CPUs\n", len(numaMap), len(tree.Devices), len(gpuIDBusMap), len(cpuNumaMap))
 	return &NumaAwareSelector{
 		NcclTopolgy: topology,
-		gpuIDBusMap: gpuIdBusIdMap,
+		gpuIDBusMap: gpuIDBusMap,
 		NumaMap:     numaMap,
+		Tree:        tree,
+		cpuNumaMap:  cpuNumaMap,
 	}
 }
 
@@ -173,42 +194,101 @@ func (s *NumaAwareSelector) Select(req NICSelectRequest, interfaceNameMap map[st
 	return selectedMaster[0:maxSize]
 }
 
-func (s *NumaAwareSelector) SortByNumaAware(selectedMaster []string, interfaceNameMap map[string]string, resourceMap map[string][]string) []string {
-	if gpuIds, ok := resourceMap[GPUResourceName]; !ok || len(s.NumaMap) == 0 {
-		// cannot sort value
-		log.Printf("cannot sort by numa node: resourceMap=%v, NumaMap length=%d", ok, len(s.NumaMap))
-		return selectedMaster
+// getGPUBusID returns bus ID of the allocated GPU, device ID can be GPU UUID or bus ID
+func (s *NumaAwareSelector) getGPUBusID(gpuId string) string {
+	if busId, ok := s.gpuIDBusMap[gpuId]; ok {
+		return normalizeBusID(busId)
+	}
+	if _, ok := s.Tree.Devices[normalizeBusID(gpuId)]; ok {
+		return normalizeBusID(gpuId)
+	}
+	return ""
+}
+
+// getNumaNode returns NUMA node of the PCI device, empty if unknown
+func (s *NumaAwareSelector) getNumaNode(busId string) string {
+	numaNode := s.Tree.Devices[busId].NumaNode
+	if numaNode == "" || numaNode == "-1" {
+		numaNode = s.NumaMap[busId]
+	}
+	if numaNode == "-1" {
+		return ""
+	}
+	return numaNode
+}
+
+// getGPUAffinity returns affinity level of the NIC to the GPU by PCIe tree,
+// or by NUMA nodes of the GPU reported by kubelet if the GPU is not found in the tree
+func (s *NumaAwareSelector) getGPUAffinity(gpuId, nicBusId, nicNumaNode string) int {
+	affinity := noAffinity
+	if gpuBusId := s.getGPUBusID(gpuId); gpuBusId != "" {
+		affinity = s.Tree.Affinity(nicBusId, gpuBusId)
 	} else {
-		numaPriority := make(map[string]int)
-		for _, gpuId := range gpuIds {
-			// map gpuId to pciAddress
-			pciAddress := s.gpuIDBusMap[gpuId]
-			numaId := s.NumaMap[pciAddress]
-			if priority, ok := numaPriority[numaId]; ok {
-				numaPriority[numaId] = priority + 1
-			} else {
-				numaPriority[numaId] = 1
+		log.Printf("cannot find bus ID of GPU %s", gpuId)
+	}
+	if affinity < numaAffinity && nicNumaNode != "" {
+		for _, numaNode := range s.podResources.GetDeviceNumaNodes()[gpuId] {
+			if strconv.FormatInt(numaNode, 10) == nicNumaNode {
+				return numaAffinity
 			}
 		}
-		nicPriority := make(map[string]int)
-		interfaceMap := iface.GetInterfaceInfoCache()
-		for _, masterNetAddr := range selectedMaster {
-			if devName, ok := interfaceNameMap[masterNetAddr]; ok {
-				if info, ok := interfaceMap[devName]; ok {
-					log.Printf("info: %v", info)
-					nicPciAddress := info.PciAddress
-					if numaId, ok := s.NumaMap[nicPciAddress]; ok {
-						if priority, ok := numaPriority[numaId]; ok {
-							nicPriority[masterNetAddr] = priority
-						} else {
-							nicPriority[masterNetAddr] = 0
-						}
-					}
-				}
-			}
+	}
+	return affinity
+}
+
+// WithPodResources sets devices and CPUs assigned to the pod by kubelet
+func (s *NumaAwareSelector) WithPodResources(podResources iface.PodResources) *NumaAwareSelector {
+	s.podResources = podResources
+	return s
+}
+
+// getAffinityCounts returns for each NIC the number of allocated GPUs under the same PCIe switch,
+// under the same host bridge, on the same NUMA node, and the number of exclusive CPUs of the pod on the same NUMA node
+func (s *NumaAwareSelector) getAffinityCounts(selectedMaster []string, interfaceNameMap map[string]string, resourceMap map[string][]string) map[string][]int {
+	gpuIds := resourceMap[GPUResourceName]
+	cpuCounts := make(map[string]int)
+	for _, cpuId := range s.podResources.GetCPUIds() {
+		if numaNode, ok := s.cpuNumaMap[cpuId]; ok {
+			cpuCounts[numaNode] += 1
 		}
-		return getSortedKeyByMap(nicPriority)
 	}
+	if len(gpuIds) == 0 && len(cpuCounts) == 0 {
+		log.Printf("cannot sort by topology: no GPU or exclusive CPU, resourceMap=%v", resourceMap)
+	}
+	affinityCounts := make(map[string][]int)
+	for _, masterNetAddr := range selectedMaster {
+		levelCounts := make([]int, switchAffinity+1)
+		nicBusId := getNicBusID(interfaceNameMap[masterNetAddr])
+		nicNumaNode := s.getNumaNode(nicBusId)
+		for _, gpuId := range gpuIds {
+			levelCounts[s.getGPUAffinity(gpuId, nicBusId, nicNumaNode)] += 1
+		}
+		counts := []int{levelCounts[switchAffinity], levelCounts[hostBridgeAffinity], levelCounts[numaAffinity], 0}
+		if nicNumaNode != "" {
+			counts[3] = cpuCounts[nicNumaNode]
+		}
+		log.Printf("%s (%s) affinity to GPUs and CPUs: %v", masterNetAddr, nicBusId, counts)
+		affinityCounts[masterNetAddr] = counts
+	}
+	return affinityCounts
+}
+
+// SortByNumaAware sorts NICs by the number of allocated GPUs under the same PCIe switch,
+// then under the same host bridge, then on the same NUMA node, and then by the number of
+// exclusive CPUs of the pod on the same NUMA node; ties are sorted by network address
+func (s *NumaAwareSelector) SortByNumaAware(selectedMaster []string, interfaceNameMap map[string]string, resourceMap map[string][]string) []string {
+	affinityCounts := s.getAffinityCounts(selectedMaster, interfaceNameMap, resourceMap)
+	sortedMaster := append([]string{}, selectedMaster...)
+	sort.SliceStable(sortedMaster, func(i, j int) bool {
+		countsI, countsJ := affinityCounts[sortedMaster[i]], affinityCounts[sortedMaster[j]]
+		for level := range countsI {
+			if countsI[level] != countsJ[level] {
+				return countsI[level] > countsJ[level]
+			}
+		}
+		return sortedMaster[i] < sortedMaster[j]
+	})
+	return sortedMaster
 }
 
 func (s *NumaAwareSelector) GetCopy() *NumaAwareSelector {
@@ -216,5 +296,7 @@ func (s *NumaAwareSelector) GetCopy() *NumaAwareSelector {
 		NcclTopolgy: s.NcclTopolgy,
 		gpuIDBusMap: s.gpuIDBusMap,
 		NumaMap:     s.NumaMap,
+		Tree:        s.Tree,
+		cpuNumaMap:  s.cpuNumaMap,
 	}
 }
@@ -0,0 +1,168 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package selector
+
+import (
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/foundation-model-stack/multi-nic-cni/daemon/iface"
+)
+
+const exampleTopology = "../../example/example-topology.xml"
+
+var _ = Describe("Test Topology Selector", func() {
+	interfaceNameMap := map[string]string{
+		"10.0.1.0/24": "eth1",
+		"10.0.2.0/24": "eth2",
+		"10.0.3.0/24": "eth3",
+		"10.0.4.0/24": "eth4",
+	}
+
+	var (
+		originalSysClassNet      string
+		originalPciDevicesDir    string
+		originalNvidiaGPUInfoDir string
+		originalCPUNodeDir       string
+	)
+
+	// addDevice creates device folder under /sys/devices linked from /sys/bus/pci/devices and
+	// from /sys/class/net/<devName>/device if devName is set
+	addDevice := func(sysfsRoot, path, class, numaNode, devName string) {
+		devicePath := filepath.Join(sysfsRoot, "devices", path)
+		writeSysfsFile(devicePath, "class", class)
+		writeSysfsFile(devicePath, "numa_node", numaNode)
+		Expect(os.Symlink(devicePath, filepath.Join(pciDevicesDir, filepath.Base(path)))).To(Succeed())
+		if devName != "" {
+			Expect(os.MkdirAll(filepath.Join(iface.SysClassNet, devName), 0755)).To(Succeed())
+			Expect(os.Symlink(devicePath, filepath.Join(iface.SysClassNet, devName, "device"))).To(Succeed())
+		}
+	}
+
+	BeforeEach(func() {
+		originalSysClassNet = iface.SysClassNet
+		originalPciDevicesDir = pciDevicesDir
+		originalNvidiaGPUInfoDir = nvidiaGPUInfoDir
+		originalCPUNodeDir = cpuNodeDir
+		sysfsRoot := GinkgoT().TempDir()
+		iface.SysClassNet = filepath.Join(sysfsRoot, "class", "net")
+		pciDevicesDir = filepath.Join(sysfsRoot, "bus", "pci", "devices")
+		nvidiaGPUInfoDir = filepath.Join(GinkgoT().TempDir(), "gpus")
+		cpuNodeDir = filepath.Join(sysfsRoot, "devices", "system", "node")
+		Expect(os.MkdirAll(pciDevicesDir, 0755)).To(Succeed())
+
+		// GPU and eth1 under the same PCIe switch
+		addDevice(sysfsRoot, "pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:00.0/0000:03:00.0", "0x030200", "0", "")
+		addDevice(sysfsRoot, "pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:01.0/0000:04:00.0", "0x020000", "0", "eth1")
+		// eth2 on another root port of the same host bridge
+		addDevice(sysfsRoot, "pci0000:00/0000:00:02.0/0000:05:00.0", "0x020000", "0", "eth2")
+		// GPU on NUMA 1 with eth3 under another host bridge
+		addDevice(sysfsRoot, "pci0000:40/0000:40:01.0/0000:41:00.0", "0x030200", "1", "")
+		addDevice(sysfsRoot, "pci0000:80/0000:80:01.0/0000:81:00.0", "0x020000", "1", "eth3")
+		// eth4 without NUMA information
+		addDevice(sysfsRoot, "pci0000:c0/0000:c0:01.0/0000:c1:00.0", "0x020000", "-1", "eth4")
+		writeSysfsFile(filepath.Join(cpuNodeDir, "node0"), "cpulist", "0-3,8-11")
+		writeSysfsFile(filepath.Join(cpuNodeDir, "node1"), "cpulist", "4-7,12-15")
+
+		writeSysfsFile(filepath.Join(nvidiaGPUInfoDir, "0000:03:00.0"), "information",
+			"Model: \t\t NVIDIA A100-SXM4-80GB\nGPU UUID: \t GPU-581b17ed-1c48-9b8c-6a9b-e2e6f99500dc\nBus Location: \t 0000:03:00.0")
+	})
+
+	AfterEach(func() {
+		iface.SysClassNet = originalSysClassNet
+		pciDevicesDir = originalPciDevicesDir
+		nvidiaGPUInfoDir = originalNvidiaGPUInfoDir
+		cpuNodeDir = originalCPUNodeDir
+	})
+
+	It("builds PCI tree from sysfs", func() {
+		tree := BuildPciTreeFromSysfs(pciDevicesDir)
+		Expect(tree.Devices).To(HaveLen(6))
+		Expect(tree.Devices["0000:04:00.0"]).To(Equal(PciDevice{
+			BusID:      "0000:04:00.0",
+			Class:      "0x020000",
+			NumaNode:   "0",
+			Bridges:    []string{"0000:00:01.0", "0000:01:00.0", "0000:02:01.0"},
+			HostBridge: "pci0000:00",
+		}))
+	})
+
+	DescribeTable("affinity", func(busID1, busID2 string, expected int) {
+		tree := BuildPciTreeFromSysfs(pciDevicesDir)
+		Expect(tree.Affinity(busID1, busID2)).To(Equal(expected))
+		Expect(tree.Affinity(busID2, busID1)).To(Equal(expected))
+	},
+		Entry("same switch", "0000:03:00.0", "0000:04:00.0", switchAffinity),
+		Entry("NVML bus ID", "00000000:03:00.0", "0000:04:00.0", switchAffinity),
+		Entry("same host bridge", "0000:03:00.0", "0000:05:00.0", hostBridgeAffinity),
+		Entry("same NUMA", "0000:41:00.0", "0000:81:00.0", numaAffinity),
+		Entry("different NUMA", "0000:03:00.0", "0000:81:00.0", noAffinity),
+		Entry("unknown NUMA", "0000:c1:00.0", "0000:c1:00.0", switchAffinity),
+		Entry("unknown NUMA of different devices", "0000:41:00.0", "0000:c1:00.0", noAffinity),
+		Entry("unknown device", "0000:ff:00.0", "0000:04:00.0", noAffinity),
+	)
+
+	It("builds PCI tree from topology file", func() {
+		selector := InitNumaAwareSelector(exampleTopology, map[string]string{})
+		Expect(selector.Tree.Affinity("0000:0c:05.0", "0000:0c:00.0")).To(Equal(switchAffinity))
+		Expect(selector.Tree.Affinity("0000:08:00.0", "0000:0a:03.0")).To(Equal(numaAffinity))
+		Expect(selector.Tree.Affinity("0000:08:00.0", "0000:0c:05.0")).To(Equal(noAffinity))
+	})
+
+	It("maps GPU UUID to bus ID from driver information", func() {
+		Expect(getNvidiaGPUBusMap(nvidiaGPUInfoDir)).To(Equal(map[string]string{
+			"GPU-581b17ed-1c48-9b8c-6a9b-e2e6f99500dc": "0000:03:00.0",
+		}))
+	})
+
+	DescribeTable("selects NICs close to allocated GPUs", func(gpuIds []string, nics int, expected []string) {
+		selector := InitNumaAwareSelector("", map[string]string{})
+		req := NICSelectRequest{NicSet: NicArgs{NumOfInterfaces: nics}}
+		resourceMap := map[string][]string{GPUResourceName: gpuIds}
+		Expect(selector.Select(req, interfaceNameMap, nil, resourceMap)).To(Equal(expected))
+	},
+		Entry("GPU UUID under switch", []string{"GPU-581b17ed-1c48-9b8c-6a9b-e2e6f99500dc"}, 2, []string{"10.0.1.0/24", "10.0.2.0/24"}),
+		Entry("GPU bus ID on NUMA", []string{"0000:41:00.0"}, 1, []string{"10.0.3.0/24"}),
+		Entry("both GPUs", []string{"GPU-581b17ed-1c48-9b8c-6a9b-e2e6f99500dc", "0000:41:00.0"}, 3,
+			[]string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}),
+		Entry("unknown GPU", []string{"GPU-unknown"}, 3, []string{"10.0.1.0/24", "10.0.2.0/24", "10.0.3.0/24"}),
+	)
+
+	DescribeTable("selects NICs by pod resources from kubelet", func(devices []iface.ContainerDevices, cpuIds []int64, nics int, expected []string) {
+		podResources := iface.PodResources{
+			Name:       "pod",
+			Namespace:  "default",
+			Containers: []iface.ContainerResources{{Name: "main", Devices: devices, CPUIds: cpuIds}},
+		}
+		selector := InitNumaAwareSelector("", map[string]string{}).WithPodResources(podResources)
+		req := NICSelectRequest{NicSet: NicArgs{NumOfInterfaces: nics}}
+		Expect(selector.Select(req, interfaceNameMap, nil, podResources.GetResourceMap())).To(Equal(expected))
+	},
+		Entry("NUMA of unknown GPU", []iface.ContainerDevices{
+			{ResourceName: GPUResourceName, DeviceIds: []string{"GPU-unknown"}, NumaNodes: []int64{1}},
+		}, []int64{}, 1, []string{"10.0.3.0/24"}),
+		Entry("exclusive CPUs", []iface.ContainerDevices{}, []int64{4, 5, 6, 0}, 2, []string{"10.0.3.0/24", "10.0.1.0/24"}),
+		Entry("CPUs break tie of GPU affinity", []iface.ContainerDevices{
+			{ResourceName: GPUResourceName, DeviceIds: []string{"GPU-unknown"}, NumaNodes: []int64{0, 1}},
+		}, []int64{4, 5}, 2, []string{"10.0.3.0/24", "10.0.1.0/24"}),
+		Entry("GPU affinity before CPUs", []iface.ContainerDevices{
+			{ResourceName: GPUResourceName, DeviceIds: []string{"GPU-581b17ed-1c48-9b8c-6a9b-e2e6f99500dc"}, NumaNodes: []int64{0}},
+		}, []int64{4, 5, 6, 7}, 2, []string{"10.0.1.0/24", "10.0.2.0/24"}),
+	)
+
+	It("parses CPU list", func() {
+		Expect(parseCPUList("0-2,8,10-11\n")).To(Equal([]int64{0, 1, 2, 8, 10, 11}))
+		Expect(getCPUNumaMap(cpuNodeDir)).To(HaveKeyWithValue(int64(12), "1"))
+	})
+
+	It("normalizes NVML bus ID", func() {
+		Expect(normalizeBusID("00000000:0C:05.0")).To(Equal("0000:0c:05.0"))
+		Expect(normalizeBusID("0000:0c:05.0")).To(Equal("0000:0c:05.0"))
+	})
+})
@@ -123,7 +123,7 @@ func addMasterInterfaces() {
 			Name: master,
 		}
 		err := netlink.LinkAdd(&netlink.Dummy{
-			linkAttrs,
+			LinkAttrs: linkAttrs,
 		})
 		Expect(err).NotTo(HaveOccurred())
 		masterLink, err := netlink.LinkByName(master)
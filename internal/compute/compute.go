@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"net"
 	"strconv"
 	"strings"
@@ -16,9 +17,9 @@ import (
 
 type CIDRCompute struct{}
 
-func (c CIDRCompute) appendMask(baseMask []byte, block int) [4]byte {
+func (c CIDRCompute) appendMask(baseMask []byte, block int) []byte {
 	remain := block
-	var output [4]byte
+	output := make([]byte, len(baseMask))
 	for index, value := range baseMask {
 		if value&0xff == 255 || remain == 0 {
 			output[index] = value
@@ -40,11 +41,20 @@ func (c CIDRCompute) appendMask(baseMask []byte, block int) [4]byte {
 
 // addAddress adds the addValue to the baseAddress with the mask.
 // It returns the new IP address and an error if the addValue is invalid.
-func (c CIDRCompute) addAddress(baseAddress []byte, mask []byte, block int, addValue int) ([4]byte, error) {
+// The baseAddress and mask must have the same length (4 bytes for IPv4, 16 bytes for IPv6).
+func (c CIDRCompute) addAddress(baseAddress []byte, mask []byte, block int, addValue int) (net.IP, error) {
 	// check if valid sum value
-	maxValue := math.Pow(2, float64(block)) - 1
-	if addValue > int(maxValue) {
-		return [4]byte{}, fmt.Errorf("InvalidRequest: %.0f > %d", maxValue, addValue)
+	if addValue < 0 {
+		return nil, fmt.Errorf("InvalidRequest: negative value %d", addValue)
+	}
+	if block < strconv.IntSize-1 {
+		maxValue := (1 << block) - 1
+		if addValue > maxValue {
+			return nil, fmt.Errorf("InvalidRequest: %d > %d", maxValue, addValue)
+		}
+	}
+	if len(baseAddress) != len(mask) {
+		return nil, fmt.Errorf("InvalidRequest: address length %d != mask length %d", len(baseAddress), len(mask))
 	}
 
 	// get value in binary length equals to block
@@ -53,7 +63,7 @@ func (c CIDRCompute) addAddress(baseAddress []byte, mask []byte, block int, addV
 		valueInBinary = "0" + valueInBinary
 	}
 
-	var output [4]byte
+	output := make(net.IP, len(mask))
 	for index, value := range mask {
 		if value&0xff == 255 || len(valueInBinary) == 0 {
 			output[index] = baseAddress[index]
@@ -78,17 +88,16 @@ func (c CIDRCompute) addAddress(baseAddress []byte, mask []byte, block int, addV
 			target = target + "0"
 		}
 		if intValue, err := strconv.ParseInt(target, 2, 64); err != nil {
-			return [4]byte{}, err
+			return nil, err
 		} else {
 			output[index] = baseAddress[index] + byte(intValue)
 		}
 	}
 
 	// confirm mask not change
-	newIP := net.IPv4(output[0], output[1], output[2], output[3])
-	baseIP := net.IPv4(baseAddress[0], baseAddress[1], baseAddress[2], baseAddress[3])
-	if newIP.Mask(mask).String() != baseIP.Mask(mask).String() {
-		return [4]byte{}, errors.New("InvalidRequest: out of mask")
+	baseIP := net.IP(baseAddress)
+	if !output.Mask(mask).Equal(baseIP.Mask(mask)) {
+		return nil, errors.New("InvalidRequest: out of mask")
 	}
 
 	return output, nil
@@ -103,9 +112,8 @@ func (c CIDRCompute) CheckIfTabuIndex(baseCIDR string, index int, blocksize int,
 			excludeBlock, _ := strconv.ParseInt(excludeIPSplits[1], 10, 64)
 			if excludeBlock <= baseBlock+int64(blocksize) {
 				_, excludeNet, _ := net.ParseCIDR(exclude)
-				netInByte, _ := c.ComputeNet(baseCIDR, index, blocksize)
-				netIP, _, _ := net.ParseCIDR(bytesToStr(netInByte) + "/32")
-				if excludeNet.Contains(netIP) {
+				netIP, err := c.ComputeNet(baseCIDR, index, blocksize)
+				if err == nil && excludeNet != nil && excludeNet.Contains(netIP) {
 					return true
 				}
 			}
@@ -140,55 +148,51 @@ func (c CIDRCompute) FindAvailableIndex(indexes []int, leftIndex int, startIndex
 }
 
 // ComputeNet computes the network address for a given CIDR and index.
-// It takes the base CIDR, index, and blocksize as input parameters.
-// It returns the network address as a [4]byte array and an error if any.
-func (c CIDRCompute) ComputeNet(baseCIDR string, index int, blocksize int) ([4]byte, error) {
+// It takes the base CIDR (IPv4 or IPv6), index, and blocksize as input parameters.
+// It returns the network address in the family of the base CIDR and an error if any.
+func (c CIDRCompute) ComputeNet(baseCIDR string, index int, blocksize int) (net.IP, error) {
 	startIPStr := strings.Split(baseCIDR, "/")[0]
 	startIP := net.ParseIP(startIPStr)
 	_, subnetNet, err := net.ParseCIDR(baseCIDR)
 	if err != nil {
-		return [4]byte{}, err
+		return nil, err
 	}
 	mask := subnetNet.Mask
-	interfaceMask := c.appendMask(mask, blocksize)
-	interfaceIPMask := net.IPv4Mask(interfaceMask[0], interfaceMask[1], interfaceMask[2], interfaceMask[3])
-	baseIP := startIP.Mask(interfaceIPMask).To4()
+	ones, bits := mask.Size()
+	if ones+blocksize > bits {
+		return nil, fmt.Errorf("InvalidRequest: /%d + %d bits exceeds %d-bit address", ones, blocksize, bits)
+	}
+	interfaceIPMask := net.IPMask(c.appendMask(mask, blocksize))
+	baseIP := startIP.Mask(interfaceIPMask)
+	if baseIP == nil {
+		return nil, fmt.Errorf("InvalidRequest: cannot mask %s", baseCIDR)
+	}
 
 	cidrInByte, err := c.addAddress(baseIP, mask, blocksize, index)
 	if err != nil {
-		return [4]byte{}, err
+		return nil, err
 	}
 	return cidrInByte, nil
 }
 
-// GetCIDRFromByte returns a CIDR string from a byte array, subnet, and block size.
-func (c CIDRCompute) GetCIDRFromByte(cidrInByte [4]byte, subnet string, blocksize int) string {
+// GetCIDRFromByte returns a CIDR string from a network address, subnet, and block size.
+func (c CIDRCompute) GetCIDRFromByte(cidrInByte net.IP, subnet string, blocksize int) string {
 	baseBlock, _ := strconv.ParseInt(strings.Split(subnet, "/")[1], 10, 64)
 	blockSize := int(baseBlock) + blocksize
-	return fmt.Sprintf("%s/%d", bytesToStr(cidrInByte), blockSize)
+	return fmt.Sprintf("%s/%d", cidrInByte.String(), blockSize)
 }
 
 // GetIndexInRange returns a boolean indicating if the pod IP address is within the pod CIDR range,
 // and the index of the pod IP address within the range.
 func (c CIDRCompute) GetIndexInRange(podCIDR string, podIPAddress string) (bool, int) {
-	startPodIP, podNet, _ := net.ParseCIDR(podCIDR)
-	netIP, _, _ := net.ParseCIDR(podIPAddress + "/32")
-	if !podNet.Contains(netIP) {
+	startPodIP, podNet, err := net.ParseCIDR(podCIDR)
+	podIP := net.ParseIP(podIPAddress)
+	if err != nil || podIP == nil || !podNet.Contains(podIP) {
 		return false, -1
 	}
-	cidrValues := strings.Split(startPodIP.String(), ".")
-	podValues := strings.Split(podIPAddress, ".")
-
-	podIndex := int(0)
-	for index, value := range podValues {
-		if value == cidrValues[index] {
-			continue
-		}
-		cidrValue, _ := strconv.Atoi(cidrValues[index])
-		podValue, _ := strconv.Atoi(podValues[index])
-		diff := podValue - cidrValue
-		diff *= int(math.Pow(256, float64(3-index)))
-		podIndex += int(diff)
+	diff := new(big.Int).Sub(ipToInt(podIP), ipToInt(startPodIP))
+	if !diff.IsInt64() || diff.Int64() > math.MaxInt {
+		return false, -1
 	}
-	return true, podIndex
+	return true, int(diff.Int64())
 }
@@ -20,6 +20,12 @@ var _ = Describe("Test CIDRCompute", func() {
 		Entry("simple", "192.168.0.0/16", 0, 2, "192.168.0.0/18", false),
 		Entry("invalid CIDR", "192.168.0.0", 0, 2, "", true),
 		Entry("invalid Index", "192.168.0.0/16", 4, 2, "", true),
+		Entry("ipv6 simple", "fd00::/48", 0, 8, "fd00::/56", false),
+		Entry("ipv6 index", "fd00::/48", 3, 8, "fd00:0:0:300::/56", false),
+		Entry("ipv6 unaligned block", "fd00:1::/44", 5, 4, "fd00:1:5::/48", false),
+		Entry("ipv6 host block", "fd00:0:0:100::/56", 2, 8, "fd00:0:0:102::/64", false),
+		Entry("ipv6 invalid Index", "fd00::/48", 256, 8, "", true),
+		Entry("ipv6 block overflow", "fd00::/120", 0, 16, "", true),
 	)
 
 	DescribeTable("CheckIfTabuIndex", func(baseCIDR string, index int, blocksize int, excludes []string, expected bool) {
@@ -30,6 +36,9 @@ var _ = Describe("Test CIDRCompute", func() {
 		Entry("tabu index", "192.168.0.0/16", 0, 8, []string{"192.168.0.0/24"}, true),
 		Entry("cover tabu index", "192.168.0.0/16", 0, 8, []string{"192.168.0.0/8"}, true),
 		Entry("not tabu index", "192.168.0.0/16", 0, 8, []string{"192.168.1.0/24"}, false),
+		Entry("ipv6 tabu index", "fd00::/48", 1, 8, []string{"fd00:0:0:100::/56"}, true),
+		Entry("ipv6 not tabu index", "fd00::/48", 0, 8, []string{"fd00:0:0:100::/56"}, false),
+		Entry("other family exclude", "fd00::/48", 0, 8, []string{"0.0.0.0/0"}, false),
 	)
 
 	DescribeTable("FindAvailableIndex", func(indexes []int, leftIndex, startIndex, expected int) {
@@ -54,5 +63,8 @@ var _ = Describe("Test CIDRCompute", func() {
 		Entry("invalid CIDR and IP", "192.168.1.0/24", "192.168.2.100", false, -1),
 		Entry("same CIDR and IP", "192.168.1.0/24", "192.168.1.0", true, 0),
 		Entry("different CIDR and IP", "10.0.0.0/8", "192.168.1.100", false, -1),
+		Entry("ipv6 CIDR and IP", "fd00::/96", "fd00::1:2", true, 65536+2),
+		Entry("ipv6 uncontained IP", "fd00::/112", "fd00::1:0:2", false, -1),
+		Entry("mixed families", "fd00::/112", "192.168.1.100", false, -1),
 	)
 })
@@ -6,9 +6,9 @@
 package compute
 
 import (
-	"fmt"
+	"math/big"
+	"net"
 	"sort"
-	"strconv"
 	"strings"
 )
 
@@ -16,13 +16,18 @@ const (
 	SHIFT_BYTE_VAL     = 256
 	MAX_VALUE_PER_BYTE = 255
 	BYTE_SIZE          = 8
+	// MAX_IPV6_INDEX_BITS limits assignable host bits of IPv6 pod CIDR (same as daemon allocator)
+	MAX_IPV6_INDEX_BITS = 16
+	// MAX_BLOCK_BITS limits computed block capacity to fit in int32
+	MAX_BLOCK_BITS     = 31
+	MAX_BLOCK_CAPACITY = 1<<MAX_BLOCK_BITS - 1
 )
 
 var MASKCHECK = []byte{0, 128, 192, 224, 240, 248, 252, 254, 255}
 
 type IPValue struct {
 	Address string
-	Value   int64
+	Value   *big.Int
 }
 
 func maskIndex(b byte) int {
@@ -34,28 +39,95 @@ func maskIndex(b byte) int {
 	return -1
 }
 
-func addrToValue(address string) int64 {
-	splits := strings.Split(address, ".")
-	var sumValue int64
-	sumValue = 0
-	for _, split := range splits {
-		val, _ := strconv.ParseInt(split, 10, 64)
-		sumValue = sumValue*SHIFT_BYTE_VAL + val
+// ipToInt converts IP address to its integer value in the address family (32-bit for IPv4, 128-bit for IPv6)
+func ipToInt(ip net.IP) *big.Int {
+	if ip4 := ip.To4(); ip4 != nil {
+		return new(big.Int).SetBytes(ip4)
 	}
-	return sumValue
+	return new(big.Int).SetBytes(ip.To16())
 }
 
-func ValueToAddr(value int64) [4]byte {
-	var output [4]byte
-	for index := 3; index >= 0; index-- {
-		output[index] = byte(value % SHIFT_BYTE_VAL)
-		value = value / SHIFT_BYTE_VAL
+func addrToValue(address string) *big.Int {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return big.NewInt(0)
 	}
+	return ipToInt(ip)
+}
+
+// ValueToAddr converts integer value back to IP address of the given family
+func ValueToAddr(value *big.Int, isIPv6 bool) net.IP {
+	size := net.IPv4len
+	if isIPv6 {
+		size = net.IPv6len
+	}
+	output := make(net.IP, size)
+	value.FillBytes(output)
 	return output
 }
 
-func bytesToStr(ip [4]byte) string {
-	return fmt.Sprintf("%d.%d.%d.%d", ip[0], ip[1], ip[2], ip[3])
+// IsIPv6CIDR returns true if the given CIDR or address is IPv6
+func IsIPv6CIDR(cidr string) bool {
+	ip := net.ParseIP(strings.Split(cidr, "/")[0])
+	return ip != nil && ip.To4() == nil
+}
+
+// SplitSubnets splits a comma-separated subnet (e.g., a dual-stack pair "10.0.0.0/16,fd00::/64") into a list
+func SplitSubnets(subnet string) []string {
+	subnets := []string{}
+	for _, item := range strings.Split(subnet, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			subnets = append(subnets, item)
+		}
+	}
+	return subnets
+}
+
+// FilterByFamily returns items (addresses or CIDRs) that have the same family as the reference CIDR
+func FilterByFamily(items []string, reference string) []string {
+	isIPv6 := IsIPv6CIDR(reference)
+	filtered := []string{}
+	for _, item := range items {
+		if IsIPv6CIDR(item) == isIPv6 {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
+
+// HostCIDR returns a single-address CIDR (/32 for IPv4, /128 for IPv6) of the given address
+func HostCIDR(address string) string {
+	if IsIPv6CIDR(address) {
+		return address + "/128"
+	}
+	return address + "/32"
+}
+
+// BlockCapacity returns the number of indexes in a block of the given bits, capped at MAX_BLOCK_CAPACITY
+func BlockCapacity(block int) int {
+	if block < 0 {
+		return 0
+	}
+	if block >= MAX_BLOCK_BITS {
+		return MAX_BLOCK_CAPACITY
+	}
+	return 1 << block
+}
+
+// GetPodCIDRCapacity returns the number of assignable addresses in pod CIDR
+// consistent with the daemon allocator (network and broadcast addresses are not assigned in IPv4,
+// IPv6 pod CIDR is limited to MAX_IPV6_INDEX_BITS)
+func GetPodCIDRCapacity(podCIDR string) int {
+	_, ipNet, err := net.ParseCIDR(podCIDR)
+	if err != nil {
+		return 0
+	}
+	ones, bits := ipNet.Mask.Size()
+	if ipNet.IP.To4() == nil {
+		return BlockCapacity(min(bits-ones, MAX_IPV6_INDEX_BITS)) - 1
+	}
+	return max(BlockCapacity(bits-ones)-2, 0)
 }
 
 func getIPValue(address string) IPValue {
@@ -64,6 +136,7 @@ func getIPValue(address string) IPValue {
 }
 
 // SortAddress sorts a list of IP addresses and returns a list of IPValues.
+// IPv4 addresses are placed before IPv6 addresses.
 func SortAddress(addresses []string) []IPValue {
 	var ipValues []IPValue
 	for _, address := range addresses {
@@ -71,7 +144,12 @@ func SortAddress(addresses []string) []IPValue {
 		ipValues = append(ipValues, ipValue)
 	}
 	sort.SliceStable(ipValues, func(i, j int) bool {
-		return ipValues[i].Value < ipValues[j].Value
+		iIsIPv6 := IsIPv6CIDR(ipValues[i].Address)
+		jIsIPv6 := IsIPv6CIDR(ipValues[j].Address)
+		if iIsIPv6 != jIsIPv6 {
+			return !iIsIPv6
+		}
+		return ipValues[i].Value.Cmp(ipValues[j].Value) < 0
 	})
 	return ipValues
 }
@@ -1,6 +1,8 @@
 package compute_test
 
 import (
+	"math/big"
+
 	. "github.com/foundation-model-stack/multi-nic-cni/internal/compute"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -12,10 +14,65 @@ var _ = Describe("Test Compute Utils", func() {
 		Expect(ips).To(BeEquivalentTo(ips))
 	},
 		Entry("empty slice", []string{}, []IPValue{}),
-		Entry("single ip", []string{"0.0.1.0"}, []IPValue{{Address: "0.0.1.0", Value: 256}}),
+		Entry("single ip", []string{"0.0.1.0"}, []IPValue{{Address: "0.0.1.0", Value: big.NewInt(256)}}),
 		Entry("sorted ips", []string{"0.0.0.1", "0.0.0.2"},
-			[]IPValue{{Address: "0.0.0.2", Value: 2}, {Address: "0.0.0.1", Value: 1}}),
+			[]IPValue{{Address: "0.0.0.2", Value: big.NewInt(2)}, {Address: "0.0.0.1", Value: big.NewInt(1)}}),
 		Entry("unsorted ips", []string{"0.0.0.2", "0.0.0.1"},
-			[]IPValue{{Address: "0.0.0.2", Value: 2}, {Address: "0.0.0.1", Value: 1}}),
+			[]IPValue{{Address: "0.0.0.2", Value: big.NewInt(2)}, {Address: "0.0.0.1", Value: big.NewInt(1)}}),
+	)
+
+	DescribeTable("SortAddress order", func(addresses []string, expected []string) {
+		ips := SortAddress(addresses)
+		sorted := []string{}
+		for _, ip := range ips {
+			sorted = append(sorted, ip.Address)
+		}
+		Expect(sorted).To(Equal(expected))
+	},
+		Entry("ipv4 cidrs", []string{"10.0.1.0/24", "10.0.0.0/24"}, []string{"10.0.0.0/24", "10.0.1.0/24"}),
+		Entry("ipv6 cidrs", []string{"fd00::1:0/112", "fd00::/112"}, []string{"fd00::/112", "fd00::1:0/112"}),
+		Entry("mixed families", []string{"fd00::/112", "10.0.0.0/24"}, []string{"10.0.0.0/24", "fd00::/112"}),
+	)
+
+	DescribeTable("SplitSubnets", func(subnet string, expected []string) {
+		Expect(SplitSubnets(subnet)).To(Equal(expected))
+	},
+		Entry("empty", "", []string{}),
+		Entry("single ipv4", "192.168.0.0/16", []string{"192.168.0.0/16"}),
+		Entry("single ipv6", "fd00::/48", []string{"fd00::/48"}),
+		Entry("dual-stack", "192.168.0.0/16, fd00::/48", []string{"192.168.0.0/16", "fd00::/48"}),
+	)
+
+	DescribeTable("FilterByFamily", func(items []string, reference string, expected []string) {
+		Expect(FilterByFamily(items, reference)).To(Equal(expected))
+	},
+		Entry("ipv4 reference", []string{"192.168.0.0/24", "fd00::/64"}, "10.0.0.0/8", []string{"192.168.0.0/24"}),
+		Entry("ipv6 reference", []string{"192.168.0.0/24", "fd00::/64"}, "fd00::/48", []string{"fd00::/64"}),
+	)
+
+	DescribeTable("HostCIDR", func(address string, expected string) {
+		Expect(HostCIDR(address)).To(Equal(expected))
+	},
+		Entry("ipv4", "192.168.0.1", "192.168.0.1/32"),
+		Entry("ipv6", "fd00::1", "fd00::1/128"),
+	)
+
+	DescribeTable("GetPodCIDRCapacity", func(podCIDR string, expected int) {
+		Expect(GetPodCIDRCapacity(podCIDR)).To(Equal(expected))
+	},
+		Entry("ipv4 /24", "192.168.0.0/24", 254),
+		Entry("ipv4 /32", "192.168.0.1/32", 0),
+		Entry("ipv6 /120", "fd00::/120", 255),
+		Entry("ipv6 /64 capped", "fd00::/64", 65535),
+		Entry("invalid", "192.168.0.0", 0),
+	)
+
+	DescribeTable("BlockCapacity", func(block int, expected int) {
+		Expect(BlockCapacity(block)).To(Equal(expected))
+	},
+		Entry("zero block", 0, 1),
+		Entry("8-bit block", 8, 256),
+		Entry("negative block", -1, 0),
+		Entry("capped block", 64, MAX_BLOCK_CAPACITY),
 	)
 })
@@ -0,0 +1,120 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package multinicctl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	"github.com/foundation-model-stack/multi-nic-cni/internal/plugin"
+)
+
+// Inventory is a snapshot of multi-nic resources used to render the views
+type Inventory struct {
+	Networks       []multinicv1.MultiNicNetwork
+	CIDRs          []multinicv1.CIDR
+	IPPools        []multinicv1.IPPool
+	HostInterfaces []multinicv1.HostInterface
+	Pods           []corev1.Pod
+}
+
+// LoadInventory lists multi-nic resources, pods are listed only if withPods is set
+func LoadInventory(ctx context.Context, c client.Client, withPods bool) (*Inventory, error) {
+	inv := &Inventory{}
+	networkList := &multinicv1.MultiNicNetworkList{}
+	if err := c.List(ctx, networkList); err != nil {
+		return nil, fmt.Errorf("cannot list MultiNicNetwork: %v", err)
+	}
+	inv.Networks = networkList.Items
+	cidrList := &multinicv1.CIDRList{}
+	if err := c.List(ctx, cidrList); err != nil {
+		return nil, fmt.Errorf("cannot list CIDR: %v", err)
+	}
+	inv.CIDRs = cidrList.Items
+	ippoolList := &multinicv1.IPPoolList{}
+	if err := c.List(ctx, ippoolList); err != nil {
+		return nil, fmt.Errorf("cannot list IPPool: %v", err)
+	}
+	inv.IPPools = ippoolList.Items
+	hifList := &multinicv1.HostInterfaceList{}
+	if err := c.List(ctx, hifList); err != nil {
+		return nil, fmt.Errorf("cannot list HostInterface: %v", err)
+	}
+	inv.HostInterfaces = hifList.Items
+	if withPods {
+		podList := &corev1.PodList{}
+		if err := c.List(ctx, podList); err != nil {
+			return nil, fmt.Errorf("cannot list Pod: %v", err)
+		}
+		inv.Pods = podList.Items
+	}
+	return inv, nil
+}
+
+// getNetwork returns MultiNicNetwork by name
+func (inv *Inventory) getNetwork(name string) (*multinicv1.MultiNicNetwork, error) {
+	for i := range inv.Networks {
+		if inv.Networks[i].GetName() == name {
+			return &inv.Networks[i], nil
+		}
+	}
+	return nil, fmt.Errorf("MultiNicNetwork %s not found", name)
+}
+
+// getCIDR returns CIDR of the network, nil if not found
+func (inv *Inventory) getCIDR(network string) *multinicv1.CIDR {
+	for i := range inv.CIDRs {
+		if inv.CIDRs[i].GetName() == network {
+			return &inv.CIDRs[i]
+		}
+	}
+	return nil
+}
+
+// getPodNetworkStatus returns network status of multi-nic networks in pod annotation
+func getPodNetworkStatus(pod corev1.Pod) []plugin.NetworkStatus {
+	networksStatus := []plugin.NetworkStatus{}
+	if networkStatusStr, found := pod.Annotations[plugin.StatusesKey]; found {
+		if err := json.Unmarshal([]byte(networkStatusStr), &networksStatus); err != nil {
+			return []plugin.NetworkStatus{}
+		}
+	}
+	return networksStatus
+}
+
+// getNetworkName returns network name from <namespace>/<name> status name
+func getNetworkName(statusName string) string {
+	nameSplit := strings.Split(statusName, "/")
+	return nameSplit[len(nameSplit)-1]
+}
+
+// getIPAMType returns type of ipam JSON string
+func getIPAMType(ipam string) string {
+	ipamType := struct {
+		Type string `json:"type"`
+	}{}
+	if err := json.Unmarshal([]byte(ipam), &ipamType); err != nil {
+		return ""
+	}
+	return ipamType.Type
+}
+
+// cidrContains returns true if the CIDR contains the address
+func cidrContains(cidr string, address string) bool {
+	_, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(strings.Split(address, "/")[0])
+	return ip != nil && ipNet.Contains(ip)
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package multinicctl
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+	OutputYAML  = "yaml"
+)
+
+// TablePrinter is a view which can be rendered as table
+type TablePrinter interface {
+	WriteTable(w io.Writer) error
+}
+
+// Print writes the view in table, json or yaml format
+func Print(w io.Writer, format string, view TablePrinter) error {
+	switch format {
+	case "", OutputTable:
+		tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+		if err := view.WriteTable(tw); err != nil {
+			return err
+		}
+		return tw.Flush()
+	case OutputJSON:
+		data, err := json.MarshalIndent(view, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case OutputYAML:
+		data, err := yaml.Marshal(view)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+	return fmt.Errorf("unknown output format %s, must be one of %s, %s, %s", format, OutputTable, OutputJSON, OutputYAML)
+}
+
+// writeRow writes tab-separated columns
+func writeRow(w io.Writer, columns ...interface{}) {
+	for i, column := range columns {
+		if i > 0 {
+			fmt.Fprint(w, "\t")
+		}
+		fmt.Fprint(w, column)
+	}
+	fmt.Fprintln(w)
+}
+
+// orNone returns <none> for empty value
+func orNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+	return value
+}
+
+func (view NetworkSummaryList) WriteTable(w io.Writer) error {
+	writeRow(w, "NAME", "PLUGIN", "IPAM", "SUBNET", "CONFIG", "ROUTE", "HOSTS", "INTERFACES", "ALLOCATED", "USABLE")
+	for _, network := range view {
+		writeRow(w, network.Name, orNone(network.Plugin), orNone(network.IPAM), orNone(network.Subnet),
+			orNone(network.ConfigState), orNone(network.RouteState),
+			network.Hosts, network.Interfaces, network.Allocated, network.Usable)
+	}
+	return nil
+}
+
+// writePools writes pool usage table
+func writePools(w io.Writer, pools []PoolSummary) {
+	writeRow(w, "IPPOOL", "NETWORK", "HOST", "INTERFACE", "HOST INDEX", "POD CIDR", "ALLOCATED", "USABLE", "FREE")
+	for _, pool := range pools {
+		hostIndex := "<none>"
+		if pool.HostIndex >= 0 {
+			hostIndex = fmt.Sprintf("%d", pool.HostIndex)
+		}
+		writeRow(w, pool.Name, pool.Network, pool.HostName, pool.InterfaceName, hostIndex, pool.PodCIDR,
+			pool.Allocated, pool.Usable, pool.Free)
+	}
+}
+
+func (view *NetworkPools) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Network: %s\n\nHost Index Map:\n", view.Network)
+	writeRow(w, "NET ADDRESS", "VLAN CIDR", "HOST INDEX", "HOST", "INTERFACE", "HOST IP", "POD CIDR")
+	for _, hostMap := range view.HostIndexMap {
+		for _, host := range hostMap.Hosts {
+			writeRow(w, hostMap.NetAddress, hostMap.VlanCIDR, host.HostIndex, host.HostName, host.InterfaceName,
+				orNone(host.HostIP), host.PodCIDR)
+		}
+	}
+	fmt.Fprintln(w, "\nIPPools:")
+	writePools(w, view.Pools)
+	fmt.Fprintln(w, "\nAllocations:")
+	writeRow(w, "IPPOOL", "INDEX", "ADDRESS", "POD")
+	for _, pool := range view.Pools {
+		for _, allocation := range pool.Allocations {
+			writeRow(w, pool.Name, allocation.Index, allocation.Address, allocation.Namespace+"/"+allocation.Pod)
+		}
+	}
+	return nil
+}
+
+func (view AddressOwnerList) WriteTable(w io.Writer) error {
+	writeRow(w, "ADDRESS", "NETWORK", "IPPOOL", "HOST", "INTERFACE", "POD", "SOURCE")
+	for _, owner := range view {
+		pod := "<none>"
+		if owner.Pod != "" {
+			pod = owner.Namespace + "/" + owner.Pod
+		}
+		writeRow(w, owner.Address, owner.Network, orNone(owner.IPPool), orNone(owner.HostName),
+			orNone(owner.InterfaceName), pod, owner.Source)
+	}
+	return nil
+}
+
+func (view *PodSummary) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Pod: %s/%s\nNode: %s\n\n", view.Namespace, view.Name, orNone(view.NodeName))
+	writeRow(w, "NETWORK", "POD INTERFACE", "ADDRESS", "IPPOOL", "HOST INTERFACE", "ANNOTATED", "ALLOCATED")
+	for _, address := range view.Addresses {
+		writeRow(w, address.Network, orNone(address.Interface), address.Address, orNone(address.IPPool),
+			orNone(address.InterfaceName), address.Annotated, address.Allocated)
+	}
+	return nil
+}
+
+func (view *NodeSummary) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Node: %s\n\nInterfaces:\n", view.Name)
+	writeRow(w, "INTERFACE", "NET ADDRESS", "HOST IP", "PCI", "DRIVER", "SPEED", "STATE", "CARRIER")
+	for _, nodeInterface := range view.Interfaces {
+		carrier := "<unknown>"
+		if nodeInterface.Carrier != nil {
+			carrier = fmt.Sprintf("%t", *nodeInterface.Carrier)
+		}
+		writeRow(w, nodeInterface.InterfaceName, orNone(nodeInterface.NetAddress), orNone(nodeInterface.HostIP),
+			orNone(nodeInterface.PciAddress), orNone(nodeInterface.Driver), nodeInterface.Speed,
+			orNone(nodeInterface.OperState), carrier)
+	}
+	fmt.Fprintln(w, "\nIPPools:")
+	writePools(w, view.Pools)
+	return nil
+}
+
+func (view *NetworkCapacity) WriteTable(w io.Writer) error {
+	fmt.Fprintf(w, "Network: %s\n\n", view.Network)
+	if len(view.HostIndexes) > 0 {
+		writeRow(w, "NET ADDRESS", "VLAN CIDR", "HOST CAPACITY", "ASSIGNED", "AVAILABLE")
+		for _, entry := range view.HostIndexes {
+			writeRow(w, entry.NetAddress, entry.VlanCIDR, entry.HostCapacity, entry.AssignedHosts, entry.AvailableHostIndexes)
+		}
+		fmt.Fprintln(w)
+	}
+	writeRow(w, "NODE", "IPPOOLS", "ALLOCATED", "USABLE", "FREE", "UTILIZATION")
+	for _, node := range append(view.Nodes, view.Total) {
+		writeRow(w, node.HostName, node.Pools, node.Allocated, node.Usable, node.Free, fmt.Sprintf("%d%%", node.Utilization))
+	}
+	return nil
+}
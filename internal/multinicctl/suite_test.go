@@ -0,0 +1,13 @@
+package multinicctl
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestMultinicctl(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Multinicctl Suite")
+}
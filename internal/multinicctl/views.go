@@ -0,0 +1,534 @@
+/*
+ * Copyright 2022- IBM Inc. All rights reserved
+ * SPDX-License-Identifier: Apache-2.0
+ */
+
+package multinicctl
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+)
+
+// NetworkSummary summarizes a MultiNicNetwork with its CIDR and IPPools
+type NetworkSummary struct {
+	Name        string `json:"name"`
+	Plugin      string `json:"plugin"`
+	IPAM        string `json:"ipam"`
+	Subnet      string `json:"subnet,omitempty"`
+	ConfigState string `json:"configStatus,omitempty"`
+	RouteState  string `json:"routeStatus,omitempty"`
+	Hosts       int    `json:"hosts"`
+	Interfaces  int    `json:"interfaces"`
+	Allocated   int    `json:"allocated"`
+	Usable      int    `json:"usable"`
+}
+
+type NetworkSummaryList []NetworkSummary
+
+// HostIndexEntry is a host block assigned in the VLAN CIDR of an interface
+type HostIndexEntry struct {
+	HostIndex     int    `json:"hostIndex"`
+	HostName      string `json:"hostName"`
+	InterfaceName string `json:"interfaceName"`
+	HostIP        string `json:"hostIP,omitempty"`
+	PodCIDR       string `json:"podCIDR"`
+}
+
+// InterfaceHostMap is host index map of a master network
+type InterfaceHostMap struct {
+	NetAddress     string           `json:"netAddress"`
+	InterfaceIndex int              `json:"interfaceIndex"`
+	VlanCIDR       string           `json:"vlanCIDR"`
+	Hosts          []HostIndexEntry `json:"hosts"`
+}
+
+// PoolSummary summarizes usage of an IPPool
+// HostIndex is -1 if the host is not found in CIDR
+type PoolSummary struct {
+	Name          string                  `json:"name"`
+	Network       string                  `json:"network"`
+	HostName      string                  `json:"hostName"`
+	InterfaceName string                  `json:"interfaceName"`
+	PodCIDR       string                  `json:"podCIDR"`
+	HostIndex     int                     `json:"hostIndex"`
+	Usable        int                     `json:"usable"`
+	Allocated     int                     `json:"allocated"`
+	Free          int                     `json:"free"`
+	Allocations   []multinicv1.Allocation `json:"allocations,omitempty"`
+}
+
+// NetworkPools shows host index map and IPPools of a network
+type NetworkPools struct {
+	Network      string             `json:"network"`
+	HostIndexMap []InterfaceHostMap `json:"hostIndexMap"`
+	Pools        []PoolSummary      `json:"pools"`
+}
+
+// AddressOwner shows where an address is allocated
+// Source is ippool if found in IPPool allocations, or annotation if found only in pod network status
+type AddressOwner struct {
+	Address       string `json:"address"`
+	Network       string `json:"network"`
+	IPPool        string `json:"ippool,omitempty"`
+	HostName      string `json:"hostName,omitempty"`
+	InterfaceName string `json:"interfaceName,omitempty"`
+	PodCIDR       string `json:"podCIDR,omitempty"`
+	Index         int    `json:"index,omitempty"`
+	Pod           string `json:"pod,omitempty"`
+	Namespace     string `json:"namespace,omitempty"`
+	Source        string `json:"source"`
+}
+
+type AddressOwnerList []AddressOwner
+
+const (
+	SourceIPPool     = "ippool"
+	SourceAnnotation = "annotation"
+	SourceFree       = "free"
+)
+
+// PodAddress is an address of the pod on a multi-nic network
+// Annotated is true if the address is in pod network status
+// Allocated is true if the address is allocated to the pod in IPPool
+type PodAddress struct {
+	Network       string `json:"network"`
+	Interface     string `json:"interface,omitempty"`
+	Address       string `json:"address"`
+	IPPool        string `json:"ippool,omitempty"`
+	InterfaceName string `json:"hostInterface,omitempty"`
+	Annotated     bool   `json:"annotated"`
+	Allocated     bool   `json:"allocated"`
+}
+
+// PodSummary shows multi-nic addresses of a pod
+type PodSummary struct {
+	Name      string       `json:"name"`
+	Namespace string       `json:"namespace"`
+	NodeName  string       `json:"nodeName"`
+	Addresses []PodAddress `json:"addresses"`
+}
+
+// NodeInterface shows a host interface with its link status
+type NodeInterface struct {
+	InterfaceName string `json:"interfaceName"`
+	NetAddress    string `json:"netAddress,omitempty"`
+	HostIP        string `json:"hostIP,omitempty"`
+	PciAddress    string `json:"pciAddress,omitempty"`
+	Driver        string `json:"driver,omitempty"`
+	Speed         int    `json:"speed,omitempty"`
+	OperState     string `json:"operState,omitempty"`
+	Carrier       *bool  `json:"carrier,omitempty"`
+}
+
+// NodeSummary shows host interfaces and IPPools of a node
+type NodeSummary struct {
+	Name       string          `json:"name"`
+	Interfaces []NodeInterface `json:"interfaces"`
+	Pools      []PoolSummary   `json:"pools"`
+}
+
+// NodeCapacity shows address utilization of a network on a node
+type NodeCapacity struct {
+	HostName    string `json:"hostName"`
+	Pools       int    `json:"pools"`
+	Usable      int    `json:"usable"`
+	Allocated   int    `json:"allocated"`
+	Free        int    `json:"free"`
+	Utilization int    `json:"utilization"`
+}
+
+// NetworkCapacity shows per-node address utilization and host index availability of a network
+type NetworkCapacity struct {
+	Network     string                       `json:"network"`
+	HostIndexes []multinicv1.CIDREntryStatus `json:"hostIndexes,omitempty"`
+	Nodes       []NodeCapacity               `json:"nodes"`
+	Total       NodeCapacity                 `json:"total"`
+}
+
+// summarizePool computes usage of the IPPool from its status, falling back to spec allocations
+func summarizePool(ippool multinicv1.IPPool, hostIndex int) PoolSummary {
+	allocated := ippool.Status.Allocated
+	if allocated == 0 {
+		allocated = len(ippool.Spec.Allocations)
+	}
+	free := ippool.Status.Free
+	if ippool.Status.Usable == 0 {
+		free = 0
+	}
+	allocations := append([]multinicv1.Allocation{}, ippool.Spec.Allocations...)
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].Index < allocations[j].Index
+	})
+	return PoolSummary{
+		Name:          ippool.GetName(),
+		Network:       ippool.Spec.NetAttachDefName,
+		HostName:      ippool.Spec.HostName,
+		InterfaceName: ippool.Spec.InterfaceName,
+		PodCIDR:       ippool.Spec.PodCIDR,
+		HostIndex:     hostIndex,
+		Usable:        ippool.Status.Usable,
+		Allocated:     allocated,
+		Free:          free,
+		Allocations:   allocations,
+	}
+}
+
+// getHostIndex returns host index of the IPPool in CIDR, -1 if not found
+func getHostIndex(cidr *multinicv1.CIDR, ippool multinicv1.IPPool) int {
+	if cidr == nil {
+		return -1
+	}
+	for _, entry := range cidr.Spec.CIDRs {
+		for _, host := range entry.Hosts {
+			if host.IPPool == ippool.GetName() || (host.HostName == ippool.Spec.HostName && host.PodCIDR == ippool.Spec.PodCIDR) {
+				return host.HostIndex
+			}
+		}
+	}
+	return -1
+}
+
+// sortPools sorts pool summaries by host and interface
+func sortPools(pools []PoolSummary) {
+	sort.Slice(pools, func(i, j int) bool {
+		if pools[i].HostName != pools[j].HostName {
+			return pools[i].HostName < pools[j].HostName
+		}
+		if pools[i].InterfaceName != pools[j].InterfaceName {
+			return pools[i].InterfaceName < pools[j].InterfaceName
+		}
+		return pools[i].PodCIDR < pools[j].PodCIDR
+	})
+}
+
+// NetworkView summarizes all MultiNicNetworks
+func (inv *Inventory) NetworkView() NetworkSummaryList {
+	summaries := NetworkSummaryList{}
+	for _, network := range inv.Networks {
+		summary := NetworkSummary{
+			Name:        network.GetName(),
+			Plugin:      network.Spec.MainPlugin.Type,
+			IPAM:        getIPAMType(network.Spec.IPAM),
+			Subnet:      network.Spec.Subnet,
+			ConfigState: string(network.Status.NetConfigStatus),
+			RouteState:  string(network.Status.RouteStatus),
+		}
+		if cidr := inv.getCIDR(network.GetName()); cidr != nil {
+			hosts := make(map[string]bool)
+			for _, entry := range cidr.Spec.CIDRs {
+				for _, host := range entry.Hosts {
+					hosts[host.HostName] = true
+				}
+			}
+			summary.Hosts = len(hosts)
+			summary.Interfaces = len(cidr.Spec.CIDRs)
+		}
+		for _, ippool := range inv.IPPools {
+			if ippool.Spec.NetAttachDefName == network.GetName() {
+				pool := summarizePool(ippool, -1)
+				summary.Allocated += pool.Allocated
+				summary.Usable += pool.Usable
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Name < summaries[j].Name
+	})
+	return summaries
+}
+
+// PoolView returns host index map and IPPools of the network
+func (inv *Inventory) PoolView(network string) (*NetworkPools, error) {
+	if _, err := inv.getNetwork(network); err != nil {
+		return nil, err
+	}
+	view := &NetworkPools{
+		Network:      network,
+		HostIndexMap: []InterfaceHostMap{},
+		Pools:        []PoolSummary{},
+	}
+	cidr := inv.getCIDR(network)
+	if cidr != nil {
+		for _, entry := range cidr.Spec.CIDRs {
+			hostMap := InterfaceHostMap{
+				NetAddress:     entry.NetAddress,
+				InterfaceIndex: entry.InterfaceIndex,
+				VlanCIDR:       entry.VlanCIDR,
+				Hosts:          []HostIndexEntry{},
+			}
+			for _, host := range entry.Hosts {
+				hostMap.Hosts = append(hostMap.Hosts, HostIndexEntry{
+					HostIndex:     host.HostIndex,
+					HostName:      host.HostName,
+					InterfaceName: host.InterfaceName,
+					HostIP:        host.HostIP,
+					PodCIDR:       host.PodCIDR,
+				})
+			}
+			sort.Slice(hostMap.Hosts, func(i, j int) bool {
+				return hostMap.Hosts[i].HostIndex < hostMap.Hosts[j].HostIndex
+			})
+			view.HostIndexMap = append(view.HostIndexMap, hostMap)
+		}
+		sort.Slice(view.HostIndexMap, func(i, j int) bool {
+			return view.HostIndexMap[i].InterfaceIndex < view.HostIndexMap[j].InterfaceIndex
+		})
+	}
+	for _, ippool := range inv.IPPools {
+		if ippool.Spec.NetAttachDefName == network {
+			view.Pools = append(view.Pools, summarizePool(ippool, getHostIndex(cidr, ippool)))
+		}
+	}
+	sortPools(view.Pools)
+	return view, nil
+}
+
+// LookupView finds IPPool allocations and pods which hold the address
+// if the address is not allocated, IPPools containing the address are listed as free
+func (inv *Inventory) LookupView(address string) (AddressOwnerList, error) {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid address %s", address)
+	}
+	address = ip.String()
+	owners := AddressOwnerList{}
+	containingPools := []multinicv1.IPPool{}
+	for _, ippool := range inv.IPPools {
+		if !cidrContains(ippool.Spec.PodCIDR, address) {
+			continue
+		}
+		containingPools = append(containingPools, ippool)
+		for _, allocation := range ippool.Spec.Allocations {
+			if allocation.Address == address {
+				owners = append(owners, AddressOwner{
+					Address:       address,
+					Network:       ippool.Spec.NetAttachDefName,
+					IPPool:        ippool.GetName(),
+					HostName:      ippool.Spec.HostName,
+					InterfaceName: ippool.Spec.InterfaceName,
+					PodCIDR:       ippool.Spec.PodCIDR,
+					Index:         allocation.Index,
+					Pod:           allocation.Pod,
+					Namespace:     allocation.Namespace,
+					Source:        SourceIPPool,
+				})
+			}
+		}
+	}
+	for _, pod := range inv.Pods {
+		for _, status := range getPodNetworkStatus(pod) {
+			for _, statusIP := range status.IPs {
+				if statusIP != address || hasOwner(owners, pod.GetNamespace(), pod.GetName()) {
+					continue
+				}
+				owners = append(owners, AddressOwner{
+					Address:   address,
+					Network:   getNetworkName(status.Name),
+					HostName:  pod.Spec.NodeName,
+					Pod:       pod.GetName(),
+					Namespace: pod.GetNamespace(),
+					Source:    SourceAnnotation,
+				})
+			}
+		}
+	}
+	if len(owners) == 0 {
+		for _, ippool := range containingPools {
+			owners = append(owners, AddressOwner{
+				Address:       address,
+				Network:       ippool.Spec.NetAttachDefName,
+				IPPool:        ippool.GetName(),
+				HostName:      ippool.Spec.HostName,
+				InterfaceName: ippool.Spec.InterfaceName,
+				PodCIDR:       ippool.Spec.PodCIDR,
+				Source:        SourceFree,
+			})
+		}
+	}
+	return owners, nil
+}
+
+// hasOwner returns true if the pod is already listed
+func hasOwner(owners AddressOwnerList, namespace, name string) bool {
+	for _, owner := range owners {
+		if owner.Namespace == namespace && owner.Pod == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PodView returns multi-nic addresses of the pod from its network status and IPPool allocations
+func (inv *Inventory) PodView(namespace, name string) (*PodSummary, error) {
+	for _, pod := range inv.Pods {
+		if pod.GetNamespace() != namespace || pod.GetName() != name {
+			continue
+		}
+		view := &PodSummary{
+			Name:      name,
+			Namespace: namespace,
+			NodeName:  pod.Spec.NodeName,
+			Addresses: []PodAddress{},
+		}
+		addressIndex := make(map[string]int)
+		for _, status := range getPodNetworkStatus(pod) {
+			network := getNetworkName(status.Name)
+			if _, err := inv.getNetwork(network); err != nil {
+				// not multi-nic network
+				continue
+			}
+			for _, ip := range status.IPs {
+				addressIndex[network+"/"+ip] = len(view.Addresses)
+				view.Addresses = append(view.Addresses, PodAddress{
+					Network:   network,
+					Interface: status.Interface,
+					Address:   ip,
+					Annotated: true,
+				})
+			}
+		}
+		for _, ippool := range inv.IPPools {
+			for _, allocation := range ippool.Spec.Allocations {
+				if allocation.Namespace != namespace || allocation.Pod != name {
+					continue
+				}
+				key := ippool.Spec.NetAttachDefName + "/" + allocation.Address
+				if index, found := addressIndex[key]; found {
+					view.Addresses[index].IPPool = ippool.GetName()
+					view.Addresses[index].InterfaceName = ippool.Spec.InterfaceName
+					view.Addresses[index].Allocated = true
+					continue
+				}
+				view.Addresses = append(view.Addresses, PodAddress{
+					Network:       ippool.Spec.NetAttachDefName,
+					Address:       allocation.Address,
+					IPPool:        ippool.GetName(),
+					InterfaceName: ippool.Spec.InterfaceName,
+					Allocated:     true,
+				})
+			}
+		}
+		sort.SliceStable(view.Addresses, func(i, j int) bool {
+			if view.Addresses[i].Network != view.Addresses[j].Network {
+				return view.Addresses[i].Network < view.Addresses[j].Network
+			}
+			return view.Addresses[i].Interface < view.Addresses[j].Interface
+		})
+		return view, nil
+	}
+	return nil, fmt.Errorf("pod %s/%s not found", namespace, name)
+}
+
+// NodeView returns host interfaces and IPPools of the node
+func (inv *Inventory) NodeView(name string) (*NodeSummary, error) {
+	view := &NodeSummary{
+		Name:       name,
+		Interfaces: []NodeInterface{},
+		Pools:      []PoolSummary{},
+	}
+	found := false
+	for _, hif := range inv.HostInterfaces {
+		if hif.GetName() != name && hif.Spec.HostName != name {
+			continue
+		}
+		found = true
+		linkStatus := make(map[string]multinicv1.InterfaceLinkStatus)
+		for _, status := range hif.Status.Interfaces {
+			linkStatus[status.InterfaceName] = status
+		}
+		for _, info := range hif.Spec.Interfaces {
+			nodeInterface := NodeInterface{
+				InterfaceName: info.InterfaceName,
+				NetAddress:    info.NetAddress,
+				HostIP:        info.HostIP,
+				PciAddress:    info.PciAddress,
+				Driver:        info.Driver,
+				Speed:         info.Speed,
+			}
+			if status, ok := linkStatus[info.InterfaceName]; ok {
+				carrier := status.Carrier
+				nodeInterface.OperState = status.OperState
+				nodeInterface.Carrier = &carrier
+			}
+			view.Interfaces = append(view.Interfaces, nodeInterface)
+		}
+	}
+	sort.Slice(view.Interfaces, func(i, j int) bool {
+		return view.Interfaces[i].InterfaceName < view.Interfaces[j].InterfaceName
+	})
+	for _, ippool := range inv.IPPools {
+		if ippool.Spec.HostName == name {
+			found = true
+			view.Pools = append(view.Pools, summarizePool(ippool, getHostIndex(inv.getCIDR(ippool.Spec.NetAttachDefName), ippool)))
+		}
+	}
+	sortPools(view.Pools)
+	if !found {
+		return nil, fmt.Errorf("node %s not found in HostInterface or IPPool", name)
+	}
+	return view, nil
+}
+
+// CapacityView returns per-node address utilization and host index availability of the network
+func (inv *Inventory) CapacityView(network string) (*NetworkCapacity, error) {
+	if _, err := inv.getNetwork(network); err != nil {
+		return nil, err
+	}
+	view := &NetworkCapacity{
+		Network: network,
+		Nodes:   []NodeCapacity{},
+		Total:   NodeCapacity{HostName: "total"},
+	}
+	if cidr := inv.getCIDR(network); cidr != nil {
+		view.HostIndexes = cidr.Status.Entries
+	}
+	nodeMap := make(map[string]*NodeCapacity)
+	for _, ippool := range inv.IPPools {
+		if ippool.Spec.NetAttachDefName != network {
+			continue
+		}
+		pool := summarizePool(ippool, -1)
+		node, found := nodeMap[pool.HostName]
+		if !found {
+			node = &NodeCapacity{HostName: pool.HostName}
+			nodeMap[pool.HostName] = node
+		}
+		for _, capacity := range []*NodeCapacity{node, &view.Total} {
+			capacity.Pools += 1
+			capacity.Usable += pool.Usable
+			capacity.Allocated += pool.Allocated
+			capacity.Free += pool.Free
+		}
+	}
+	for _, node := range nodeMap {
+		node.Utilization = getUtilization(node.Allocated, node.Usable)
+		view.Nodes = append(view.Nodes, *node)
+	}
+	view.Total.Utilization = getUtilization(view.Total.Allocated, view.Total.Usable)
+	sort.Slice(view.Nodes, func(i, j int) bool {
+		return view.Nodes[i].HostName < view.Nodes[j].HostName
+	})
+	return view, nil
+}
+
+// getUtilization returns allocated percentage of usable addresses
+func getUtilization(allocated, usable int) int {
+	if usable == 0 {
+		return 0
+	}
+	return allocated * 100 / usable
+}
+
+// ParsePodName parses <namespace>/<name>, namespace is default if not specified
+func ParsePodName(podName string) (string, string) {
+	if namespace, name, found := strings.Cut(podName, "/"); found {
+		return namespace, name
+	}
+	return "default", podName
+}
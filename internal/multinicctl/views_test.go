@@ -0,0 +1,220 @@
+package multinicctl_test
+
+import (
+	"bytes"
+	"encoding/json"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	. "github.com/foundation-model-stack/multi-nic-cni/internal/multinicctl"
+	"github.com/foundation-model-stack/multi-nic-cni/internal/plugin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const networkName = "multinic-sample"
+
+func newIPPool(hostName, interfaceName, podCIDR string, usable int, allocations ...multinicv1.Allocation) multinicv1.IPPool {
+	ippool := multinicv1.IPPool{
+		ObjectMeta: metav1.ObjectMeta{Name: networkName + "-" + podCIDR[:len(podCIDR)-3]},
+		Spec: multinicv1.IPPoolSpec{
+			NetAttachDefName: networkName,
+			HostName:         hostName,
+			InterfaceName:    interfaceName,
+			PodCIDR:          podCIDR,
+			Allocations:      allocations,
+		},
+	}
+	ippool.Status.Usable = usable
+	ippool.Status.Allocated = len(allocations)
+	ippool.Status.Free = usable - len(allocations)
+	return ippool
+}
+
+func newPod(namespace, name, nodeName string, statuses ...plugin.NetworkStatus) corev1.Pod {
+	statusBytes, _ := json.Marshal(statuses)
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Annotations: map[string]string{plugin.StatusesKey: string(statusBytes)},
+		},
+		Spec: corev1.PodSpec{NodeName: nodeName},
+	}
+}
+
+func newInventory() *Inventory {
+	return &Inventory{
+		Networks: []multinicv1.MultiNicNetwork{{
+			ObjectMeta: metav1.ObjectMeta{Name: networkName},
+			Spec: multinicv1.MultiNicNetworkSpec{
+				Subnet:     "192.168.0.0/16",
+				IPAM:       `{"type":"multi-nic-ipam","hostBlock":8,"interfaceBlock":2}`,
+				MainPlugin: multinicv1.PluginSpec{Type: "ipvlan"},
+			},
+		}},
+		CIDRs: []multinicv1.CIDR{{
+			ObjectMeta: metav1.ObjectMeta{Name: networkName},
+			Spec: multinicv1.CIDRSpec{
+				CIDRs: []multinicv1.CIDREntry{{
+					NetAddress:     "10.0.0.0/24",
+					InterfaceIndex: 0,
+					VlanCIDR:       "192.168.0.0/18",
+					Hosts: []multinicv1.HostInterfaceInfo{
+						{HostIndex: 1, HostName: "node-b", InterfaceName: "eth1", HostIP: "10.0.0.2", PodCIDR: "192.168.1.0/24"},
+						{HostIndex: 0, HostName: "node-a", InterfaceName: "eth1", HostIP: "10.0.0.1", PodCIDR: "192.168.0.0/24"},
+					},
+				}},
+			},
+		}},
+		IPPools: []multinicv1.IPPool{
+			newIPPool("node-a", "eth1", "192.168.0.0/24", 254,
+				multinicv1.Allocation{Pod: "pod-a2", Namespace: "default", Index: 2, Address: "192.168.0.2"},
+				multinicv1.Allocation{Pod: "pod-a1", Namespace: "default", Index: 1, Address: "192.168.0.1"}),
+			newIPPool("node-b", "eth1", "192.168.1.0/24", 254),
+		},
+		HostInterfaces: []multinicv1.HostInterface{{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Spec: multinicv1.HostInterfaceSpec{
+				HostName: "node-a",
+				Interfaces: []multinicv1.InterfaceInfoType{
+					{InterfaceName: "eth2", NetAddress: "10.0.1.0/24"},
+					{InterfaceName: "eth1", NetAddress: "10.0.0.0/24", HostIP: "10.0.0.1"},
+				},
+			},
+			Status: multinicv1.HostInterfaceStatus{
+				Interfaces: []multinicv1.InterfaceLinkStatus{{InterfaceName: "eth1", OperState: "up", Carrier: true}},
+			},
+		}},
+		Pods: []corev1.Pod{
+			newPod("default", "pod-a1", "node-a",
+				plugin.NetworkStatus{Name: "default/" + networkName, Interface: "net1-0", IPs: []string{"192.168.0.1"}}),
+			// allocated in IPPool but the network status is not yet written
+			newPod("default", "pod-a2", "node-a"),
+			// network status is left after the allocation is removed
+			newPod("other", "pod-c", "node-b",
+				plugin.NetworkStatus{Name: "other/" + networkName, Interface: "net1-0", IPs: []string{"192.168.1.5"}}),
+		},
+	}
+}
+
+var _ = Describe("Test Multinicctl Views", func() {
+	inv := newInventory()
+
+	It("summarizes networks", func() {
+		summaries := inv.NetworkView()
+		Expect(summaries).To(HaveLen(1))
+		Expect(summaries[0]).To(Equal(NetworkSummary{
+			Name:       networkName,
+			Plugin:     "ipvlan",
+			IPAM:       "multi-nic-ipam",
+			Subnet:     "192.168.0.0/16",
+			Hosts:      2,
+			Interfaces: 1,
+			Allocated:  2,
+			Usable:     508,
+		}))
+	})
+
+	It("renders host index map and pools", func() {
+		view, err := inv.PoolView(networkName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(view.HostIndexMap).To(HaveLen(1))
+		Expect(view.HostIndexMap[0].Hosts[0].HostName).To(Equal("node-a"))
+		Expect(view.HostIndexMap[0].Hosts[1].HostName).To(Equal("node-b"))
+		Expect(view.Pools).To(HaveLen(2))
+		Expect(view.Pools[0].HostIndex).To(Equal(0))
+		Expect(view.Pools[0].Allocations[0].Pod).To(Equal("pod-a1"))
+		Expect(view.Pools[1].HostIndex).To(Equal(1))
+		Expect(view.Pools[1].Free).To(Equal(254))
+
+		_, err = inv.PoolView("unknown")
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("lookup address", func(address string, expectedPod string, expectedSource string) {
+		owners, err := inv.LookupView(address)
+		Expect(err).NotTo(HaveOccurred())
+		if expectedSource == "" {
+			Expect(owners).To(BeEmpty())
+			return
+		}
+		Expect(owners).To(HaveLen(1))
+		Expect(owners[0].Pod).To(Equal(expectedPod))
+		Expect(owners[0].Source).To(Equal(expectedSource))
+	},
+		Entry("allocated", "192.168.0.1", "pod-a1", SourceIPPool),
+		Entry("allocated without status", "192.168.0.2", "pod-a2", SourceIPPool),
+		Entry("annotation only", "192.168.1.5", "pod-c", SourceAnnotation),
+		Entry("free address", "192.168.1.6", "", SourceFree),
+		Entry("out of pools", "172.16.0.1", "", ""),
+	)
+
+	It("fails to lookup invalid address", func() {
+		_, err := inv.LookupView("192.168.0")
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable("pod addresses", func(namespace, name string, expected []PodAddress) {
+		view, err := inv.PodView(namespace, name)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(view.Addresses).To(HaveLen(len(expected)))
+		for i, address := range view.Addresses {
+			Expect(address.Address).To(Equal(expected[i].Address))
+			Expect(address.Annotated).To(Equal(expected[i].Annotated))
+			Expect(address.Allocated).To(Equal(expected[i].Allocated))
+		}
+	},
+		Entry("annotated and allocated", "default", "pod-a1", []PodAddress{{Address: "192.168.0.1", Annotated: true, Allocated: true}}),
+		Entry("allocated only", "default", "pod-a2", []PodAddress{{Address: "192.168.0.2", Allocated: true}}),
+		Entry("annotated only", "other", "pod-c", []PodAddress{{Address: "192.168.1.5", Annotated: true}}),
+	)
+
+	It("shows node interfaces and pools", func() {
+		view, err := inv.NodeView("node-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(view.Interfaces).To(HaveLen(2))
+		Expect(view.Interfaces[0].InterfaceName).To(Equal("eth1"))
+		Expect(*view.Interfaces[0].Carrier).To(BeTrue())
+		Expect(view.Interfaces[1].Carrier).To(BeNil())
+		Expect(view.Pools).To(HaveLen(1))
+
+		_, err = inv.NodeView("node-x")
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("computes capacity", func() {
+		view, err := inv.CapacityView(networkName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(view.Nodes).To(HaveLen(2))
+		Expect(view.Nodes[0]).To(Equal(NodeCapacity{HostName: "node-a", Pools: 1, Usable: 254, Allocated: 2, Free: 252, Utilization: 0}))
+		Expect(view.Total.Allocated).To(Equal(2))
+		Expect(view.Total.Usable).To(Equal(508))
+	})
+
+	DescribeTable("print", func(format string, expected string) {
+		buf := &bytes.Buffer{}
+		err := Print(buf, format, inv.NetworkView())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(buf.String()).To(ContainSubstring(expected))
+	},
+		Entry("table", OutputTable, "multinic-sample  ipvlan"),
+		Entry("json", OutputJSON, `"name": "multinic-sample"`),
+		Entry("yaml", OutputYAML, "name: multinic-sample"),
+	)
+
+	It("fails on unknown format", func() {
+		Expect(Print(&bytes.Buffer{}, "wide", inv.NetworkView())).NotTo(Succeed())
+	})
+
+	DescribeTable("parse pod name", func(podName, expectedNamespace, expectedName string) {
+		namespace, name := ParsePodName(podName)
+		Expect(namespace).To(Equal(expectedNamespace))
+		Expect(name).To(Equal(expectedName))
+	},
+		Entry("with namespace", "kube-system/pod", "kube-system", "pod"),
+		Entry("without namespace", "pod", "default", "pod"),
+	)
+})
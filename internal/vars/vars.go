@@ -52,6 +52,9 @@ const (
 	APIServerToleration                       = 5 // maximum retry if getting error from api server timeout
 	APIServerTolerationWaitTime time.Duration = 2 * time.Second
 
+	// default allocated percentage of usable addresses to mark IPPool NearlyExhausted
+	DefaultIPPoolNearlyExhaustedPercent = 90
+
 	//	multus-related constants
 	MultusLabelKey     = "app"
 	MultusLabelValue   = "multus"
@@ -78,17 +81,20 @@ var (
 	LongReconcileTime   time.Duration = DefaultLongReconcileTime
 	ContextTimeout      time.Duration = DefaultContextTimeout
 
+	IPPoolNearlyExhaustedPercent int = DefaultIPPoolNearlyExhaustedPercent
+
 	// logger options to change log level on the fly
-	ZapOpts    *zap.Options
-	SetupLog   logr.Logger
-	DaemonLog  logr.Logger
-	DefLog     logr.Logger
-	CIDRLog    logr.Logger
-	HifLog     logr.Logger
-	IPPoolLog  logr.Logger
-	NetworkLog logr.Logger
-	ConfigLog  logr.Logger
-	SyncLog    logr.Logger
+	ZapOpts        *zap.Options
+	SetupLog       logr.Logger
+	DaemonLog      logr.Logger
+	DefLog         logr.Logger
+	CIDRLog        logr.Logger
+	HifLog         logr.Logger
+	IPPoolLog      logr.Logger
+	NetworkLog     logr.Logger
+	ConfigLog      logr.Logger
+	SyncLog        logr.Logger
+	DeviceClassLog logr.Logger
 )
 
 // InitIntFromEnv initialize int value from environment key or set to default if not set or invalid
@@ -118,6 +124,7 @@ func SetLog() {
 	NetworkLog = ctrl.Log.WithName("controllers").WithName("MultiNicNetwork")
 	ConfigLog = ctrl.Log.WithName("controllers").WithName("Config")
 	SyncLog = ctrl.Log.WithName("controllers").WithName("Synchronizer")
+	DeviceClassLog = ctrl.Log.WithName("controllers").WithName("DeviceClass")
 }
 
 func IsUnmanaged(metadata metav1.ObjectMeta) bool {
@@ -31,6 +31,7 @@ import (
 
 	multinicv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
 	netv1 "github.com/foundation-model-stack/multi-nic-cni/api/v1"
+	multinicv2 "github.com/foundation-model-stack/multi-nic-cni/api/v2"
 	"github.com/foundation-model-stack/multi-nic-cni/controllers"
 	"github.com/foundation-model-stack/multi-nic-cni/internal/plugin"
 	"github.com/foundation-model-stack/multi-nic-cni/internal/vars"
@@ -47,6 +48,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 	utilruntime.Must(multinicv1.AddToScheme(scheme))
 	utilruntime.Must(netv1.AddToScheme(scheme))
+	utilruntime.Must(multinicv2.AddToScheme(scheme))
 	//+kubebuilder:scaffold:scheme
 }
 
@@ -191,6 +193,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	deviceClassReconciler := &controllers.DeviceClassReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}
+	if err = (deviceClassReconciler).SetupWithManager(mgr); err != nil {
+		vars.SetupLog.Error(err, "unable to create controller", "controller", "DeviceClass")
+		os.Exit(1)
+	}
+
 	MultiNicNetworkReconcilerPointer = &controllers.MultiNicNetworkReconciler{
 		Client:              mgr.GetClient(),
 		NetAttachDefHandler: defHandler,
@@ -224,6 +235,19 @@ func main() {
 		vars.SetupLog.Info(fmt.Sprintf("fail to create default config: %v", err))
 	}
 
+	// webhook server requires serving certificates mounted by config/default/manager_webhook_patch.yaml
+	// the conversion webhook between v1 and v2 MultiNicNetwork is always served as the CRD uses Webhook conversion
+	if err = multinicv1.SetupMultiNicNetworkConversionWebhookWithManager(mgr); err != nil {
+		vars.SetupLog.Error(err, "unable to create conversion webhook", "webhook", "MultiNicNetwork")
+		os.Exit(1)
+	}
+	if os.Getenv("ENABLE_WEBHOOKS") == "true" {
+		if err = multinicv1.SetupMultiNicNetworkWebhookWithManager(mgr); err != nil {
+			vars.SetupLog.Error(err, "unable to create webhook", "webhook", "MultiNicNetwork")
+			os.Exit(1)
+		}
+	}
+
 	//+kubebuilder:scaffold:builder
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		vars.SetupLog.Error(err, "unable to set up health check")